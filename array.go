@@ -1,9 +1,11 @@
 package validator
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 
 	"github.com/lestrrat/go-pdebug"
 )
@@ -18,7 +20,12 @@ func Array() *ArrayConstraint {
 }
 
 // Validate validates the given value against this Constraint
-func (c *ArrayConstraint) Validate(v interface{}) (err error) {
+func (c *ArrayConstraint) Validate(v interface{}) error {
+	return c.ValidateContext(context.Background(), v)
+}
+
+// ValidateContext is the same as Validate, but honors the given context
+func (c *ArrayConstraint) ValidateContext(ctx context.Context, v interface{}) (err error) {
 	if pdebug.Enabled {
 		g := pdebug.IPrintf("START ArrayConstraint.Validate")
 		defer func() {
@@ -60,7 +67,7 @@ func (c *ArrayConstraint) Validate(v interface{}) (err error) {
 		for i := 0; i < l; i++ {
 			iv := rv.Index(i).Interface()
 			kv := fmt.Sprintf("%s", iv)
-pdebug.Printf("unique? -> %s", kv)
+			pdebug.Printf("unique? -> %s", kv)
 			if _, ok := uitems[kv]; ok {
 				return errors.New("duplicate element found")
 			}
@@ -76,7 +83,7 @@ pdebug.Printf("unique? -> %s", kv)
 		// additional items are ignored
 		for i := 0; i < l; i++ {
 			iv := rv.Index(i).Interface()
-			if err := celem.Validate(iv); err != nil {
+			if err := validateChild(ctx, strconv.Itoa(i), celem, iv); err != nil {
 				return err
 			}
 		}
@@ -92,7 +99,7 @@ pdebug.Printf("unique? -> %s", kv)
 				pdebug.Printf("Checking positional item at '%d'", i)
 			}
 			iv := rv.Index(i).Interface()
-			if err := cpos.Validate(iv); err != nil {
+			if err := validateChild(ctx, strconv.Itoa(i), cpos, iv); err != nil {
 				return err
 			}
 		}
@@ -103,9 +110,9 @@ pdebug.Printf("unique? -> %s", kv)
 			if cadd == nil { // you can't have additionalItems!
 				return errors.New("additional elements found in array")
 			}
-			for i := lp - 1; i < l; i++ {
+			for i := lp; i < l; i++ {
 				iv := rv.Index(i).Interface()
-				if err := cadd.Validate(iv); err != nil {
+				if err := validateChild(ctx, strconv.Itoa(i), cadd, iv); err != nil {
 					return err
 				}
 			}
@@ -114,6 +121,28 @@ pdebug.Printf("unique? -> %s", kv)
 	return nil
 }
 
+// itemConstraint returns the constraint that the i-th element of the
+// array must be validated against. A nil Constraint with no error means
+// that the element does not need to be validated.
+func (c *ArrayConstraint) itemConstraint(i int) (Constraint, error) {
+	if c.items != nil {
+		return c.items, nil
+	}
+
+	lp := len(c.positionalItems)
+	if i < lp {
+		return c.positionalItems[i], nil
+	}
+
+	if lp > 0 {
+		if c.additionalItems == nil {
+			return nil, errors.New("additional elements found in array")
+		}
+		return c.additionalItems, nil
+	}
+	return nil, nil
+}
+
 // AdditionalItems specifies the constraint that additional items
 // must be validated against. Note that if you specify `Items`
 // with this constraint, `AdditionalItems` has no effect. This
@@ -121,7 +150,8 @@ pdebug.Printf("unique? -> %s", kv)
 // `PositionalItems` constraint.
 //
 // If you want to allow any additional items, pass `EmptyConstraint`
-// to this method. If unspecified, no extra items are allowed.
+// to this method, and pass nil to disallow them. If unspecified, any
+// additional items are allowed.
 func (c *ArrayConstraint) AdditionalItems(ac Constraint) *ArrayConstraint {
 	c.additionalItems = ac
 	return c
@@ -169,4 +199,37 @@ func (c *ArrayConstraint) UniqueItems(b bool) *ArrayConstraint {
 	}
 	c.uniqueItems = b
 	return c
-}
\ No newline at end of file
+}
+
+// GetAdditionalItems returns the constraint for additional items,
+// or nil if no extra items are allowed
+func (c *ArrayConstraint) GetAdditionalItems() Constraint {
+	return c.additionalItems
+}
+
+// GetItems returns the constraint that all items must be validated
+// against, or nil if unspecified
+func (c *ArrayConstraint) GetItems() Constraint {
+	return c.items
+}
+
+// GetMinItems returns the minimum number of items, or -1 if unspecified
+func (c *ArrayConstraint) GetMinItems() int {
+	return c.minItems
+}
+
+// GetMaxItems returns the maximum number of items, or -1 if unspecified
+func (c *ArrayConstraint) GetMaxItems() int {
+	return c.maxItems
+}
+
+// GetPositionalItems returns the constraints for each position of
+// the array
+func (c *ArrayConstraint) GetPositionalItems() []Constraint {
+	return c.positionalItems
+}
+
+// GetUniqueItems returns true if the items must be unique
+func (c *ArrayConstraint) GetUniqueItems() bool {
+	return c.uniqueItems
+}
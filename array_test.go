@@ -60,3 +60,74 @@ func TestArrayItemsReference(t *testing.T) {
 		return
 	}
 }
+
+func TestArrayAdditionalItems(t *testing.T) {
+	data := map[string]struct {
+		src     string
+		valid   []interface{}
+		invalid []interface{}
+	}{
+		"additionalItems as schema": {
+			src:     `{ "items": [ { "type": "string" } ], "additionalItems": { "type": "integer" } }`,
+			valid:   []interface{}{"foo", float64(1), float64(2)},
+			invalid: []interface{}{"foo", float64(1), "bar"},
+		},
+		"additionalItems as false": {
+			src:     `{ "items": [ { "type": "string" }, { "type": "string" } ], "additionalItems": false }`,
+			valid:   []interface{}{"foo", "bar"},
+			invalid: []interface{}{"foo", "bar", "baz"},
+		},
+		"additionalItems allowed by default": {
+			src:     `{ "items": [ { "type": "string" } ] }`,
+			valid:   []interface{}{"foo", float64(1), true},
+			invalid: []interface{}{float64(1)},
+		},
+		"maxItems": {
+			src:     `{ "type": "array", "maxItems": 2 }`,
+			valid:   []interface{}{"foo"},
+			invalid: []interface{}{"foo", "bar", "baz"},
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			s, err := schema.Parse(strings.NewReader(d.src), schema.WithSchemaID(draft04.SchemaID))
+			if !assert.NoError(t, err, "schema.Parse should succeed") {
+				return
+			}
+			v, err := builder.New().Build(s)
+			if !assert.NoError(t, err, "builder.Build should succeed") {
+				return
+			}
+			if !assert.NoError(t, v.Validate(d.valid), "%#v is valid", d.valid) {
+				return
+			}
+			if !assert.Error(t, v.Validate(d.invalid), "%#v is invalid", d.invalid) {
+				return
+			}
+		})
+	}
+}
+
+func TestArrayGetters(t *testing.T) {
+	items := validator.String()
+	c := validator.Array().Items(items).MinItems(1).MaxItems(3).UniqueItems(true)
+
+	if !assert.Equal(t, items, c.GetItems(), "items match") {
+		return
+	}
+	if !assert.Equal(t, []int{1, 3}, []int{c.GetMinItems(), c.GetMaxItems()}, "limits match") {
+		return
+	}
+	if !assert.True(t, c.GetUniqueItems(), "uniqueItems matches") {
+		return
+	}
+
+	c = validator.Array().PositionalItems([]validator.Constraint{items}).AdditionalItems(nil)
+	if !assert.Len(t, c.GetPositionalItems(), 1, "positional items match") {
+		return
+	}
+	if !assert.Nil(t, c.GetAdditionalItems(), "additional items are not allowed") {
+		return
+	}
+}
@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"context"
 	"errors"
 	"reflect"
 
@@ -61,6 +62,11 @@ func Not(c Constraint) *NotConstraint {
 	return &NotConstraint{child: c}
 }
 
+// GetChild returns the child constraint whose result is negated
+func (nc NotConstraint) GetChild() Constraint {
+	return nc.child
+}
+
 // HasDefault is a no op for this constraint
 func (nc NotConstraint) HasDefault() bool {
 	return false
@@ -73,7 +79,12 @@ func (nc NotConstraint) DefaultValue() interface{} {
 
 // Validate runs the validation, and returns an error unless
 // the child constraint fails
-func (nc NotConstraint) Validate(v interface{}) (err error) {
+func (nc NotConstraint) Validate(v interface{}) error {
+	return nc.ValidateContext(context.Background(), v)
+}
+
+// ValidateContext is the same as Validate, but honors the given context
+func (nc NotConstraint) ValidateContext(ctx context.Context, v interface{}) (err error) {
 	if pdebug.Enabled {
 		g := pdebug.Marker("NotConstraint.Validate").BindError(&err)
 		defer g.End()
@@ -83,8 +94,12 @@ func (nc NotConstraint) Validate(v interface{}) (err error) {
 		return errors.New("'not' constraint does not have a child constraint")
 	}
 
-	if err := nc.child.Validate(v); err == nil {
+	err = validateContext(ctx, nc.child, v)
+	if err == nil {
 		return errors.New("'not' validation failed")
 	}
+	if isAborted(err) {
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,231 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lestrrat/go-pdebug"
+	"github.com/pkg/errors"
+)
+
+// LineResult is the result of validating a single line of a
+// newline-delimited JSON stream.
+type LineResult struct {
+	// Line is the 1-based line number of the record
+	Line int
+	// Valid is true if the record passed validation
+	Valid bool
+	// Err is the validation error, if any. Use AsValidationError
+	// to find out where in the record the error occurred.
+	Err error
+}
+
+// BatchStats contains the summary of a batch validation run
+type BatchStats struct {
+	// Records is the number of records that were validated
+	Records int64
+	// Valid is the number of records that passed validation
+	Valid int64
+	// Invalid is the number of records that failed validation
+	Invalid int64
+	// Skipped is the number of blank lines that were skipped
+	Skipped int64
+	// Elapsed is the time it took to process the whole stream
+	Elapsed time.Duration
+}
+
+// BatchValidator validates newline-delimited JSON (a.k.a. JSON Lines)
+// streams, validating each line as an individual record using a pool
+// of goroutines.
+//
+// All constraints in this package are safe for concurrent use once they
+// have been built, so the same JSVal is shared among all workers.
+type BatchValidator struct {
+	validator *JSVal
+	workers   int
+}
+
+// NewBatchValidator creates a new BatchValidator that validates each
+// record against v
+func NewBatchValidator(v *JSVal) *BatchValidator {
+	return &BatchValidator{
+		validator: v,
+		workers:   runtime.GOMAXPROCS(0),
+	}
+}
+
+// Workers specifies the number of goroutines used to validate records.
+// If unspecified, runtime.GOMAXPROCS(0) is used.
+func (b *BatchValidator) Workers(n int) *BatchValidator {
+	if n < 1 {
+		n = 1
+	}
+	b.workers = n
+	return b
+}
+
+type batchJob struct {
+	seq  int
+	line int
+	data []byte
+}
+
+type batchResult struct {
+	seq int
+	res LineResult
+}
+
+// Run reads newline-delimited JSON from r, and validates each record.
+// fn is called for every record with its result, in the same order the
+// records appear in the input, regardless of the order in which the
+// workers finish. Blank lines are skipped.
+//
+// If fn returns an error, processing stops and that error is returned.
+// Processing also stops when ctx is canceled, in which case ctx.Err()
+// is returned, and the records that had not been passed to fn yet are
+// dropped. Note that the goroutine reading from r may remain blocked
+// until the read returns.
+func (b *BatchValidator) Run(ctx context.Context, r io.Reader, fn func(LineResult) error) (stats BatchStats, err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("BatchValidator.Run").BindError(&err)
+		defer g.End()
+	}
+
+	var skipped int64
+	start := time.Now()
+	defer func() {
+		stats.Skipped = atomic.LoadInt64(&skipped)
+		stats.Elapsed = time.Since(start)
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// tokens limits the number of records in flight, so that a slow
+	// record does not cause the rest of the stream to pile up in memory
+	tokens := make(chan struct{}, b.workers*4)
+	jobs := make(chan batchJob)
+	results := make(chan batchResult, b.workers)
+	readerr := make(chan error, 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < b.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var job batchJob
+				select {
+				case <-ctx.Done():
+					return
+				case j, ok := <-jobs:
+					if !ok {
+						return
+					}
+					job = j
+				}
+
+				res := batchResult{seq: job.seq, res: LineResult{Line: job.line}}
+				if err := b.validator.ValidateReaderContext(ctx, bytes.NewReader(job.data)); err != nil {
+					res.res.Err = err
+				} else {
+					res.res.Valid = true
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case results <- res:
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	go func() {
+		defer close(jobs)
+		readerr <- b.readLines(ctx, r, tokens, jobs, &skipped)
+	}()
+
+	pending := make(map[int]LineResult)
+	next := 0
+	for res := range results {
+		pending[res.seq] = res.res
+		for {
+			lr, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-tokens
+
+			// Records are neither counted nor passed to fn once ctx
+			// is canceled, as their validation may have been cut short
+			if ctx.Err() != nil {
+				continue
+			}
+
+			stats.Records++
+			if lr.Valid {
+				stats.Valid++
+			} else {
+				stats.Invalid++
+			}
+
+			if err := fn(lr); err != nil {
+				cancel()
+				for range results {
+				}
+				return stats, err
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return stats, err
+	}
+	return stats, <-readerr
+}
+
+func (b *BatchValidator) readLines(ctx context.Context, r io.Reader, tokens chan struct{}, jobs chan batchJob, skipped *int64) error {
+	rdr := bufio.NewReader(r)
+	seq := 0
+	for line := 1; ; line++ {
+		data, err := rdr.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return errors.Wrapf(err, `failed to read line %d`, line)
+		}
+
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case tokens <- struct{}{}:
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case jobs <- batchJob{seq: seq, line: line, data: trimmed}:
+			}
+			seq++
+		} else if len(data) > 0 {
+			atomic.AddInt64(skipped, 1)
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package validator_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-json-schema/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchValidator(t *testing.T) {
+	v := validator.New().SetRoot(
+		validator.Object().
+			AddProp(`id`, validator.Integer().Minimum(0)).
+			AddProp(`level`, validator.String().Enum("info", "warn", "error")).
+			AddProp(`tags`, validator.Array().Items(validator.String()).UniqueItems(true)).
+			Required(`id`, `level`),
+	)
+
+	var buf bytes.Buffer
+	var expected []bool
+	for i := 0; i < 1000; i++ {
+		switch i % 10 {
+		case 3:
+			fmt.Fprintf(&buf, `{"id": %d, "level": "debug"}`+"\n", i)
+			expected = append(expected, false)
+		case 7:
+			fmt.Fprintf(&buf, `{"id": %d, "level": "info", "tags": ["a", "a"]}`+"\n", i)
+			expected = append(expected, false)
+		default:
+			fmt.Fprintf(&buf, `{"id": %d, "level": "info", "tags": ["a", "b"]}`+"\n", i)
+			expected = append(expected, true)
+		}
+		if i%100 == 0 {
+			buf.WriteString("\n")
+		}
+	}
+
+	var results []validator.LineResult
+	stats, err := validator.NewBatchValidator(v).Workers(8).Run(context.Background(), &buf, func(lr validator.LineResult) error {
+		results = append(results, lr)
+		return nil
+	})
+	if !assert.NoError(t, err, "Run should succeed") {
+		return
+	}
+
+	if !assert.Len(t, results, len(expected), "one result per record") {
+		return
+	}
+
+	prev := 0
+	for i, lr := range results {
+		if !assert.True(t, lr.Line > prev, "results are emitted in order") {
+			return
+		}
+		prev = lr.Line
+
+		if !assert.Equal(t, expected[i], lr.Valid, "result for line %d", lr.Line) {
+			return
+		}
+		if !lr.Valid && !assert.Error(t, lr.Err, "invalid records carry an error") {
+			return
+		}
+	}
+
+	if !assert.Equal(t, int64(1000), stats.Records, "records") {
+		return
+	}
+	if !assert.Equal(t, int64(800), stats.Valid, "valid records") {
+		return
+	}
+	if !assert.Equal(t, int64(200), stats.Invalid, "invalid records") {
+		return
+	}
+	if !assert.Equal(t, int64(10), stats.Skipped, "skipped lines") {
+		return
+	}
+}
+
+func TestBatchValidatorStop(t *testing.T) {
+	v := validator.New().SetRoot(validator.Integer())
+	src := strings.Repeat("1\n", 1000)
+
+	stop := errors.New("stop")
+	count := 0
+	_, err := validator.NewBatchValidator(v).Workers(4).Run(context.Background(), strings.NewReader(src), func(lr validator.LineResult) error {
+		count++
+		if count == 10 {
+			return stop
+		}
+		return nil
+	})
+	if !assert.Equal(t, stop, err, "error from callback is returned") {
+		return
+	}
+	if !assert.Equal(t, 10, count, "callback is not called after it returns an error") {
+		return
+	}
+}
+
+func TestBatchValidatorCancel(t *testing.T) {
+	v := validator.New().SetRoot(validator.Integer())
+
+	// The pipe is never closed while Run is in progress, so Run can
+	// only return because ctx is canceled
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go pw.Write([]byte(strings.Repeat("1\n", 100)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	count := 0
+	stats, err := validator.NewBatchValidator(v).Workers(4).Run(ctx, pr, func(lr validator.LineResult) error {
+		count++
+		if count == 10 {
+			cancel()
+		}
+		return nil
+	})
+	if !assert.Equal(t, context.Canceled, err, "ctx.Err() is returned") {
+		return
+	}
+	if !assert.Equal(t, 10, count, "callback is not called after ctx is canceled") {
+		return
+	}
+	if !assert.Equal(t, int64(10), stats.Records, "records after cancelation are not counted") {
+		return
+	}
+	if !assert.Equal(t, int64(10), stats.Valid, "valid records") {
+		return
+	}
+}
+
+func TestConcurrentValidate(t *testing.T) {
+	type Record struct {
+		ID   int64                 `json:"id"`
+		Name validator.MaybeString `json:"name"`
+	}
+
+	m := &validator.ConstraintMap{}
+	m.SetReference("#/definitions/name", validator.String().MaxLength(10))
+	v := validator.New().SetConstraintMap(m).SetRoot(
+		validator.Array().Items(
+			validator.Object().
+				AddProp(`id`, validator.Integer()).
+				AddProp(`name`, validator.Reference(m).RefersTo("#/definitions/name")).
+				Required(`id`),
+		),
+	)
+
+	done := make(chan error)
+	for i := 0; i < 8; i++ {
+		go func(i int) {
+			var err error
+			for j := 0; j < 100 && err == nil; j++ {
+				var r Record
+				r.ID = int64(j)
+				r.Name.Set(fmt.Sprintf("%d-%d", i, j))
+				err = v.Validate([]interface{}{r, &r})
+			}
+			done <- err
+		}(i)
+	}
+
+	for i := 0; i < 8; i++ {
+		if !assert.NoError(t, <-done, "concurrent validation should succeed") {
+			return
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package validator_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/go-json-schema/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+type benchAddress struct {
+	Street string                `json:"street"`
+	City   string                `json:"city"`
+	Zip    validator.MaybeString `json:"zip"`
+}
+
+type benchRecord struct {
+	ID      int64                 `json:"id"`
+	Name    string                `json:"name"`
+	Email   validator.MaybeString `json:"email"`
+	Score   validator.MaybeFloat  `json:"score"`
+	Active  bool                  `json:"active"`
+	Tags    []string              `json:"tags"`
+	Address benchAddress          `json:"address"`
+}
+
+// newBenchRuntimeValidator builds the validator used to compare the
+// runtime constructed validator against its generated counterpart.
+// bench_generated_test.go contains the output of the Generator for this
+// validator (named "BenchGenerated", with its package level variables
+// prefixed by the same name), and must be regenerated when this function
+// is changed.
+func newBenchRuntimeValidator() *validator.JSVal {
+	m := &validator.ConstraintMap{}
+	m.SetReference(`#/definitions/address`, validator.Object().
+		AddProp(`street`, validator.String().MinLength(1)).
+		AddProp(`city`, validator.String().MinLength(1)).
+		AddProp(`zip`, validator.String().RegexpString(`^[0-9]{5}$`)).
+		Required(`street`, `city`),
+	)
+
+	return validator.New().SetName("BenchGenerated").SetConstraintMap(m).SetRoot(
+		validator.Object().
+			AddProp(`id`, validator.Integer().Minimum(1)).
+			AddProp(`name`, validator.String().MinLength(1).MaxLength(64)).
+			AddProp(`email`, validator.String().Format(`email`)).
+			AddProp(`score`, validator.Number().Minimum(0).Maximum(100)).
+			AddProp(`active`, validator.Boolean()).
+			AddProp(`tags`, validator.Array().Items(validator.String()).UniqueItems(true)).
+			AddProp(`address`, validator.Reference(m).RefersTo(`#/definitions/address`)).
+			Required(`id`, `name`, `address`),
+	)
+}
+
+// raceEnabled is set in race_test.go when the tests are run with -race
+var raceEnabled bool
+
+func newBenchRecord() *benchRecord {
+	r := &benchRecord{
+		ID:     1,
+		Name:   "John Doe",
+		Active: true,
+		Tags:   []string{"foo", "bar", "baz"},
+		Address: benchAddress{
+			Street: "1 Infinite Loop",
+			City:   "Cupertino",
+		},
+	}
+	r.Email.Set("john@example.com")
+	r.Score.Set(99.5)
+	r.Address.Zip.Set("95014")
+	return r
+}
+
+func newBenchMap() map[string]interface{} {
+	return map[string]interface{}{
+		"id":     float64(1),
+		"name":   "John Doe",
+		"email":  "john@example.com",
+		"score":  99.5,
+		"active": true,
+		"tags":   []interface{}{"foo", "bar", "baz"},
+		"address": map[string]interface{}{
+			"street": "1 Infinite Loop",
+			"city":   "Cupertino",
+			"zip":    "95014",
+		},
+	}
+}
+
+// newNestedReferenceValidator creates a validator for a linked list
+// of the given depth, where each node refers to the next one through
+// a JSON reference
+func newNestedReferenceValidator() *validator.JSVal {
+	m := &validator.ConstraintMap{}
+	m.SetReference(`#/definitions/node`, validator.Object().
+		AddProp(`value`, validator.Integer()).
+		AddProp(`next`, validator.Reference(m).RefersTo(`#/definitions/node`)).
+		Required(`value`),
+	)
+	return validator.New().SetConstraintMap(m).SetRoot(
+		validator.Reference(m).RefersTo(`#/definitions/node`),
+	)
+}
+
+func newNestedReferenceValue(depth int) map[string]interface{} {
+	root := map[string]interface{}{"value": float64(0)}
+	cur := root
+	for i := 1; i < depth; i++ {
+		next := map[string]interface{}{"value": float64(i)}
+		cur["next"] = next
+		cur = next
+	}
+	return root
+}
+
+func newUniqueItemsValue(n int) []interface{} {
+	l := make([]interface{}, n)
+	for i := range l {
+		l[i] = fmt.Sprintf("item-%d", i)
+	}
+	return l
+}
+
+func runValidateBenchmark(b *testing.B, v *validator.JSVal, x interface{}) {
+	if err := v.Validate(x); err != nil {
+		b.Fatalf("validation should succeed: %s", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := v.Validate(x); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConstraints(b *testing.B) {
+	data := []struct {
+		name  string
+		c     validator.Constraint
+		value interface{}
+	}{
+		{"Empty", validator.EmptyConstraint, "foo"},
+		{"Null", validator.NullConstraint, nil},
+		{"Boolean", validator.Boolean(), true},
+		{"String", validator.String().MinLength(1).MaxLength(10), "foobar"},
+		{"StringRegexp", validator.String().RegexpString(`^[a-z]+$`), "foobar"},
+		{"StringFormat", validator.String().Format(`email`), "john@example.com"},
+		{"StringEnum", validator.String().Enum("foo", "bar", "baz"), "baz"},
+		{"Integer", validator.Integer().Minimum(0).Maximum(100), float64(10)},
+		{"Number", validator.Number().Minimum(0).Maximum(100).MultipleOf(0.5), 10.5},
+		{"Array", validator.Array().Items(validator.String()).MinItems(1), []interface{}{"foo", "bar", "baz"}},
+		{"ArrayPositional", validator.Array().PositionalItems([]validator.Constraint{validator.String(), validator.Integer()}), []interface{}{"foo", float64(1)}},
+		{"Object", validator.Object().AddProp(`foo`, validator.String()).Required(`foo`), map[string]interface{}{"foo": "bar"}},
+		{"ObjectPatternProperties", validator.Object().PatternPropertiesString(`^x-`, validator.String()), map[string]interface{}{"x-foo": "bar"}},
+		{"Any", validator.Any().Add(validator.Integer()).Add(validator.String()), "foo"},
+		{"All", validator.All().Add(validator.String().MinLength(1)).Add(validator.String().MaxLength(10)), "foo"},
+		{"OneOf", validator.OneOf().Add(validator.Integer()).Add(validator.String()), "foo"},
+		{"Not", validator.Not(validator.Integer()), "foo"},
+	}
+
+	for _, d := range data {
+		d := d
+		b.Run(d.name, func(b *testing.B) {
+			runValidateBenchmark(b, validator.New().SetRoot(d.c), d.value)
+		})
+	}
+}
+
+func BenchmarkObject(b *testing.B) {
+	b.Run("Map", func(b *testing.B) {
+		runValidateBenchmark(b, newBenchRuntimeValidator(), newBenchMap())
+	})
+	b.Run("Struct", func(b *testing.B) {
+		runValidateBenchmark(b, newBenchRuntimeValidator(), newBenchRecord())
+	})
+}
+
+func BenchmarkNestedReference(b *testing.B) {
+	for _, depth := range []int{1, 10, 100} {
+		depth := depth
+		b.Run(fmt.Sprintf("Depth%d", depth), func(b *testing.B) {
+			runValidateBenchmark(b, newNestedReferenceValidator(), newNestedReferenceValue(depth))
+		})
+	}
+}
+
+func BenchmarkUniqueItems(b *testing.B) {
+	for _, n := range []int{10, 1000, 10000} {
+		n := n
+		b.Run(fmt.Sprintf("Items%d", n), func(b *testing.B) {
+			v := validator.New().SetRoot(validator.Array().Items(validator.String()).UniqueItems(true))
+			runValidateBenchmark(b, v, newUniqueItemsValue(n))
+		})
+	}
+}
+
+func BenchmarkGenerated(b *testing.B) {
+	b.Run("Runtime", func(b *testing.B) {
+		runValidateBenchmark(b, newBenchRuntimeValidator(), newBenchRecord())
+	})
+	b.Run("Generated", func(b *testing.B) {
+		runValidateBenchmark(b, BenchGenerated, newBenchRecord())
+	})
+}
+
+// TestBenchGenerated makes sure that bench_generated_test.go is the
+// output of the Generator for newBenchRuntimeValidator
+func TestBenchGenerated(t *testing.T) {
+	expected, err := ioutil.ReadFile("bench_generated_test.go")
+	if !assert.NoError(t, err, "reading bench_generated_test.go succeeds") {
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by the Generator from newBenchRuntimeValidator in bench_test.go. DO NOT EDIT.\n\n")
+	g := validator.NewGenerator().Package("validator_test").Prefix("BenchGenerated")
+	if !assert.NoError(t, g.Process(&buf, newBenchRuntimeValidator()), "Process() succeeds") {
+		return
+	}
+	if !assert.Equal(t, string(expected), buf.String(), "bench_generated_test.go is up to date") {
+		return
+	}
+}
+
+// TestAllocations makes sure that the number of allocations made per
+// call to Validate does not regress. If you have made a change that
+// legitimately reduces the number of allocations, lower the numbers
+// here accordingly.
+func TestAllocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector changes the number of allocations")
+	}
+
+	data := []struct {
+		name  string
+		v     *validator.JSVal
+		value interface{}
+		max   float64
+	}{
+		{"Boolean", validator.New().SetRoot(validator.Boolean()), true, 2},
+		{"String", validator.New().SetRoot(validator.String().MinLength(1).MaxLength(10)), "foobar", 2},
+		{"StringRegexp", validator.New().SetRoot(validator.String().RegexpString(`^[a-z]+$`)), "foobar", 2},
+		{"StringEnum", validator.New().SetRoot(validator.String().Enum("foo", "bar", "baz")), "baz", 2},
+		{"Integer", validator.New().SetRoot(validator.Integer().Minimum(0).Maximum(100)), float64(10), 2},
+		{"Number", validator.New().SetRoot(validator.Number().Minimum(0).Maximum(100)), 10.5, 2},
+		{"All", validator.New().SetRoot(validator.All().Add(validator.String().MinLength(1)).Add(validator.String().MaxLength(10))), "foo", 2},
+		{"Array", validator.New().SetRoot(validator.Array().Items(validator.String())), []interface{}{"foo", "bar", "baz"}, 5},
+		{"Object", validator.New().SetRoot(validator.Object().AddProp(`foo`, validator.String()).Required(`foo`)), map[string]interface{}{"foo": "bar"}, 8},
+		{"ObjectMap", newBenchRuntimeValidator(), newBenchMap(), 58},
+		{"ObjectStruct", newBenchRuntimeValidator(), newBenchRecord(), 49},
+		{"NestedReference", newNestedReferenceValidator(), newNestedReferenceValue(10), 99},
+		{"UniqueItems", validator.New().SetRoot(validator.Array().Items(validator.String()).UniqueItems(true)), newUniqueItemsValue(100), 211},
+	}
+
+	for _, d := range data {
+		d := d
+		t.Run(d.name, func(t *testing.T) {
+			var err error
+			allocs := testing.AllocsPerRun(100, func() {
+				err = d.v.Validate(d.value)
+			})
+			if !assert.NoError(t, err, "validation should succeed") {
+				return
+			}
+			if !assert.True(t, allocs <= d.max, "expected at most %v allocations, got %v", d.max, allocs) {
+				return
+			}
+		})
+	}
+}
+
+// newAllOfChainValidator builds the kind of tree that deeply composed
+// allOf chains produce: each level adds one more string constraint
+func newAllOfChainValidator(depth int) *validator.JSVal {
+	c := validator.Constraint(validator.String().MinLength(1))
+	for i := 0; i < depth; i++ {
+		c = validator.All().Add(c).Add(validator.EmptyConstraint).Add(validator.String().MaxLength(int64(100 + i)))
+	}
+	return validator.New().SetRoot(c)
+}
+
+func BenchmarkAllOfChain(b *testing.B) {
+	b.Run("Plain", func(b *testing.B) {
+		runValidateBenchmark(b, newAllOfChainValidator(20), "foobar")
+	})
+	b.Run("Optimized", func(b *testing.B) {
+		runValidateBenchmark(b, newAllOfChainValidator(20).Optimize(), "foobar")
+	})
+}
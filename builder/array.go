@@ -20,6 +20,10 @@ type arrayT interface {
 	UniqueItems() bool
 }
 
+type negatedT interface {
+	IsNegated() bool
+}
+
 type draft04AdditionalItems interface {
 	AdditionalItems() *draft04.Schema
 }
@@ -63,7 +67,7 @@ func arrayItems(s interface{}) ([]schema.Schema, error) {
 	return nil, errors.New(`could not fetch items from schema`)
 }
 
-func buildArrayConstraint(ctx *buildctx, c *validator.ArrayConstraint, s arrayT) (err error) {
+func buildArrayConstraint(ctx *buildctx, c *validator.ArrayConstraint, s arrayT, raw rawSchema) (err error) {
 	if pdebug.Enabled {
 		g := pdebug.Marker("buildArrayConstraint").BindError(&err)
 		defer g.End()
@@ -74,20 +78,23 @@ func buildArrayConstraint(ctx *buildctx, c *validator.ArrayConstraint, s arrayT)
 		if err != nil {
 			return errors.Wrap(err, `failed to extract items from schema`)
 		}
-		switch l := len(schemas); l {
-		case 0:
+		// A list of schemas is positional even if it only has one
+		// element
+		_, positional := raw["items"].([]interface{})
+		switch l := len(schemas); {
+		case l == 0:
 			// WTF
 			return errors.New(`invalid number of defintions in items field: 0`)
-		case 1:
-			specs, err := buildFromSchema(ctx, schemas[0])
+		case l == 1 && !positional:
+			specs, err := buildFromSchema(ctx, schemas[0], raw.schema("items"))
 			if err != nil {
 				return errors.Wrap(err, `failed to build schemas for items`)
 			}
 			c.Items(specs)
 		default:
-			specs := make([]validator.Constraint, l)
+			specs := make([]validator.Constraint, len(schemas))
 			for i, espec := range schemas {
-				item, err := buildFromSchema(ctx, espec)
+				item, err := buildFromSchema(ctx, espec, raw.item("items", i))
 				if err != nil {
 					return errors.Wrap(err, `failed to build constraints for item elements`)
 				}
@@ -95,25 +102,27 @@ func buildArrayConstraint(ctx *buildctx, c *validator.ArrayConstraint, s arrayT)
 			}
 			c.PositionalItems(specs)
 
-			if !s.HasAdditionalItems() {
-				if pdebug.Enabled {
-					pdebug.Printf("Disabling additional items")
-				}
-				// No additional items
-				c.AdditionalItems(nil)
-			} else {
+			if s.HasAdditionalItems() {
 				as, err := arrayAdditionalItems(s)
 				if err != nil {
 					return errors.Wrap(err, `invalid additional item spec`)
 				}
-				spec, err := buildFromSchema(ctx, as)
-				if err != nil {
-					return errors.Wrap(err, `failed to build constraints for additionalItems`)
-				}
-				if pdebug.Enabled {
-					pdebug.Printf("Using constraint for additional items ")
+				if n, ok := as.(negatedT); ok && n.IsNegated() {
+					if pdebug.Enabled {
+						pdebug.Printf("Disabling additional items")
+					}
+					// No additional items
+					c.AdditionalItems(nil)
+				} else {
+					spec, err := buildFromSchema(ctx, as, raw.schema("additionalItems"))
+					if err != nil {
+						return errors.Wrap(err, `failed to build constraints for additionalItems`)
+					}
+					if pdebug.Enabled {
+						pdebug.Printf("Using constraint for additional items ")
+					}
+					c.AdditionalItems(spec)
 				}
-				c.AdditionalItems(spec)
 			}
 		}
 	}
@@ -123,7 +132,7 @@ func buildArrayConstraint(ctx *buildctx, c *validator.ArrayConstraint, s arrayT)
 	}
 
 	if s.HasMaxItems() {
-		c.MinItems(int(s.MaxItems())) // TODO: do away with type conversion
+		c.MaxItems(int(s.MaxItems())) // TODO: do away with type conversion
 	}
 
 	if s.HasUniqueItems() {
@@ -31,9 +31,14 @@ type commonT interface {
 }
 
 // Builder builds Validator objects from JSON schemas
-type Builder struct{}
+type Builder struct {
+	cm       *validator.ConstraintMap
+	keywords map[string]KeywordFunc
+	strict   bool
+}
 
 type buildctx struct {
+	B *Builder
 	V *validator.JSVal
 	S schema.Schema
 	R map[string]struct{}
@@ -44,6 +49,38 @@ func New() *Builder {
 	return &Builder{}
 }
 
+// ConstraintMap makes all validators built by this builder share cm.
+// The constraints built for references are registered in cm, and
+// references that have already been built are reused, so that
+// validators for schemas that refer to the same definitions do not
+// build them over and over. Schemas that refer to themselves ("#")
+// can not be built with a shared ConstraintMap.
+func (b *Builder) ConstraintMap(cm *validator.ConstraintMap) *Builder {
+	b.cm = cm
+	return b
+}
+
+// Keyword registers fn as the factory for the custom keyword name.
+// Whenever a schema contains name, fn is called with its value, and
+// the constraint that it returns is added to the constraints built
+// for the schema. Registering a keyword that the builder already
+// understands adds fn's constraint to the built-in one
+func (b *Builder) Keyword(name string, fn KeywordFunc) *Builder {
+	if b.keywords == nil {
+		b.keywords = make(map[string]KeywordFunc)
+	}
+	b.keywords[name] = fn
+	return b
+}
+
+// Strict makes the builder fail on keywords that it does not
+// understand and that have not been registered using Keyword. By
+// default such keywords are ignored
+func (b *Builder) Strict(v bool) *Builder {
+	b.strict = v
+	return b
+}
+
 type draft04Builder struct{}
 type draft07Builder struct{}
 
@@ -82,16 +119,26 @@ func (b *Builder) BuildWithCtx(s schema.Schema, jsctx interface{}) (v *validator
 	}
 
 	v = validator.New()
+	if b.cm != nil {
+		v.SetConstraintMap(b.cm)
+	}
 	ctx := buildctx{
+		B: b,
 		V: v,
 		S: s,
 		R: map[string]struct{}{}, // names of references used
 	}
 
 	var c validator.Constraint
-	c, err = buildFromSchema(&ctx, s)
+	c, err = buildFromSchema(&ctx, s, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	if _, ok := ctx.R["#"]; ok {
+		if b.cm != nil {
+			return nil, errors.New("schemas that refer to themselves can not be built with a shared ConstraintMap")
+		}
 		v.SetReference("#", c)
 		delete(ctx.R, "#")
 	}
@@ -138,10 +185,12 @@ func compileReferences(ctx *buildctx, r *jsref.Resolver, v *validator.JSVal, ref
 	}
 
 	var s1 schema.Schema
+	var raw rawSchema
 	switch thing.(type) {
 	case schema.Schema:
 		s1 = thing.(schema.Schema)
 	case map[string]interface{}:
+		raw = thing.(map[string]interface{})
 		s1 = &draft04.Schema{} // schema.New()
 		// XXX Very inefficient, should probably fix
 		var buf bytes.Buffer
@@ -153,7 +202,7 @@ func compileReferences(ctx *buildctx, r *jsref.Resolver, v *validator.JSVal, ref
 		}
 	}
 
-	c1, err := buildFromSchema(ctx, s1)
+	c1, err := buildFromSchema(ctx, s1, raw)
 	if err != nil {
 		return err
 	}
@@ -167,12 +216,15 @@ func compileReferences(ctx *buildctx, r *jsref.Resolver, v *validator.JSVal, ref
 	return nil
 }
 
-func buildFromSchema(ctx *buildctx, s schema.Schema) (validator.Constraint, error) {
+// buildFromSchema builds the constraints for s. raw is the JSON
+// representation of s, taken from the representation of its parent, or
+// nil if it is not known yet
+func buildFromSchema(ctx *buildctx, s schema.Schema, raw rawSchema) (validator.Constraint, error) {
 	var c validator.Constraint
 	var err error
 	switch v := s.(type) {
 	case *draft04.Schema:
-		c, err = buildFromDraft04Schema(ctx, v)
+		c, err = buildFromDraft04Schema(ctx, v, raw)
 		if err != nil {
 			return nil, errors.Wrap(err, `failed to build draft-04 validator`)
 		}
@@ -201,7 +253,7 @@ func buildFromDraft07Schema(ctx *buildctx, s *draft07.Schema) (validator.Constra
 	return ct, nil
 }
 
-func buildFromDraft04Schema(ctx *buildctx, s *draft04.Schema) (validator.Constraint, error) {
+func buildFromDraft04Schema(ctx *buildctx, s *draft04.Schema, raw rawSchema) (validator.Constraint, error) {
 	if hasReference(s) {
 		c := validator.Reference(ctx.V)
 		if err := buildReferenceConstraint(ctx, c, s); err != nil {
@@ -210,6 +262,19 @@ func buildFromDraft04Schema(ctx *buildctx, s *draft04.Schema) (validator.Constra
 		return c, nil
 	}
 
+	if raw == nil {
+		var err error
+		raw, err = encodeSchema(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s, raw, data, err := extractDataReferences(s, raw)
+	if err != nil {
+		return nil, errors.Wrap(err, `failed to build $data references`)
+	}
+
 	ct := validator.All()
 
 	switch {
@@ -217,7 +282,7 @@ func buildFromDraft04Schema(ctx *buildctx, s *draft04.Schema) (validator.Constra
 		if pdebug.Enabled {
 			pdebug.Printf("Not constraint")
 		}
-		c1, err := buildFromSchema(ctx, s.Not())
+		c1, err := buildFromSchema(ctx, s.Not(), raw.schema("not"))
 		if err != nil {
 			return nil, err
 		}
@@ -227,38 +292,68 @@ func buildFromDraft04Schema(ctx *buildctx, s *draft04.Schema) (validator.Constra
 			pdebug.Printf("AllOf constraint")
 		}
 		ac := validator.All()
+		i := 0
 		for s1 := range s.AllOf().Iterator() {
-			c1, err := buildFromSchema(ctx, s1)
+			c1, err := buildFromSchema(ctx, s1, raw.item("allOf", i))
 			if err != nil {
 				return nil, err
 			}
 			ac.Add(c1)
+			i++
 		}
 		ct.Add(ac.Reduce())
 	case s.HasAnyOf():
 		if pdebug.Enabled {
 			pdebug.Printf("AnyOf constraint")
 		}
+		spec, err := lookupDiscriminator(raw)
+		if err != nil {
+			return nil, err
+		}
+		if spec != nil {
+			dc, err := buildDiscriminatorConstraint(ctx, spec, s.AnyOf())
+			if err != nil {
+				return nil, errors.Wrap(err, `failed to build discriminator constraint`)
+			}
+			ct.Add(dc)
+			break
+		}
 		ac := validator.Any()
+		i := 0
 		for s1 := range s.AnyOf().Iterator() {
-			c1, err := buildFromSchema(ctx, s1)
+			c1, err := buildFromSchema(ctx, s1, raw.item("anyOf", i))
 			if err != nil {
 				return nil, err
 			}
 			ac.Add(c1)
+			i++
 		}
 		ct.Add(ac.Reduce())
 	case s.HasOneOf():
 		if pdebug.Enabled {
 			pdebug.Printf("OneOf constraint")
 		}
+		spec, err := lookupDiscriminator(raw)
+		if err != nil {
+			return nil, err
+		}
+		if spec != nil {
+			dc, err := buildDiscriminatorConstraint(ctx, spec, s.OneOf())
+			if err != nil {
+				return nil, errors.Wrap(err, `failed to build discriminator constraint`)
+			}
+			ct.Add(dc)
+			break
+		}
 		oc := validator.OneOf()
+		i := 0
 		for s1 := range s.OneOf().Iterator() {
-			c1, err := buildFromSchema(ctx, s1)
+			c1, err := buildFromSchema(ctx, s1, raw.item("oneOf", i))
 			if err != nil {
 				return nil, err
 			}
 			oc.Add(c1)
+			i++
 		}
 		ct.Add(oc.Reduce())
 	}
@@ -307,13 +402,13 @@ func buildFromDraft04Schema(ctx *buildctx, s *draft04.Schema) (validator.Constra
 				c = bc
 			case common.ArrayType:
 				ac := validator.Array()
-				if err := buildArrayConstraint(ctx, ac, s); err != nil {
+				if err := buildArrayConstraint(ctx, ac, s, raw); err != nil {
 					return nil, err
 				}
 				c = ac
 			case common.ObjectType:
 				oc := validator.Object()
-				if err := buildObjectConstraint(ctx, oc, s); err != nil {
+				if err := buildObjectConstraint(ctx, oc, s, raw); err != nil {
 					return nil, err
 				}
 				c = oc
@@ -336,6 +431,14 @@ func buildFromDraft04Schema(ctx *buildctx, s *draft04.Schema) (validator.Constra
 		}
 	}
 
+	if err := buildKeywordConstraints(ctx, ct, s, raw); err != nil {
+		return nil, err
+	}
+
+	for _, c := range data {
+		ct.Add(c)
+	}
+
 	return ct.Reduce(), nil
 }
 
@@ -14,6 +14,7 @@ func TestGuessType(t *testing.T) {
 		`{ "items": { "type": "number" } }`:                 {common.ArrayType},
 		`{ "properties": { "foo": { "type": "number" } } }`: {common.ObjectType},
 		`{ "additionalProperties": false }`:                 {common.ObjectType},
+		`{ "dependencies": { "foo": ["bar"] } }`:            {common.ObjectType},
 		`{ "maxLength": 10 }`:                               {common.StringType},
 		`{ "pattern": "^[a-fA-F0-9]+$" }`:                   {common.StringType},
 		`{ "format": "email" }`:                             {common.StringType},
@@ -0,0 +1,66 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/go-json-schema/schema/draft04"
+	"github.com/go-json-schema/validator"
+	"github.com/pkg/errors"
+)
+
+// extractDataReferences looks for keywords whose value is a $data
+// reference, such as {"minimum": {"$data": "1/start"}}, in raw, the
+// JSON representation of s, and returns a DataConstraint for each of
+// them, along with a copy of s and raw without these keywords, so that
+// they are not built as regular keywords. If there are none, s and raw
+// themselves are returned
+func extractDataReferences(s *draft04.Schema, raw rawSchema) (*draft04.Schema, rawSchema, []validator.Constraint, error) {
+	var names []string
+	for name, value := range raw {
+		m, ok := value.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			continue
+		}
+		if _, ok := m["$data"]; ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return s, raw, nil, nil
+	}
+	sort.Strings(names)
+
+	l := make([]validator.Constraint, 0, len(names))
+	for _, name := range names {
+		if !validator.IsDataKeyword(name) {
+			return nil, nil, nil, errors.Errorf(`keyword %s does not support $data references`, name)
+		}
+		pointer, ok := raw[name].(map[string]interface{})["$data"].(string)
+		if !ok {
+			return nil, nil, nil, errors.Errorf(`$data reference of keyword %s must be a string`, name)
+		}
+		l = append(l, validator.Data(name, pointer))
+	}
+
+	stripped := make(rawSchema, len(raw))
+	for name, value := range raw {
+		stripped[name] = value
+	}
+	for _, name := range names {
+		delete(stripped, name)
+	}
+
+	// XXX Same as for resolved references, the schema is rebuilt from
+	// its JSON representation
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(stripped); err != nil {
+		return nil, nil, nil, errors.Wrap(err, `failed to encode schema`)
+	}
+	s1 := &draft04.Schema{}
+	if err := json.NewDecoder(&buf).Decode(s1); err != nil {
+		return nil, nil, nil, errors.Wrap(err, `failed to decode schema`)
+	}
+	return s1, stripped, l, nil
+}
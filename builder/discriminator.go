@@ -0,0 +1,101 @@
+package builder
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-json-schema/schema/draft04"
+	"github.com/go-json-schema/validator"
+	"github.com/lestrrat/go-pdebug"
+	"github.com/pkg/errors"
+)
+
+// discriminatorSpec is the OpenAPI discriminator keyword
+type discriminatorSpec struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping"`
+}
+
+// lookupDiscriminator returns the discriminator keyword of the schema
+// whose JSON representation is raw, or nil if it has none. The schema
+// types do not expose keywords that are not part of JSON Schema, hence
+// the use of the raw representation. Both the OpenAPI 3 object form
+// and the Swagger 2 form, which only names the property, are accepted
+func lookupDiscriminator(raw rawSchema) (*discriminatorSpec, error) {
+	v, ok := raw["discriminator"]
+	if !ok {
+		return nil, nil
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, `failed to encode discriminator`)
+	}
+
+	var spec discriminatorSpec
+	if err := json.Unmarshal(buf, &spec.PropertyName); err != nil {
+		if err := json.Unmarshal(buf, &spec); err != nil {
+			return nil, errors.Wrap(err, `invalid discriminator`)
+		}
+	}
+	if spec.PropertyName == "" {
+		return nil, errors.New("discriminator must specify a propertyName")
+	}
+	return &spec, nil
+}
+
+// buildDiscriminatorConstraint builds a DiscriminatorConstraint from
+// the branches of an anyOf or oneOf annotated with a discriminator.
+// Values listed in the mapping select the branch they refer to, either
+// by reference or by schema name. Branches that are not listed can be
+// selected by their schema name, which is the last component of their
+// reference.
+func buildDiscriminatorConstraint(ctx *buildctx, spec *discriminatorSpec, branches *draft04.SchemaList) (c *validator.DiscriminatorConstraint, err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("buildDiscriminatorConstraint '%s'", spec.PropertyName).BindError(&err)
+		defer g.End()
+	}
+
+	c = validator.Discriminator(spec.PropertyName)
+
+	var refs []string
+	byRef := make(map[string]validator.Constraint)
+	byName := make(map[string]string)
+	for s1 := range branches.Iterator() {
+		if !hasReference(s1) {
+			return nil, errors.New("discriminator requires all branches to be references")
+		}
+		c1, err := buildFromSchema(ctx, s1, nil)
+		if err != nil {
+			return nil, err
+		}
+		ref := s1.Reference()
+		refs = append(refs, ref)
+		byRef[ref] = c1
+		byName[ref[strings.LastIndex(ref, "/")+1:]] = ref
+	}
+
+	for value, target := range spec.Mapping {
+		ref := target
+		if !strings.ContainsAny(target, "#/") {
+			r, ok := byName[target]
+			if !ok {
+				return nil, errors.Errorf(`discriminator mapping for '%s' refers to unknown schema '%s'`, value, target)
+			}
+			ref = r
+		}
+		c1, ok := byRef[ref]
+		if !ok {
+			return nil, errors.Errorf(`discriminator mapping for '%s' refers to '%s', which is not one of the branches`, value, target)
+		}
+		c.Map(value, c1)
+	}
+
+	for _, ref := range refs {
+		name := ref[strings.LastIndex(ref, "/")+1:]
+		if _, ok := spec.Mapping[name]; ok {
+			continue
+		}
+		c.Map(name, byRef[ref])
+	}
+	return c, nil
+}
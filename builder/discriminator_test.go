@@ -0,0 +1,171 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-json-schema/schema"
+	"github.com/go-json-schema/schema/draft04"
+	"github.com/go-json-schema/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+const petSchema = `{
+  "oneOf": [
+    { "$ref": "#/definitions/Cat" },
+    { "$ref": "#/definitions/Dog" }
+  ],
+  "discriminator": {
+    "propertyName": "petType",
+    "mapping": { "kitty": "#/definitions/Cat" }
+  },
+  "definitions": {
+    "Cat": {
+      "type": "object",
+      "properties": {
+        "petType": { "type": "string" },
+        "lives": { "type": "integer", "maximum": 9 }
+      },
+      "required": ["petType"]
+    },
+    "Dog": {
+      "type": "object",
+      "properties": {
+        "petType": { "type": "string" },
+        "bark": { "type": "boolean" }
+      },
+      "required": ["petType"]
+    }
+  }
+}`
+
+func TestDiscriminator(t *testing.T) {
+	s, err := schema.Parse(strings.NewReader(petSchema), schema.WithSchemaID(draft04.SchemaID))
+	if !assert.NoError(t, err, "reading schema should succeed") {
+		return
+	}
+
+	v, err := New().Build(s)
+	if !assert.NoError(t, err, "Builder.Build should succeed") {
+		return
+	}
+
+	var dc *validator.DiscriminatorConstraint
+	validator.Walk(v.Root(), validator.VisitorFunc(func(_ string, c validator.Constraint) bool {
+		if c, ok := c.(*validator.DiscriminatorConstraint); ok {
+			dc = c
+		}
+		return dc == nil
+	}))
+	if !assert.NotNil(t, dc, "a DiscriminatorConstraint is built") {
+		return
+	}
+	if !assert.Equal(t, []string{"Cat", "Dog", "kitty"}, dc.GetMappingValues(), "explicit and implicit mappings are registered") {
+		return
+	}
+
+	valid := []interface{}{
+		map[string]interface{}{"petType": "kitty", "lives": float64(3)},
+		map[string]interface{}{"petType": "Cat"},
+		map[string]interface{}{"petType": "Dog", "bark": true},
+	}
+	for _, x := range valid {
+		if !assert.NoError(t, v.Validate(x), "%#v should be valid", x) {
+			return
+		}
+	}
+
+	invalid := map[string]interface{}{
+		"missing property": map[string]interface{}{"lives": float64(3)},
+		"unknown value":    map[string]interface{}{"petType": "fish"},
+		"invalid variant":  map[string]interface{}{"petType": "kitty", "lives": float64(12)},
+		"not an object":    "kitty",
+	}
+	messages := map[string]string{
+		"missing property": "discriminator property 'petType' is missing",
+		"unknown value":    "unknown value 'fish' for discriminator property 'petType' (expected one of: Cat, Dog, kitty)",
+		"invalid variant":  "numeric value is greater than the maximum",
+		"not an object":    "value must be an object",
+	}
+	for name, x := range invalid {
+		err := v.Validate(x)
+		if !assert.Error(t, err, "%s should be invalid", name) {
+			return
+		}
+		if !assert.Contains(t, err.Error(), messages[name], "%s error message matches", name) {
+			return
+		}
+	}
+}
+
+func TestDiscriminatorInvalid(t *testing.T) {
+	data := map[string]string{
+		"inline branch":  `{ "oneOf": [ { "type": "object" } ], "discriminator": { "propertyName": "petType" } }`,
+		"unknown target": `{ "oneOf": [ { "$ref": "#/definitions/Cat" } ], "discriminator": { "propertyName": "petType", "mapping": { "dog": "Dog" } }, "definitions": { "Cat": { "type": "object" } } }`,
+		"no property":    `{ "oneOf": [ { "$ref": "#/definitions/Cat" } ], "discriminator": {}, "definitions": { "Cat": { "type": "object" } } }`,
+	}
+
+	for name, src := range data {
+		t.Run(name, func(t *testing.T) {
+			s, err := schema.Parse(strings.NewReader(src), schema.WithSchemaID(draft04.SchemaID))
+			if !assert.NoError(t, err, "reading schema should succeed") {
+				return
+			}
+			_, err = New().Build(s)
+			if !assert.Error(t, err, "Builder.Build should fail") {
+				return
+			}
+		})
+	}
+}
+
+func TestDiscriminatorNested(t *testing.T) {
+	const src = `{
+  "type": "object",
+  "properties": {
+    "pets": {
+      "type": "array",
+      "items": {
+        "allOf": [
+          { "type": "object" },
+          {
+            "oneOf": [
+              { "$ref": "#/definitions/Cat" },
+              { "$ref": "#/definitions/Dog" }
+            ],
+            "discriminator": "petType"
+          }
+        ]
+      }
+    }
+  },
+  "definitions": {
+    "Cat": { "type": "object", "properties": { "lives": { "type": "integer" } } },
+    "Dog": { "type": "object", "properties": { "bark": { "type": "boolean" } } }
+  }
+}`
+
+	s, err := schema.Parse(strings.NewReader(src), schema.WithSchemaID(draft04.SchemaID))
+	if !assert.NoError(t, err, "reading schema should succeed") {
+		return
+	}
+
+	v, err := New().Build(s)
+	if !assert.NoError(t, err, "Builder.Build should succeed") {
+		return
+	}
+
+	x := map[string]interface{}{
+		"pets": []interface{}{
+			map[string]interface{}{"petType": "Cat", "lives": float64(9)},
+			map[string]interface{}{"petType": "fish"},
+		},
+	}
+	err = v.Validate(x)
+	if !assert.Error(t, err, "unknown discriminator values in nested schemas are invalid") {
+		return
+	}
+	if !assert.Contains(t, err.Error(), "unknown value 'fish' for discriminator property 'petType'", "error message matches") {
+		return
+	}
+}
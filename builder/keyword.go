@@ -0,0 +1,103 @@
+package builder
+
+import (
+	"sort"
+
+	"github.com/go-json-schema/schema"
+	"github.com/go-json-schema/validator"
+	"github.com/pkg/errors"
+)
+
+// KeywordFunc creates the constraint for a custom keyword. value is the
+// value of the keyword, decoded as by encoding/json, and s is the
+// schema that the keyword appears in
+type KeywordFunc func(value interface{}, s schema.Schema) (validator.Constraint, error)
+
+// knownKeywords lists the keywords understood by the builder: the
+// draft-04 vocabulary, the OpenAPI extensions that it supports, and
+// annotations that do not affect validation
+var knownKeywords = map[string]struct{}{
+	"$schema":              {},
+	"id":                   {},
+	"$ref":                 {},
+	"title":                {},
+	"description":          {},
+	"default":              {},
+	"multipleOf":           {},
+	"maximum":              {},
+	"exclusiveMaximum":     {},
+	"minimum":              {},
+	"exclusiveMinimum":     {},
+	"maxLength":            {},
+	"minLength":            {},
+	"pattern":              {},
+	"additionalItems":      {},
+	"items":                {},
+	"maxItems":             {},
+	"minItems":             {},
+	"uniqueItems":          {},
+	"maxProperties":        {},
+	"minProperties":        {},
+	"required":             {},
+	"additionalProperties": {},
+	"definitions":          {},
+	"properties":           {},
+	"patternProperties":    {},
+	"dependencies":         {},
+	"enum":                 {},
+	"type":                 {},
+	"allOf":                {},
+	"anyOf":                {},
+	"oneOf":                {},
+	"not":                  {},
+	"format":               {},
+	"discriminator":        {},
+	"readOnly":             {},
+	"writeOnly":            {},
+	"$comment":             {},
+	"examples":             {},
+	"example":              {},
+	"deprecated":           {},
+}
+
+// IsKnownKeyword returns true if name is a keyword that the builder
+// understands without registering it
+func IsKnownKeyword(name string) bool {
+	_, ok := knownKeywords[name]
+	return ok
+}
+
+// buildKeywordConstraints adds the constraints created by custom
+// keywords in s, whose JSON representation is raw, to ct. In strict
+// mode, keywords that are neither known nor registered are reported
+// as errors
+func buildKeywordConstraints(ctx *buildctx, ct *validator.AllConstraint, s schema.Schema, raw rawSchema) error {
+	if len(ctx.B.keywords) == 0 && !ctx.B.strict {
+		return nil
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fn, ok := ctx.B.keywords[name]
+		if !ok {
+			if ctx.B.strict && !IsKnownKeyword(name) {
+				return errors.Errorf(`unknown keyword %s`, name)
+			}
+			continue
+		}
+
+		c, err := fn(raw[name], s)
+		if err != nil {
+			return errors.Wrapf(err, `failed to build constraint for keyword %s`, name)
+		}
+		if c != nil {
+			ct.Add(c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-json-schema/schema"
+	"github.com/go-json-schema/validator"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// uniqueBy requires the objects in an array to have distinct values
+// for a property
+type uniqueBy string
+
+func (uniqueBy) DefaultValue() interface{} { return nil }
+func (uniqueBy) HasDefault() bool          { return false }
+func (c uniqueBy) Validate(x interface{}) error {
+	l, ok := x.([]interface{})
+	if !ok {
+		return nil
+	}
+	seen := make(map[interface{}]struct{})
+	for _, item := range l {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := seen[m[string(c)]]; ok {
+			return errors.Errorf(`duplicate value for %s: %v`, string(c), m[string(c)])
+		}
+		seen[m[string(c)]] = struct{}{}
+	}
+	return nil
+}
+
+func uniqueByKeyword(value interface{}, s schema.Schema) (validator.Constraint, error) {
+	name, ok := value.(string)
+	if !ok {
+		return nil, errors.New("x-unique-by must be a string")
+	}
+	return uniqueBy(name), nil
+}
+
+const keywordSchema = `{
+  "type": "object",
+  "properties": {
+    "users": {
+      "type": "array",
+      "x-unique-by": "id",
+      "items": { "type": "object", "properties": { "id": { "type": "integer" } } }
+    }
+  }
+}`
+
+func TestKeyword(t *testing.T) {
+	s, err := schema.Parse(strings.NewReader(keywordSchema))
+	if !assert.NoError(t, err, "schema.Parse should succeed") {
+		return
+	}
+
+	v, err := New().Keyword("x-unique-by", uniqueByKeyword).Build(s)
+	if !assert.NoError(t, err, "Build should succeed") {
+		return
+	}
+
+	valid := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"id": float64(1)},
+			map[string]interface{}{"id": float64(2)},
+		},
+	}
+	if !assert.NoError(t, v.Validate(valid), "distinct ids are valid") {
+		return
+	}
+
+	invalid := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"id": float64(1)},
+			map[string]interface{}{"id": float64(1)},
+		},
+	}
+	if !assert.Error(t, v.Validate(invalid), "duplicate ids are invalid") {
+		return
+	}
+
+	// Without the keyword, x-unique-by is ignored
+	v, err = New().Build(s)
+	if !assert.NoError(t, err, "Build should succeed") {
+		return
+	}
+	if !assert.NoError(t, v.Validate(invalid), "x-unique-by is ignored") {
+		return
+	}
+
+	s, err = schema.Parse(strings.NewReader(`{ "type": "string", "x-unique-by": 1 }`))
+	if !assert.NoError(t, err, "schema.Parse should succeed") {
+		return
+	}
+	if _, err := New().Keyword("x-unique-by", uniqueByKeyword).Build(s); !assert.Error(t, err, "factory errors are reported") {
+		return
+	}
+}
+
+func TestStrict(t *testing.T) {
+	s, err := schema.Parse(strings.NewReader(keywordSchema))
+	if !assert.NoError(t, err, "schema.Parse should succeed") {
+		return
+	}
+
+	_, err = New().Strict(true).Build(s)
+	if !assert.Error(t, err, "unknown keywords are reported in strict mode") {
+		return
+	}
+	if !assert.Contains(t, err.Error(), "unknown keyword x-unique-by", "error names the keyword") {
+		return
+	}
+
+	if _, err := New().Strict(true).Keyword("x-unique-by", uniqueByKeyword).Build(s); !assert.NoError(t, err, "registered keywords are accepted in strict mode") {
+		return
+	}
+
+	s, err = schema.Parse(strings.NewReader(`{ "type": "string", "title": "name", "minLength": 1, "example": "x" }`))
+	if !assert.NoError(t, err, "schema.Parse should succeed") {
+		return
+	}
+	if _, err := New().Strict(true).Build(s); !assert.NoError(t, err, "known keywords are accepted in strict mode") {
+		return
+	}
+}
@@ -20,7 +20,7 @@ type objectT interface {
 	Required() []string
 }
 
-func buildObjectConstraint(ctx *buildctx, c *validator.ObjectConstraint, s objectT) (err error) {
+func buildObjectConstraint(ctx *buildctx, c *validator.ObjectConstraint, s objectT, raw rawSchema) (err error) {
 	if pdebug.Enabled {
 		g := pdebug.Marker("ObjectConstraint.FromSchema").BindError(&err)
 		defer g.End()
@@ -40,7 +40,7 @@ func buildObjectConstraint(ctx *buildctx, c *validator.ObjectConstraint, s objec
 
 	switch v := s.(type) {
 	case *draft04.Schema:
-		return buildDraft04ObjectConstraint(ctx, c, v)
+		return buildDraft04ObjectConstraint(ctx, c, v, raw)
 	case *draft07.Schema:
 		return buildDraft07ObjectConstraint(ctx, c, v)
 	default:
@@ -52,21 +52,33 @@ func buildDraft07ObjectConstraint(ctx *buildctx, c *validator.ObjectConstraint,
 	return nil
 }
 
-func buildDraft04ObjectConstraint(ctx *buildctx, c *validator.ObjectConstraint, s *draft04.Schema) error {
+func buildDraft04ObjectConstraint(ctx *buildctx, c *validator.ObjectConstraint, s *draft04.Schema, raw rawSchema) error {
 	if s.HasProperties() {
 		for prop := range s.Properties().Iterator() {
-			cprop, err := buildFromSchema(ctx, prop.Definition())
+			rawProp := raw.property("properties", prop.Name())
+			cprop, err := buildFromSchema(ctx, prop.Definition(), rawProp)
 			if err != nil {
 				return err
 			}
 
 			c.AddProp(prop.Name(), cprop)
+
+			readOnly, writeOnly, err := lookupAccessMode(rawProp)
+			if err != nil {
+				return errors.Wrapf(err, `failed to read access mode of property %s`, prop.Name())
+			}
+			if readOnly {
+				c.ReadOnly(prop.Name())
+			}
+			if writeOnly {
+				c.WriteOnly(prop.Name())
+			}
 		}
 	}
 
 	if s.HasPatternProperties() {
 		for prop := range s.PatternProperties().Iterator() {
-			cprop, err := buildFromSchema(ctx, prop.Definition())
+			cprop, err := buildFromSchema(ctx, prop.Definition(), raw.property("patternProperties", prop.Name()))
 			if err != nil {
 				return err
 			}
@@ -88,7 +100,7 @@ func buildDraft04ObjectConstraint(ctx *buildctx, c *validator.ObjectConstraint,
 		} else if ap.IsEmpty() {
 			c.AdditionalProperties(validator.EmptyConstraint)
 		} else {
-			aitem, err := buildFromSchema(ctx, ap)
+			aitem, err := buildFromSchema(ctx, ap, raw.schema("additionalProperties"))
 			if err != nil {
 				return errors.Wrap(err, `failed to build additional proerties schema`)
 			}
@@ -102,7 +114,7 @@ func buildDraft04ObjectConstraint(ctx *buildctx, c *validator.ObjectConstraint,
 		}
 
 		for prop := range s.Dependencies().Schemas().Iterator() {
-			depc, err := buildFromSchema(ctx, prop.Definition())
+			depc, err := buildFromSchema(ctx, prop.Definition(), raw.property("dependencies", prop.Name()))
 			if err != nil {
 				return errors.Wrapf(err, `failed to build dependency %s`, prop.Name())
 			}
@@ -114,6 +126,24 @@ func buildDraft04ObjectConstraint(ctx *buildctx, c *validator.ObjectConstraint,
 	return nil
 }
 
+// lookupAccessMode returns the readOnly and writeOnly annotations of
+// the schema whose JSON representation is raw. Like the discriminator,
+// they are not exposed by the draft-04 schema type
+func lookupAccessMode(raw rawSchema) (readOnly bool, writeOnly bool, err error) {
+	for name, dst := range map[string]*bool{"readOnly": &readOnly, "writeOnly": &writeOnly} {
+		v, ok := raw[name]
+		if !ok || v == nil {
+			continue
+		}
+		b, ok := v.(bool)
+		if !ok {
+			return false, false, errors.New(`readOnly and writeOnly must be booleans`)
+		}
+		*dst = b
+	}
+	return readOnly, writeOnly, nil
+}
+
 func schemaLooksLikeObject(s schema.Schema) bool {
 	if v, ok := s.(interface {
 		HasProperties() bool
@@ -151,6 +181,12 @@ func schemaLooksLikeObject(s schema.Schema) bool {
 		return true
 	}
 
+	if v, ok := s.(interface {
+		HasDependencies() bool
+	}); ok && v.HasDependencies() {
+		return true
+	}
+
 	/*
 		for _, v := range s.Enum {
 			rv := reflect.ValueOf(v)
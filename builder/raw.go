@@ -0,0 +1,60 @@
+package builder
+
+import (
+	"encoding/json"
+
+	"github.com/go-json-schema/schema"
+	"github.com/pkg/errors"
+)
+
+// rawSchema is the JSON representation of a schema, decoded as by
+// encoding/json. Keywords that are not exposed by the schema types,
+// such as discriminator, are looked up in it. The builder
+// passes the representation of each subschema down along with the
+// subschema, so that the schema is encoded once per build rather than
+// once per node
+type rawSchema map[string]interface{}
+
+// encodeSchema returns the JSON representation of s
+func encodeSchema(s schema.Schema) (rawSchema, error) {
+	buf, err := json.Marshal(s)
+	if err != nil {
+		return nil, errors.Wrap(err, `failed to encode schema`)
+	}
+
+	var raw rawSchema
+	if err := json.Unmarshal(buf, &raw); err != nil {
+		return nil, errors.Wrap(err, `failed to decode schema`)
+	}
+	return raw, nil
+}
+
+// schema returns the subschema stored under name, or nil
+func (r rawSchema) schema(name string) rawSchema {
+	m, _ := r[name].(map[string]interface{})
+	return m
+}
+
+// property returns the subschema stored under prop in the map of
+// subschemas called name, such as properties or dependencies, or nil
+func (r rawSchema) property(name, prop string) rawSchema {
+	m, _ := r.schema(name)[prop].(map[string]interface{})
+	return m
+}
+
+// item returns the i-th subschema in the list called name, such as
+// allOf or items, or nil. A single schema is treated as a list of one
+func (r rawSchema) item(name string, i int) rawSchema {
+	switch v := r[name].(type) {
+	case map[string]interface{}:
+		if i == 0 {
+			return v
+		}
+	case []interface{}:
+		if i < len(v) {
+			m, _ := v[i].(map[string]interface{})
+			return m
+		}
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+func runBundle(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bundle", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	output := fs.String("o", "", "output file (default: stdout)")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: jsval bundle [-o <file>] <schema>\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError
+	}
+
+	bundled, err := bundleSchema(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "jsval: %s\n", err)
+		return exitError
+	}
+
+	buf, err := json.MarshalIndent(bundled, "", "  ")
+	if err != nil {
+		fmt.Fprintf(stderr, "jsval: failed to encode bundled schema: %s\n", err)
+		return exitError
+	}
+	buf = append(buf, '\n')
+
+	if *output == "" {
+		stdout.Write(buf)
+		return exitOK
+	}
+
+	if err := ioutil.WriteFile(*output, buf, 0644); err != nil {
+		fmt.Fprintf(stderr, "jsval: failed to write %s: %s\n", *output, err)
+		return exitError
+	}
+	return exitOK
+}
+
+// bundler inlines the schemas referred to by external references
+// (that is, references to other files) into the definitions of the
+// root schema, so that the result can be used on its own.
+type bundler struct {
+	root  string                 // file of the root schema
+	defs  map[string]interface{} // definitions in the root schema
+	added map[string]interface{} // definitions added by the bundler
+	names map[string]string      // file -> name of the definition
+}
+
+func bundleSchema(path string) (map[string]interface{}, error) {
+	x, err := readRawDocument(path)
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := x.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf(`schema %s is not an object`, path)
+	}
+
+	defs, _ := root["definitions"].(map[string]interface{})
+	if defs == nil {
+		defs = make(map[string]interface{})
+	}
+
+	b := &bundler{
+		root:  filepath.Clean(path),
+		defs:  defs,
+		added: make(map[string]interface{}),
+		names: make(map[string]string),
+	}
+	if err := b.walk(root, path, ""); err != nil {
+		return nil, err
+	}
+
+	for name, def := range b.added {
+		defs[name] = def
+	}
+	if len(defs) > 0 {
+		root["definitions"] = defs
+	}
+	return root, nil
+}
+
+func readRawDocument(path string) (interface{}, error) {
+	data, err := readDocument(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var x interface{}
+	if err := json.Unmarshal(data, &x); err != nil {
+		return nil, errors.Wrapf(err, `failed to decode %s`, path)
+	}
+	return x, nil
+}
+
+// schemaKeywords are the keywords whose value is a schema or a list of
+// schemas
+var schemaKeywords = map[string]struct{}{
+	"additionalItems":      {},
+	"additionalProperties": {},
+	"allOf":                {},
+	"anyOf":                {},
+	"contains":             {},
+	"else":                 {},
+	"if":                   {},
+	"items":                {},
+	"not":                  {},
+	"oneOf":                {},
+	"propertyNames":        {},
+	"then":                 {},
+}
+
+// schemaMapKeywords are the keywords whose value maps names to schemas
+var schemaMapKeywords = map[string]struct{}{
+	"$defs":             {},
+	"definitions":       {},
+	"dependencies":      {},
+	"dependentSchemas":  {},
+	"patternProperties": {},
+	"properties":        {},
+}
+
+// walk rewrites the references found in x, which is a schema (or a list
+// of schemas) in the document read from file. Only keywords that hold
+// schemas are walked, so that values such as enum, const or default are
+// copied as is, even when they contain a $ref key. prefix is the JSON
+// pointer under which the document is placed in the bundle, and is
+// empty for the root schema.
+func (b *bundler) walk(x interface{}, file, prefix string) error {
+	switch v := x.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			rewritten, err := b.rewrite(ref, file, prefix)
+			if err != nil {
+				return err
+			}
+			v["$ref"] = rewritten
+		}
+
+		for key, val := range v {
+			if _, ok := schemaKeywords[key]; ok {
+				if err := b.walk(val, file, prefix); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if _, ok := schemaMapKeywords[key]; ok {
+				m, _ := val.(map[string]interface{})
+				for _, sub := range m {
+					if err := b.walk(sub, file, prefix); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	case []interface{}:
+		for _, val := range v {
+			if err := b.walk(val, file, prefix); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *bundler) rewrite(ref, file, prefix string) (string, error) {
+	target := ref
+	fragment := ""
+	if i := strings.IndexByte(ref, '#'); i > -1 {
+		target = ref[:i]
+		fragment = ref[i+1:]
+	}
+
+	if target == "" {
+		return "#" + prefix + fragment, nil
+	}
+
+	if strings.Contains(target, "://") {
+		return "", errors.Errorf(`remote reference %s in %s is not supported`, ref, file)
+	}
+
+	path := filepath.Clean(filepath.Join(filepath.Dir(file), filepath.FromSlash(target)))
+	if path == b.root {
+		return "#" + fragment, nil
+	}
+
+	name, err := b.include(path)
+	if err != nil {
+		return "", errors.Wrapf(err, `failed to resolve reference %s in %s`, ref, file)
+	}
+	return "#/definitions/" + escapePointer(name) + fragment, nil
+}
+
+// include adds the schema in file to the definitions, and returns the
+// name of the definition
+func (b *bundler) include(file string) (string, error) {
+	if name, ok := b.names[file]; ok {
+		return name, nil
+	}
+
+	x, err := readRawDocument(file)
+	if err != nil {
+		return "", err
+	}
+
+	base := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	name := base
+	for i := 2; ; i++ {
+		_, exists := b.defs[name]
+		if _, added := b.added[name]; !exists && !added {
+			break
+		}
+		name = base + strconv.Itoa(i)
+	}
+
+	// Register the name before walking the document, so that circular
+	// references between files terminate
+	b.names[file] = name
+	b.added[name] = x
+	if err := b.walk(x, file, "/definitions/"+escapePointer(name)); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func escapePointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
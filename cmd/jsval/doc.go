@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-json-schema/validator"
+)
+
+func runDoc(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("doc", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "markdown", "output format (markdown or html)")
+	title := fs.String("title", "", "title of the document")
+	output := fs.String("o", "", "output file (default: stdout)")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: jsval doc [-format markdown|html] [-title <title>] [-o <file>] <schema>...\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitError
+	}
+
+	g := validator.NewDocGenerator().Title(*title)
+	switch *format {
+	case "markdown":
+	case "html":
+		g.Format(validator.DocHTML)
+	default:
+		fmt.Fprintf(stderr, "jsval: unknown format %q\n", *format)
+		return exitError
+	}
+
+	var validators []*validator.JSVal
+	for _, file := range fs.Args() {
+		v, err := buildValidator(file)
+		if err != nil {
+			fmt.Fprintf(stderr, "jsval: %s\n", err)
+			return exitError
+		}
+		// Sections are named after the file, rather than its path
+		v.SetName(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)))
+		validators = append(validators, v)
+
+		data, err := readDocument(file)
+		if err != nil {
+			fmt.Fprintf(stderr, "jsval: %s\n", err)
+			return exitError
+		}
+		var s map[string]interface{}
+		if err := json.Unmarshal(data, &s); err == nil {
+			g.DescriptionsFromSchema(s)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := g.Process(&buf, validators...); err != nil {
+		fmt.Fprintf(stderr, "jsval: failed to generate documentation: %s\n", err)
+		return exitError
+	}
+
+	if *output == "" {
+		buf.WriteTo(stdout)
+		return exitOK
+	}
+
+	if err := ioutil.WriteFile(*output, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(stderr, "jsval: failed to write %s: %s\n", *output, err)
+		return exitError
+	}
+	return exitOK
+}
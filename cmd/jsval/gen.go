@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+
+	"github.com/go-json-schema/validator"
+)
+
+func runGen(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaFile := fs.String("schema", "", "schema file (JSON or YAML)")
+	pkg := fs.String("pkg", "main", "package name of the generated file")
+	name := fs.String("name", "V", "name of the generated validator variable")
+	prefix := fs.String("prefix", "", "prefix for the names of the generated constraint map and references")
+	output := fs.String("o", "", "output file (default: stdout)")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: jsval gen -schema <file> [-pkg <name>] [-name <name>] [-prefix <prefix>] [-o <file>]\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	if *schemaFile == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return exitError
+	}
+
+	v, err := buildValidator(*schemaFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "jsval: %s\n", err)
+		return exitError
+	}
+	v.SetName(*name)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by jsval gen from %s. DO NOT EDIT.\n\n", *schemaFile)
+	if err := validator.NewGenerator().Package(*pkg).Prefix(*prefix).Process(&buf, v); err != nil {
+		fmt.Fprintf(stderr, "jsval: failed to generate code: %s\n", err)
+		return exitError
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		fmt.Fprintf(stderr, "jsval: failed to format generated code: %s\n", err)
+		return exitError
+	}
+
+	if *output == "" {
+		stdout.Write(src)
+		return exitOK
+	}
+
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintf(stderr, "jsval: failed to write %s: %s\n", *output, err)
+		return exitError
+	}
+	return exitOK
+}
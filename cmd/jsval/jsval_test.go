@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"validate", "-schema", "testdata/person.json", "testdata/valid.json", "testdata/valid.yaml"}, &stdout, &stderr)
+	if !assert.Equal(t, exitOK, code, "exit code is 0 (stderr: %s)", stderr.String()) {
+		return
+	}
+
+	stdout.Reset()
+	code = run([]string{"validate", "-schema", "testdata/person.json", "-format", "json", "testdata/valid.json", "testdata/invalid.json"}, &stdout, &stderr)
+	if !assert.Equal(t, exitInvalid, code, "exit code is 1") {
+		return
+	}
+
+	var results []validateResult
+	if !assert.NoError(t, json.Unmarshal(stdout.Bytes(), &results), "output is JSON") {
+		return
+	}
+	if !assert.Len(t, results, 2, "one result per file") {
+		return
+	}
+	if !assert.True(t, results[0].Valid, "valid.json is valid") {
+		return
+	}
+	if !assert.False(t, results[1].Valid, "invalid.json is invalid") {
+		return
+	}
+	if !assert.Equal(t, "/age", results[1].Pointer, "pointer to the invalid value") {
+		return
+	}
+	if !assert.False(t, strings.Contains(results[1].Error, "/age"), "the message does not repeat the pointer") {
+		return
+	}
+
+	stdout.Reset()
+	code = run([]string{"validate", "-schema", "testdata/person.json", "testdata/invalid.json"}, &stdout, &stderr)
+	if !assert.Equal(t, exitInvalid, code, "exit code is 1") {
+		return
+	}
+	if !assert.Equal(t, 1, strings.Count(stdout.String(), "/age"), "the pointer is printed once: %s", stdout.String()) {
+		return
+	}
+
+	stdout.Reset()
+	code = run([]string{"validate", "-schema", "testdata/person.json", "-explain", "testdata/invalid.json"}, &stdout, &stderr)
+	if !assert.Equal(t, exitInvalid, code, "exit code is 1") {
+		return
+	}
+	if !assert.Contains(t, stdout.String(), "FAIL integer at /age", "trace shows the failing constraint") {
+		return
+	}
+
+	stdout.Reset()
+	code = run([]string{"validate", "-schema", "testdata/person.json", "testdata/valid.toml", "testdata/invalid.yaml"}, &stdout, &stderr)
+	if !assert.Equal(t, exitInvalid, code, "exit code is 1") {
+		return
+	}
+	if !assert.Contains(t, stdout.String(), "testdata/valid.toml: OK", "TOML documents are validated") {
+		return
+	}
+	if !assert.Contains(t, stdout.String(), "testdata/invalid.yaml:3:6: FAIL at /age", "YAML failures are reported by line and column") {
+		return
+	}
+
+	code = run([]string{"validate", "-schema", "testdata/person.json", "testdata/missing.json"}, &stdout, &stderr)
+	if !assert.Equal(t, exitError, code, "exit code is 2") {
+		return
+	}
+}
+
+func TestGen(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"gen", "-schema", "testdata/person.json", "-pkg", "person", "-name", "Person"}, &stdout, &stderr)
+	if !assert.Equal(t, exitOK, code, "exit code is 0 (stderr: %s)", stderr.String()) {
+		return
+	}
+
+	src := stdout.String()
+	for _, s := range []string{"package person\n", "var Person *validator.JSVal"} {
+		if !assert.True(t, strings.Contains(src, s), "generated code contains %q", s) {
+			return
+		}
+	}
+}
+
+func TestDoc(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"doc", "-title", "People", "testdata/person.json"}, &stdout, &stderr)
+	if !assert.Equal(t, exitOK, code, "exit code is 0 (stderr: %s)", stderr.String()) {
+		return
+	}
+
+	doc := stdout.String()
+	for _, s := range []string{"# People\n", "## person\n", "## address\n", "| `name` | string | yes |"} {
+		if !assert.True(t, strings.Contains(doc, s), "documentation contains %q", s) {
+			return
+		}
+	}
+}
+
+func TestBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsval-bundle")
+	if !assert.NoError(t, err, "creating temporary directory should succeed") {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	var stdout, stderr bytes.Buffer
+	out := filepath.Join(dir, "bundled.json")
+	code := run([]string{"bundle", "-o", out, "testdata/order.json"}, &stdout, &stderr)
+	if !assert.Equal(t, exitOK, code, "exit code is 0 (stderr: %s)", stderr.String()) {
+		return
+	}
+
+	var bundled map[string]interface{}
+	data, err := ioutil.ReadFile(out)
+	if !assert.NoError(t, err, "reading bundled schema should succeed") {
+		return
+	}
+	if !assert.NoError(t, json.Unmarshal(data, &bundled), "bundled schema is JSON") {
+		return
+	}
+
+	props := bundled["properties"].(map[string]interface{})
+	if !assert.Equal(t, "#/definitions/person", props["customer"].(map[string]interface{})["$ref"], "reference to file is rewritten") {
+		return
+	}
+	if !assert.Equal(t, "#/definitions/person/definitions/address", props["shipping"].(map[string]interface{})["$ref"], "reference to fragment is rewritten") {
+		return
+	}
+
+	person := bundled["definitions"].(map[string]interface{})["person"].(map[string]interface{})
+	address := person["properties"].(map[string]interface{})["address"].(map[string]interface{})
+	if !assert.Equal(t, "#/definitions/person/definitions/address", address["$ref"], "reference within bundled file is rewritten") {
+		return
+	}
+
+	// The bundled schema can be used on its own
+	code = run([]string{"validate", "-schema", out, "testdata/invalid.json"}, &stdout, &stderr)
+	if !assert.Equal(t, exitInvalid, code, "exit code is 1 (stderr: %s)", stderr.String()) {
+		return
+	}
+}
+
+func TestBundleData(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsval-bundle")
+	if !assert.NoError(t, err, "creating temporary directory should succeed") {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	// None of these values are schemas, so the references in them must
+	// be neither resolved nor rewritten
+	const src = `{
+  "type": "object",
+  "properties": {
+    "link": {
+      "type": "object",
+      "enum": [{"$ref": "missing.json"}],
+      "default": {"$ref": "missing.json"}
+    }
+  }
+}`
+	in := filepath.Join(dir, "schema.json")
+	if !assert.NoError(t, ioutil.WriteFile(in, []byte(src), 0644), "writing schema should succeed") {
+		return
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"bundle", in}, &stdout, &stderr)
+	if !assert.Equal(t, exitOK, code, "exit code is 0 (stderr: %s)", stderr.String()) {
+		return
+	}
+
+	var bundled map[string]interface{}
+	if !assert.NoError(t, json.Unmarshal(stdout.Bytes(), &bundled), "bundled schema is JSON") {
+		return
+	}
+
+	link := bundled["properties"].(map[string]interface{})["link"].(map[string]interface{})
+	if !assert.Equal(t, []interface{}{map[string]interface{}{"$ref": "missing.json"}}, link["enum"], "enum is copied as is") {
+		return
+	}
+	if !assert.Equal(t, map[string]interface{}{"$ref": "missing.json"}, link["default"], "default is copied as is") {
+		return
+	}
+	if !assert.Nil(t, bundled["definitions"], "no definitions are added") {
+		return
+	}
+}
+
+func TestLint(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"lint", "testdata/person.json"}, &stdout, &stderr)
+	if !assert.Equal(t, exitOK, code, "exit code is 0 (output: %s)", stdout.String()) {
+		return
+	}
+
+	stdout.Reset()
+	code = run([]string{"lint", "testdata/contradictory.json"}, &stdout, &stderr)
+	if !assert.Equal(t, exitInvalid, code, "exit code is 1") {
+		return
+	}
+	for _, s := range []string{"/properties/age: error", "/definitions/unused: warning"} {
+		if !assert.True(t, strings.Contains(stdout.String(), s), "output contains %q", s) {
+			return
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-json-schema/validator/lint"
+)
+
+func runLint(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	formats := fs.String("formats", "", "comma separated list of additional known formats")
+	strict := fs.Bool("strict", false, "treat warnings as errors")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: jsval lint [-formats <list>] [-strict] <schema>...\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitError
+	}
+
+	l := lint.New()
+	if *formats != "" {
+		l.KnownFormats(strings.Split(*formats, ",")...)
+	}
+
+	code := exitOK
+	for _, file := range fs.Args() {
+		// A schema that can be parsed, and whose references can all be
+		// resolved, builds into a validator
+		if _, err := buildValidator(file); err != nil {
+			fmt.Fprintf(stdout, "%s: %s\n", file, err)
+			code = exitInvalid
+			continue
+		}
+
+		data, err := readDocument(file)
+		if err != nil {
+			fmt.Fprintf(stderr, "jsval: %s\n", err)
+			return exitError
+		}
+
+		problems, err := l.LintJSON(data)
+		if err != nil {
+			fmt.Fprintf(stdout, "%s: %s\n", file, err)
+			code = exitInvalid
+			continue
+		}
+
+		for _, p := range problems {
+			fmt.Fprintf(stdout, "%s:%s\n", file, p)
+			if p.Severity == lint.Error || *strict {
+				code = exitInvalid
+			}
+		}
+	}
+	return code
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-json-schema/schema"
+	"github.com/go-json-schema/validator"
+	"github.com/go-json-schema/validator/builder"
+	"github.com/go-json-schema/validator/instance"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func isYAML(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+func isTOML(path string) bool {
+	return strings.ToLower(filepath.Ext(path)) == ".toml"
+}
+
+// readDocument reads the JSON or YAML document in path, and returns
+// it as JSON. YAML documents are converted to JSON.
+func readDocument(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, `failed to read %s`, path)
+	}
+
+	if !isYAML(path) {
+		return data, nil
+	}
+
+	var x interface{}
+	if err := yaml.Unmarshal(data, &x); err != nil {
+		return nil, errors.Wrapf(err, `failed to decode YAML from %s`, path)
+	}
+
+	x, err = instance.Normalize(x)
+	if err != nil {
+		return nil, errors.Wrapf(err, `failed to convert YAML from %s`, path)
+	}
+
+	buf, err := json.Marshal(x)
+	if err != nil {
+		return nil, errors.Wrapf(err, `failed to convert YAML from %s`, path)
+	}
+	return buf, nil
+}
+
+// decodeDocument decodes the JSON document in data, preserving the
+// precision of numbers
+func decodeDocument(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var x interface{}
+	if err := dec.Decode(&x); err != nil {
+		return nil, errors.Wrap(err, `failed to decode JSON`)
+	}
+	return x, nil
+}
+
+// loadSchema reads the schema in path, and returns it along with its
+// raw JSON representation
+func loadSchema(path string) (schema.Schema, []byte, error) {
+	data, err := readDocument(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s, err := schema.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, `failed to parse schema %s`, path)
+	}
+	return s, data, nil
+}
+
+// buildValidator builds the validator for the schema in path
+func buildValidator(path string) (*validator.JSVal, error) {
+	s, data, err := loadSchema(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON references are resolved against the raw schema, so that
+	// they may point anywhere in the document
+	var jsctx interface{}
+	if err := json.Unmarshal(data, &jsctx); err != nil {
+		return nil, errors.Wrapf(err, `failed to decode schema %s`, path)
+	}
+
+	v, err := builder.New().BuildWithCtx(s, jsctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, `failed to build validator from %s`, path)
+	}
+	return v.SetName(path), nil
+}
@@ -0,0 +1,74 @@
+// jsval is a command line tool to work with JSON schemas and the
+// validators built from them.
+//
+//	jsval validate -schema schema.json data1.json data2.yaml ...
+//	jsval gen -schema schema.json -pkg mypkg -o validator_gen.go
+//	jsval bundle -o bundled.json schema.json
+//	jsval lint schema.json
+//	jsval doc -format html -o schema.html schema.json
+//
+// The exit code is 0 on success, 1 when validation (or lint) finds
+// problems, and 2 when the tool could not run, for example because
+// a file could not be read.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitError   = 2
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = []command{
+	{"validate", "validate JSON or YAML files against a schema", runValidate},
+	{"gen", "generate Go code that sets up a validator for a schema", runGen},
+	{"bundle", "inline external references into a single schema", runBundle},
+	{"lint", "report problems in a schema", runLint},
+	{"doc", "render documentation for schemas as Markdown or HTML", runDoc},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitError
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return exitOK
+	}
+
+	fmt.Fprintf(stderr, "jsval: unknown command %q\n", args[0])
+	usage(stderr)
+	return exitError
+}
+
+func usage(out io.Writer) {
+	fmt.Fprintf(out, "Usage: jsval <command> [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(out, "\nRun 'jsval <command> -h' for the arguments of each command.\n")
+}
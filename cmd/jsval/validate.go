@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/go-json-schema/validator"
+	"github.com/go-json-schema/validator/instance"
+)
+
+type validateResult struct {
+	File    string `json:"file"`
+	Valid   bool   `json:"valid"`
+	Error   string `json:"error,omitempty"`
+	Pointer string `json:"pointer,omitempty"`
+	Offset  *int64 `json:"offset,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Trace   string `json:"trace,omitempty"`
+}
+
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaFile := fs.String("schema", "", "schema file (JSON or YAML)")
+	format := fs.String("format", "text", "output format (text or json)")
+	explain := fs.Bool("explain", false, "show every constraint evaluated for invalid files")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: jsval validate -schema <file> [-format text|json] [-explain] <file>...\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	if *schemaFile == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return exitError
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "jsval: unknown format %q\n", *format)
+		return exitError
+	}
+
+	v, err := buildValidator(*schemaFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "jsval: %s\n", err)
+		return exitError
+	}
+
+	code := exitOK
+	results := make([]validateResult, 0, fs.NArg())
+	for _, file := range fs.Args() {
+		res, err := validateFile(v, file, *explain)
+		if err != nil {
+			fmt.Fprintf(stderr, "jsval: %s\n", err)
+			return exitError
+		}
+		if !res.Valid {
+			code = exitInvalid
+		}
+		results = append(results, res)
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			fmt.Fprintf(stderr, "jsval: failed to write results: %s\n", err)
+			return exitError
+		}
+		return code
+	}
+
+	for _, res := range results {
+		if res.Valid {
+			fmt.Fprintf(stdout, "%s: OK\n", res.File)
+			continue
+		}
+
+		location := res.File
+		if res.Line > 0 {
+			location = fmt.Sprintf("%s:%d:%d", res.File, res.Line, res.Column)
+		}
+		if res.Pointer != "" {
+			fmt.Fprintf(stdout, "%s: FAIL at %s: %s\n", location, res.Pointer, res.Error)
+		} else {
+			fmt.Fprintf(stdout, "%s: FAIL: %s\n", location, res.Error)
+		}
+		if res.Trace != "" {
+			fmt.Fprint(stdout, res.Trace)
+		}
+	}
+	return code
+}
+
+// validateFile validates the document in file. If explain is true, the
+// trace of the validation run is recorded for invalid documents. The
+// returned error is only non-nil if the file could not be read.
+func validateFile(v *validator.JSVal, file string, explain bool) (validateResult, error) {
+	res := validateResult{File: file}
+
+	var trace validator.Trace
+	ctx := context.Background()
+	if explain {
+		ctx = validator.WithTrace(ctx, &trace)
+	}
+
+	var err error
+	if isYAML(file) || isTOML(file) {
+		// Keep track of positions, so that failures can be reported
+		// by line and column
+		doc, rerr := instance.ReadFile(file)
+		if rerr != nil {
+			return res, rerr
+		}
+		err = doc.ValidateContext(ctx, v)
+		if ierr, ok := err.(*instance.Error); ok {
+			res.Line, res.Column = ierr.Position.Line, ierr.Position.Column
+			err = ierr.Err
+		}
+	} else {
+		data, rerr := readDocument(file)
+		if rerr != nil {
+			return res, rerr
+		}
+		err = v.ValidateReaderContext(ctx, bytes.NewReader(data))
+	}
+	if err == nil {
+		res.Valid = true
+		return res, nil
+	}
+
+	res.Error = err.Error()
+	if explain {
+		res.Trace = trace.String()
+	}
+	if verr, ok := validator.AsValidationError(err); ok {
+		// The location is reported separately, so it is left out of
+		// the message
+		res.Error = verr.Err.Error()
+		res.Pointer = verr.Pointer
+		if verr.Offset >= 0 {
+			offset := verr.Offset
+			res.Offset = &offset
+		}
+	}
+	return res, nil
+}
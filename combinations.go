@@ -1,7 +1,10 @@
 package validator
 
 import (
-	"errors"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
 
 	"github.com/lestrrat/go-pdebug"
 )
@@ -44,19 +47,37 @@ func (c *AnyConstraint) Add(c2 Constraint) *AnyConstraint {
 
 // Validate validates the value against the input value.
 // For AnyConstraints, it will return success the moment
-// one child Constraint succeeds. It will return an error
-// if none of the child Constraints succeeds
-func (c *AnyConstraint) Validate(v interface{}) (err error) {
+// one child Constraint succeeds. It will return a
+// CombinationError if none of the child Constraints succeeds
+func (c *AnyConstraint) Validate(v interface{}) error {
+	return c.ValidateContext(context.Background(), v)
+}
+
+// ValidateContext is the same as Validate, but honors the given context
+func (c *AnyConstraint) ValidateContext(ctx context.Context, v interface{}) (err error) {
 	if pdebug.Enabled {
 		g := pdebug.Marker("AnyConstraint.Validate").BindError(&err)
 		defer g.End()
 	}
-	for _, celem := range c.constraints {
-		if err := celem.Validate(v); err == nil {
+	var causes []error
+	for i, celem := range c.constraints {
+		err := validateContext(ctx, celem, v)
+		if err == nil {
 			return nil
 		}
+		if isAborted(err) {
+			return err
+		}
+		if causes == nil {
+			causes = make([]error, len(c.constraints))
+		}
+		causes[i] = err
+	}
+	return &CombinationError{
+		Keyword: "anyOf",
+		Causes:  causes,
+		Best:    bestCause(c.constraints, causes, v),
 	}
-	return errors.New("could not validate against any of the constraints")
 }
 
 // All creates a new AllConstraint
@@ -79,14 +100,19 @@ func (c *AllConstraint) Add(c2 Constraint) *AllConstraint {
 // Validate validates the value against the input value.
 // For AllConstraints, it will only return success if
 // all of the child Constraints succeeded.
-func (c *AllConstraint) Validate(v interface{}) (err error) {
+func (c *AllConstraint) Validate(v interface{}) error {
+	return c.ValidateContext(context.Background(), v)
+}
+
+// ValidateContext is the same as Validate, but honors the given context
+func (c *AllConstraint) ValidateContext(ctx context.Context, v interface{}) (err error) {
 	if pdebug.Enabled {
 		g := pdebug.Marker("AllConstraint.Validate").BindError(&err)
 		defer g.End()
 	}
 
 	for _, celem := range c.constraints {
-		if err := celem.Validate(v); err != nil {
+		if err := validateContext(ctx, celem, v); err != nil {
 			return err
 		}
 	}
@@ -112,24 +138,149 @@ func (c *OneOfConstraint) Add(c2 Constraint) *OneOfConstraint {
 
 // Validate validates the value against the input value.
 // For OneOfConstraints, it will return success only if
-// exactly 1 child Constraint succeeds.
-func (c *OneOfConstraint) Validate(v interface{}) (err error) {
+// exactly 1 child Constraint succeeds. Otherwise it returns
+// a CombinationError.
+func (c *OneOfConstraint) Validate(v interface{}) error {
+	return c.ValidateContext(context.Background(), v)
+}
+
+// ValidateContext is the same as Validate, but honors the given context
+func (c *OneOfConstraint) ValidateContext(ctx context.Context, v interface{}) (err error) {
 	if pdebug.Enabled {
 		g := pdebug.Marker("OneOfConstraint.Validate").BindError(&err)
 		defer g.End()
 	}
 
-	count := 0
-	for _, celem := range c.constraints {
-		if err := celem.Validate(v); err == nil {
-			count++
+	var causes []error
+	var matched []int
+	for i, celem := range c.constraints {
+		err := validateContext(ctx, celem, v)
+		if err == nil {
+			matched = append(matched, i)
+			continue
+		}
+		if isAborted(err) {
+			return err
+		}
+		if causes == nil {
+			causes = make([]error, len(c.constraints))
+		}
+		causes[i] = err
+	}
+
+	switch len(matched) {
+	case 0:
+		return &CombinationError{
+			Keyword: "oneOf",
+			Causes:  causes,
+			Best:    bestCause(c.constraints, causes, v),
+		}
+	case 1:
+		return nil // Yes!
+	default:
+		return &CombinationError{
+			Keyword: "oneOf",
+			Causes:  causes,
+			Best:    -1,
+			Matched: matched,
 		}
 	}
+}
 
-	if count == 0 {
-		return errors.New("none of the constraints passed")
-	} else if count > 1 {
-		return errors.New("more than 1 of the constraints passed")
+// bestCause picks the error in causes that is the most relevant to
+// report, and returns its index. Branches that accept the type of v
+// are preferred over those that do not, and among those, the branch
+// that failed deepest into v wins, as it got the furthest in
+// describing it. Ties are resolved in favor of the earlier branch.
+func bestCause(l []Constraint, causes []error, v interface{}) int {
+	best := -1
+	var bestTyped bool
+	var bestDepth int
+	for i, err := range causes {
+		if err == nil {
+			continue
+		}
+
+		typed := typeMatches(l[i], v, 0)
+		var depth int
+		if verr, ok := AsValidationError(err); ok {
+			depth = strings.Count(verr.Pointer, "/")
+		}
+
+		if best == -1 || (typed && !bestTyped) || (typed == bestTyped && depth > bestDepth) {
+			best, bestTyped, bestDepth = i, typed, depth
+		}
+	}
+	return best
+}
+
+// maxTypeMatchDepth limits how many references and combinations
+// typeMatches follows, so that recursive schemas terminate
+const maxTypeMatchDepth = 8
+
+// typeMatches returns true if the type of v is one that c may accept.
+// Constraints that do not restrict the type, and those that can not be
+// inspected, are assumed to match
+func typeMatches(c Constraint, v interface{}, depth int) bool {
+	if depth > maxTypeMatchDepth {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		rv = rv.Elem()
+	}
+
+	switch c := c.(type) {
+	case nullConstraint:
+		return v == nil
+	case *BooleanConstraint:
+		return rv.Kind() == reflect.Bool
+	case *StringConstraint:
+		_, isNumber := v.(json.Number)
+		return rv.Kind() == reflect.String && !isNumber
+	case *NumberConstraint, *IntegerConstraint:
+		if _, ok := v.(json.Number); ok {
+			return true
+		}
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			return true
+		}
+		return false
+	case *ArrayConstraint:
+		return rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array
+	case *ObjectConstraint, *DiscriminatorConstraint:
+		return rv.Kind() == reflect.Map || rv.Kind() == reflect.Struct
+	case *ReferenceConstraint:
+		resolved, err := c.Resolved()
+		if err != nil {
+			return true
+		}
+		return typeMatches(resolved, v, depth+1)
+	case *AllConstraint:
+		for _, child := range c.constraints {
+			if !typeMatches(child, v, depth+1) {
+				return false
+			}
+		}
+		return true
+	case *AnyConstraint:
+		return anyTypeMatches(c.constraints, v, depth+1)
+	case *OneOfConstraint:
+		return anyTypeMatches(c.constraints, v, depth+1)
+	}
+	return true
+}
+
+func anyTypeMatches(l []Constraint, v interface{}, depth int) bool {
+	for _, child := range l {
+		if typeMatches(child, v, depth) {
+			return true
+		}
 	}
-	return nil // Yes!
+	return len(l) == 0
 }
@@ -0,0 +1,95 @@
+package validator_test
+
+import (
+	"testing"
+
+	"github.com/go-json-schema/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func combinationError(t *testing.T, err error) *validator.CombinationError {
+	verr, ok := validator.AsValidationError(err)
+	if !assert.True(t, ok, "error carries a ValidationError (got %v)", err) {
+		return nil
+	}
+	cerr, ok := verr.Err.(*validator.CombinationError)
+	if !assert.True(t, ok, "error is a CombinationError (got %T)", verr.Err) {
+		return nil
+	}
+	return cerr
+}
+
+func TestAnyBestCause(t *testing.T) {
+	v := validator.New().SetRoot(validator.Any().
+		Add(validator.String()).
+		Add(validator.Object().
+			AddProp("name", validator.String().MinLength(3)).
+			AdditionalProperties(validator.EmptyConstraint)))
+
+	cerr := combinationError(t, v.Validate(map[string]interface{}{"name": "x"}))
+	if cerr == nil {
+		return
+	}
+	if !assert.Equal(t, "anyOf", cerr.Keyword, "keyword matches") {
+		return
+	}
+	if !assert.Len(t, cerr.Causes, 2, "errors of all branches are kept") {
+		return
+	}
+	if !assert.Equal(t, 1, cerr.Best, "the branch whose type matched is the best match") {
+		return
+	}
+	best, ok := validator.AsValidationError(cerr.BestCause())
+	if !assert.True(t, ok, "best cause is a ValidationError") {
+		return
+	}
+	if !assert.Equal(t, "/name", best.Pointer, "best cause points to the property") {
+		return
+	}
+	if !assert.Contains(t, cerr.Error(), "closest match is branch 1: string shorter than minLength 3 (at '/name')", "message includes the best cause") {
+		return
+	}
+}
+
+func TestOneOfBestCause(t *testing.T) {
+	v := validator.New().SetRoot(validator.OneOf().
+		Add(validator.Object().
+			AddProp("url", validator.String()).
+			Required("url").
+			AdditionalProperties(validator.EmptyConstraint)).
+		Add(validator.Object().
+			AddProp("id", validator.Integer().Minimum(1)).
+			AdditionalProperties(validator.EmptyConstraint)))
+
+	cerr := combinationError(t, v.Validate(map[string]interface{}{"id": float64(0)}))
+	if cerr == nil {
+		return
+	}
+	if !assert.Equal(t, 1, cerr.Best, "the branch that failed deepest is the best match") {
+		return
+	}
+	if !assert.Error(t, cerr.Causes[0], "errors of other branches are kept") {
+		return
+	}
+}
+
+func TestOneOfMultipleMatches(t *testing.T) {
+	v := validator.New().SetRoot(validator.OneOf().
+		Add(validator.String()).
+		Add(validator.Integer()).
+		Add(validator.String().MinLength(1)))
+
+	cerr := combinationError(t, v.Validate("foo"))
+	if cerr == nil {
+		return
+	}
+	if !assert.Equal(t, []int{0, 2}, cerr.Matched, "matched branches are reported") {
+		return
+	}
+	if !assert.Equal(t, -1, cerr.Best, "there is no best cause") {
+		return
+	}
+	if !assert.Equal(t, "more than 1 of the constraints passed (branches 0, 2)", cerr.Error(), "message lists the matched branches") {
+		return
+	}
+}
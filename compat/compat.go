@@ -0,0 +1,717 @@
+// Package compat compares two versions of a validator, and reports
+// whether the changes between them are compatible.
+//
+// Compatibility is expressed in terms of the data that each version
+// accepts. A change is backward compatible if everything that was
+// valid under the old version is still valid under the new one (for
+// example, a maximum was raised), and forward compatible if
+// everything that is valid under the new version was also valid under
+// the old one (for example, a property became required). A change that
+// is neither is breaking.
+//
+// Which direction matters depends on who upgrades first: if consumers
+// of a topic must keep accepting events that were produced before the
+// change, require backward compatibility. If existing consumers must
+// accept the events produced after the change, require forward
+// compatibility.
+package compat
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-json-schema/validator"
+	"github.com/lestrrat/go-pdebug"
+	"github.com/pkg/errors"
+)
+
+// Compatibility classifies a change
+type Compatibility int
+
+const (
+	// Identical means that both versions accept the same data
+	Identical Compatibility = iota
+	// Backward means that the new version accepts everything the old
+	// version did (and possibly more)
+	Backward
+	// Forward means that the old version accepts everything the new
+	// version does (that is, the new version is more restrictive)
+	Forward
+	// Breaking means that neither version accepts everything the
+	// other one does
+	Breaking
+)
+
+func (c Compatibility) String() string {
+	switch c {
+	case Identical:
+		return "identical"
+	case Backward:
+		return "backward compatible"
+	case Forward:
+		return "forward compatible"
+	case Breaking:
+		return "breaking"
+	}
+	return "Compatibility(" + strconv.Itoa(int(c)) + ")"
+}
+
+// combine returns the compatibility of two changes applied together
+func combine(a, b Compatibility) Compatibility {
+	switch {
+	case a == b:
+		return a
+	case a == Identical:
+		return b
+	case b == Identical:
+		return a
+	}
+	return Breaking
+}
+
+// invert swaps the roles of the old and the new version
+func invert(c Compatibility) Compatibility {
+	switch c {
+	case Backward:
+		return Forward
+	case Forward:
+		return Backward
+	}
+	return c
+}
+
+// Change describes a single difference between the two versions
+type Change struct {
+	// Path is the JSON pointer to the value in the validated data that
+	// the change applies to. Array items are denoted by "*".
+	Path string
+	// Keyword is the JSON schema keyword that changed
+	Keyword string
+	// Compatibility classifies the change
+	Compatibility Compatibility
+	// Message describes the change
+	Message string
+}
+
+func (c Change) String() string {
+	path := c.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s: %s (%s)", path, c.Keyword, c.Message, c.Compatibility)
+}
+
+// Report is the result of comparing two validators
+type Report struct {
+	Changes []Change
+}
+
+// Compatibility returns the compatibility of all of the changes
+// combined
+func (r *Report) Compatibility() Compatibility {
+	result := Identical
+	for _, c := range r.Changes {
+		result = combine(result, c.Compatibility)
+	}
+	return result
+}
+
+// IsBackwardCompatible returns true if all data that was valid under
+// the old version is valid under the new one
+func (r *Report) IsBackwardCompatible() bool {
+	c := r.Compatibility()
+	return c == Identical || c == Backward
+}
+
+// IsForwardCompatible returns true if all data that is valid under the
+// new version was valid under the old one
+func (r *Report) IsForwardCompatible() bool {
+	c := r.Compatibility()
+	return c == Identical || c == Forward
+}
+
+// Compare compares the old and the new version of a validator
+func Compare(old, new *validator.JSVal) (report *Report, err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("compat.Compare").BindError(&err)
+		defer g.End()
+	}
+
+	if old == nil || new == nil {
+		return nil, errors.New("validators must not be nil")
+	}
+
+	ctx := &comparectx{seen: make(map[[2]validator.Constraint]struct{})}
+	if err := ctx.compare("", old.Root(), new.Root()); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(ctx.changes, func(i, j int) bool {
+		return ctx.changes[i].Path < ctx.changes[j].Path
+	})
+	return &Report{Changes: ctx.changes}, nil
+}
+
+type comparectx struct {
+	seen    map[[2]validator.Constraint]struct{}
+	changes []Change
+}
+
+func (ctx *comparectx) report(path, keyword string, c Compatibility, format string, args ...interface{}) {
+	if c == Identical {
+		return
+	}
+	ctx.changes = append(ctx.changes, Change{
+		Path:          path,
+		Keyword:       keyword,
+		Compatibility: c,
+		Message:       fmt.Sprintf(format, args...),
+	})
+}
+
+// resolve follows references until it reaches an actual constraint
+func resolve(c validator.Constraint) (validator.Constraint, error) {
+	for i := 0; ; i++ {
+		rc, ok := c.(*validator.ReferenceConstraint)
+		if !ok {
+			return c, nil
+		}
+		if i > 100 {
+			return nil, errors.Errorf(`too many levels of references (%s)`, rc.GetRefersTo())
+		}
+
+		resolved, err := rc.Resolved()
+		if err != nil {
+			return nil, errors.Wrapf(err, `failed to resolve reference %s`, rc.GetRefersTo())
+		}
+		c = resolved
+	}
+}
+
+func isEmpty(c validator.Constraint) bool {
+	return c == validator.EmptyConstraint
+}
+
+func (ctx *comparectx) compare(path string, old, new validator.Constraint) error {
+	var err error
+	if old, err = resolve(old); err != nil {
+		return err
+	}
+	if new, err = resolve(new); err != nil {
+		return err
+	}
+
+	// Recursive schemas lead back to a pair of constraints that is
+	// still being compared. Pairs are forgotten once compared, so that
+	// constraints shared by several paths are reported at each of them
+	key := [2]validator.Constraint{old, new}
+	if isComparable(old) && isComparable(new) {
+		if _, ok := ctx.seen[key]; ok {
+			return nil
+		}
+		ctx.seen[key] = struct{}{}
+		defer delete(ctx.seen, key)
+	}
+
+	switch {
+	case old == nil && new == nil:
+		return nil
+	case old == nil:
+		ctx.report(path, "schema", Backward, "values are now allowed")
+		return nil
+	case new == nil:
+		ctx.report(path, "schema", Forward, "values are no longer allowed")
+		return nil
+	case isEmpty(old) && isEmpty(new):
+		return nil
+	case isEmpty(old):
+		ctx.report(path, "type", Forward, "any value was allowed, now only %s", describe(new))
+		return nil
+	case isEmpty(new):
+		ctx.report(path, "type", Backward, "only %s was allowed, now any value is", describe(old))
+		return nil
+	}
+
+	switch o := old.(type) {
+	case *validator.StringConstraint:
+		if n, ok := new.(*validator.StringConstraint); ok {
+			ctx.compareString(path, o, n)
+			return nil
+		}
+	case *validator.IntegerConstraint:
+		switch n := new.(type) {
+		case *validator.IntegerConstraint:
+			ctx.compareNumber(path, &o.NumberConstraint, &n.NumberConstraint)
+			return nil
+		case *validator.NumberConstraint:
+			ctx.report(path, "type", Backward, "type changed from integer to number")
+			ctx.compareNumber(path, &o.NumberConstraint, n)
+			return nil
+		}
+	case *validator.NumberConstraint:
+		switch n := new.(type) {
+		case *validator.NumberConstraint:
+			ctx.compareNumber(path, o, n)
+			return nil
+		case *validator.IntegerConstraint:
+			ctx.report(path, "type", Forward, "type changed from number to integer")
+			ctx.compareNumber(path, o, &n.NumberConstraint)
+			return nil
+		}
+	case *validator.BooleanConstraint:
+		if _, ok := new.(*validator.BooleanConstraint); ok {
+			return nil
+		}
+	case *validator.ArrayConstraint:
+		if n, ok := new.(*validator.ArrayConstraint); ok {
+			return ctx.compareArray(path, o, n)
+		}
+	case *validator.ObjectConstraint:
+		if n, ok := new.(*validator.ObjectConstraint); ok {
+			return ctx.compareObject(path, o, n)
+		}
+	case *validator.AnyConstraint:
+		if n, ok := new.(*validator.AnyConstraint); ok {
+			return ctx.compareCombination(path, "anyOf", o.Constraints(), n.Constraints(), Backward)
+		}
+	case *validator.AllConstraint:
+		if n, ok := new.(*validator.AllConstraint); ok {
+			return ctx.compareCombination(path, "allOf", o.Constraints(), n.Constraints(), Forward)
+		}
+	case *validator.OneOfConstraint:
+		if n, ok := new.(*validator.OneOfConstraint); ok {
+			return ctx.compareCombination(path, "oneOf", o.Constraints(), n.Constraints(), Breaking)
+		}
+	case *validator.DiscriminatorConstraint:
+		if n, ok := new.(*validator.DiscriminatorConstraint); ok {
+			return ctx.compareDiscriminator(path, o, n)
+		}
+	case *validator.NotConstraint:
+		if n, ok := new.(*validator.NotConstraint); ok {
+			// Allowing more in the child means allowing less overall
+			sub := &comparectx{seen: ctx.seen}
+			if err := sub.compare(path, o.GetChild(), n.GetChild()); err != nil {
+				return err
+			}
+			for _, c := range sub.changes {
+				c.Keyword = "not/" + c.Keyword
+				c.Compatibility = invert(c.Compatibility)
+				ctx.changes = append(ctx.changes, c)
+			}
+			return nil
+		}
+	default:
+		if reflect.TypeOf(old) == reflect.TypeOf(new) {
+			return nil
+		}
+	}
+
+	ctx.report(path, "type", Breaking, "%s changed to %s", describe(old), describe(new))
+	return nil
+}
+
+// isComparable returns true if c can be used as a map key
+func isComparable(c validator.Constraint) bool {
+	return c == nil || reflect.TypeOf(c).Comparable()
+}
+
+func describe(c validator.Constraint) string {
+	switch c.(type) {
+	case *validator.StringConstraint:
+		return "string"
+	case *validator.IntegerConstraint:
+		return "integer"
+	case *validator.NumberConstraint:
+		return "number"
+	case *validator.BooleanConstraint:
+		return "boolean"
+	case *validator.ArrayConstraint:
+		return "array"
+	case *validator.ObjectConstraint:
+		return "object"
+	case *validator.AnyConstraint:
+		return "anyOf"
+	case *validator.AllConstraint:
+		return "allOf"
+	case *validator.OneOfConstraint:
+		return "oneOf"
+	case *validator.NotConstraint:
+		return "not"
+	case *validator.DiscriminatorConstraint:
+		return "discriminator"
+	}
+	if c == validator.NullConstraint {
+		return "null"
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", c), "*")
+}
+
+// compareMin compares lower limits, where -1 means unspecified
+func compareMin(old, new int64) Compatibility {
+	switch {
+	case old == new:
+		return Identical
+	case old < 0:
+		return Forward
+	case new < 0, new < old:
+		return Backward
+	}
+	return Forward
+}
+
+// compareMax compares upper limits, where -1 means unspecified
+func compareMax(old, new int64) Compatibility {
+	switch {
+	case old == new:
+		return Identical
+	case old < 0:
+		return Forward
+	case new < 0, new > old:
+		return Backward
+	}
+	return Forward
+}
+
+func (ctx *comparectx) compareLimits(path, keyword string, c Compatibility, old, new int64) {
+	format := func(v int64) string {
+		if v < 0 {
+			return "unspecified"
+		}
+		return strconv.FormatInt(v, 10)
+	}
+	ctx.report(path, keyword, c, "%s changed from %s to %s", keyword, format(old), format(new))
+}
+
+func (ctx *comparectx) compareString(path string, old, new *validator.StringConstraint) {
+	ctx.compareLimits(path, "minLength", compareMin(old.GetMinLength(), new.GetMinLength()), old.GetMinLength(), new.GetMinLength())
+	ctx.compareLimits(path, "maxLength", compareMax(old.GetMaxLength(), new.GetMaxLength()), old.GetMaxLength(), new.GetMaxLength())
+
+	orx, nrx := old.GetRegexp(), new.GetRegexp()
+	switch {
+	case orx == nil && nrx != nil:
+		ctx.report(path, "pattern", Forward, "pattern %s was added", nrx)
+	case orx != nil && nrx == nil:
+		ctx.report(path, "pattern", Backward, "pattern %s was removed", orx)
+	case orx != nil && nrx != nil && orx.String() != nrx.String():
+		ctx.report(path, "pattern", Breaking, "pattern changed from %s to %s", orx, nrx)
+	}
+
+	of, nf := old.GetFormat(), new.GetFormat()
+	switch {
+	case of == nf:
+	case of == "":
+		ctx.report(path, "format", Forward, "format %s was added", nf)
+	case nf == "":
+		ctx.report(path, "format", Backward, "format %s was removed", of)
+	default:
+		ctx.report(path, "format", Breaking, "format changed from %s to %s", of, nf)
+	}
+
+	ctx.compareEnum(path, old.GetEnum(), new.GetEnum())
+}
+
+// compareNumericLimit compares two limits on numbers. relaxed reports
+// if the limit a is more permissive than the limit b.
+func compareNumericLimit(oldSet, newSet bool, oldValue, newValue float64, oldExclusive, newExclusive bool, relaxed func(a, b float64) bool) Compatibility {
+	switch {
+	case !oldSet && !newSet:
+		return Identical
+	case !oldSet:
+		return Forward
+	case !newSet:
+		return Backward
+	case oldValue == newValue:
+		switch {
+		case oldExclusive == newExclusive:
+			return Identical
+		case oldExclusive:
+			return Backward
+		}
+		return Forward
+	case relaxed(newValue, oldValue):
+		return Backward
+	}
+	return Forward
+}
+
+func (ctx *comparectx) compareNumber(path string, old, new *validator.NumberConstraint) {
+	c := compareNumericLimit(old.HasMinimum(), new.HasMinimum(), old.GetMinimum(), new.GetMinimum(), old.IsExclusiveMinimum(), new.IsExclusiveMinimum(), func(a, b float64) bool { return a < b })
+	ctx.report(path, "minimum", c, "minimum changed from %s to %s", formatLimit(old.HasMinimum(), old.GetMinimum(), old.IsExclusiveMinimum()), formatLimit(new.HasMinimum(), new.GetMinimum(), new.IsExclusiveMinimum()))
+
+	c = compareNumericLimit(old.HasMaximum(), new.HasMaximum(), old.GetMaximum(), new.GetMaximum(), old.IsExclusiveMaximum(), new.IsExclusiveMaximum(), func(a, b float64) bool { return a > b })
+	ctx.report(path, "maximum", c, "maximum changed from %s to %s", formatLimit(old.HasMaximum(), old.GetMaximum(), old.IsExclusiveMaximum()), formatLimit(new.HasMaximum(), new.GetMaximum(), new.IsExclusiveMaximum()))
+
+	switch {
+	case !old.HasMultipleOf() && !new.HasMultipleOf():
+	case !old.HasMultipleOf():
+		ctx.report(path, "multipleOf", Forward, "multipleOf %v was added", new.GetMultipleOf())
+	case !new.HasMultipleOf():
+		ctx.report(path, "multipleOf", Backward, "multipleOf %v was removed", old.GetMultipleOf())
+	case old.GetMultipleOf() == new.GetMultipleOf():
+	case isMultiple(new.GetMultipleOf(), old.GetMultipleOf()):
+		ctx.report(path, "multipleOf", Forward, "multipleOf changed from %v to %v", old.GetMultipleOf(), new.GetMultipleOf())
+	case isMultiple(old.GetMultipleOf(), new.GetMultipleOf()):
+		ctx.report(path, "multipleOf", Backward, "multipleOf changed from %v to %v", old.GetMultipleOf(), new.GetMultipleOf())
+	default:
+		ctx.report(path, "multipleOf", Breaking, "multipleOf changed from %v to %v", old.GetMultipleOf(), new.GetMultipleOf())
+	}
+
+	ctx.compareEnum(path, old.GetEnum(), new.GetEnum())
+}
+
+func formatLimit(set bool, v float64, exclusive bool) string {
+	switch {
+	case !set:
+		return "unspecified"
+	case exclusive:
+		return fmt.Sprintf("%v (exclusive)", v)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// isMultiple reports if a is a multiple of b
+func isMultiple(a, b float64) bool {
+	q := a / b
+	return math.Abs(q-math.Floor(q+0.5)) < 1e-9
+}
+
+func (ctx *comparectx) compareEnum(path string, old, new []interface{}) {
+	switch {
+	case old == nil && new == nil:
+		return
+	case old == nil:
+		ctx.report(path, "enum", Forward, "enum was added")
+		return
+	case new == nil:
+		ctx.report(path, "enum", Backward, "enum was removed")
+		return
+	}
+
+	for _, v := range old {
+		if !containsValue(new, v) {
+			ctx.report(path, "enum", Forward, "enum value %v was removed", v)
+		}
+	}
+	for _, v := range new {
+		if !containsValue(old, v) {
+			ctx.report(path, "enum", Backward, "enum value %v was added", v)
+		}
+	}
+}
+
+func containsValue(l []interface{}, v interface{}) bool {
+	for _, e := range l {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ctx *comparectx) compareArray(path string, old, new *validator.ArrayConstraint) error {
+	ctx.compareLimits(path, "minItems", compareMin(int64(old.GetMinItems()), int64(new.GetMinItems())), int64(old.GetMinItems()), int64(new.GetMinItems()))
+	ctx.compareLimits(path, "maxItems", compareMax(int64(old.GetMaxItems()), int64(new.GetMaxItems())), int64(old.GetMaxItems()), int64(new.GetMaxItems()))
+
+	switch {
+	case !old.GetUniqueItems() && new.GetUniqueItems():
+		ctx.report(path, "uniqueItems", Forward, "items must now be unique")
+	case old.GetUniqueItems() && !new.GetUniqueItems():
+		ctx.report(path, "uniqueItems", Backward, "items no longer need to be unique")
+	}
+
+	oitems, nitems := old.GetItems(), new.GetItems()
+	if oitems != nil || nitems != nil {
+		if err := ctx.compare(path+"/*", orEmpty(oitems), orEmpty(nitems)); err != nil {
+			return err
+		}
+	}
+
+	opos, npos := old.GetPositionalItems(), new.GetPositionalItems()
+	for i := 0; i < len(opos) || i < len(npos); i++ {
+		// Positions beyond the list of positional items are validated
+		// against additionalItems
+		oc, nc := old.GetAdditionalItems(), new.GetAdditionalItems()
+		if i < len(opos) {
+			oc = opos[i]
+		}
+		if i < len(npos) {
+			nc = npos[i]
+		}
+		if err := ctx.compare(path+"/"+strconv.Itoa(i), oc, nc); err != nil {
+			return err
+		}
+	}
+
+	if len(opos) > 0 || len(npos) > 0 {
+		return ctx.compare(path+"/*", old.GetAdditionalItems(), new.GetAdditionalItems())
+	}
+	return nil
+}
+
+func orEmpty(c validator.Constraint) validator.Constraint {
+	if c == nil {
+		return validator.EmptyConstraint
+	}
+	return c
+}
+
+func (ctx *comparectx) compareObject(path string, old, new *validator.ObjectConstraint) error {
+	ctx.compareLimits(path, "minProperties", compareMin(old.GetMinProperties(), new.GetMinProperties()), old.GetMinProperties(), new.GetMinProperties())
+	ctx.compareLimits(path, "maxProperties", compareMax(old.GetMaxProperties(), new.GetMaxProperties()), old.GetMaxProperties(), new.GetMaxProperties())
+
+	oreq, nreq := toSet(old.GetRequired()), toSet(new.GetRequired())
+	for _, pname := range new.GetRequired() {
+		if _, ok := oreq[pname]; !ok {
+			ctx.report(path+"/"+escapePointer(pname), "required", Forward, "property %s is now required", pname)
+		}
+	}
+	for _, pname := range old.GetRequired() {
+		if _, ok := nreq[pname]; !ok {
+			ctx.report(path+"/"+escapePointer(pname), "required", Backward, "property %s is no longer required", pname)
+		}
+	}
+
+	// A property that is only declared in one of the versions is
+	// validated against additionalProperties in the other one
+	names := toSet(old.GetPropNames())
+	for _, pname := range new.GetPropNames() {
+		names[pname] = struct{}{}
+	}
+	for _, pname := range sortedKeys(names) {
+		oc, nc := old.GetProp(pname), new.GetProp(pname)
+		if oc == nil {
+			oc = old.GetAdditionalProperties()
+		}
+		if nc == nil {
+			nc = new.GetAdditionalProperties()
+		}
+		if err := ctx.compare(path+"/"+escapePointer(pname), oc, nc); err != nil {
+			return err
+		}
+	}
+
+	oap, nap := old.GetAdditionalProperties(), new.GetAdditionalProperties()
+	switch {
+	case oap != nil && nap == nil:
+		ctx.report(path, "additionalProperties", Forward, "additional properties are no longer allowed")
+	case oap == nil && nap != nil:
+		ctx.report(path, "additionalProperties", Backward, "additional properties are now allowed")
+	case oap != nil && nap != nil:
+		if err := ctx.compare(path+"/*", oap, nap); err != nil {
+			return err
+		}
+	}
+
+	opp, npp := patternMap(old), patternMap(new)
+	for _, pat := range sortedKeys(toSet(append(keys(opp), keys(npp)...))) {
+		oc, ook := opp[pat]
+		nc, nok := npp[pat]
+		switch {
+		case !ook:
+			ctx.report(path, "patternProperties", Forward, "pattern %s was added", pat)
+		case !nok:
+			ctx.report(path, "patternProperties", Backward, "pattern %s was removed", pat)
+		default:
+			if err := ctx.compare(path+"/"+escapePointer(pat), oc, nc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func patternMap(o *validator.ObjectConstraint) map[string]validator.Constraint {
+	m := make(map[string]validator.Constraint)
+	for rx, c := range o.GetPatternProperties() {
+		m[rx.String()] = c
+	}
+	return m
+}
+
+func keys(m map[string]validator.Constraint) []string {
+	l := make([]string, 0, len(m))
+	for k := range m {
+		l = append(l, k)
+	}
+	return l
+}
+
+// compareCombination compares the branches of anyOf, allOf and oneOf.
+// Branches are compared by position. added is the compatibility of
+// adding a branch.
+func (ctx *comparectx) compareCombination(path, keyword string, old, new []validator.Constraint, added Compatibility) error {
+	sub := &comparectx{seen: ctx.seen}
+	for i := 0; i < len(old) && i < len(new); i++ {
+		if err := sub.compare(path, old[i], new[i]); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range sub.changes {
+		// Relaxing a oneOf branch may cause values to match more than
+		// one branch, which makes them invalid
+		if keyword == "oneOf" && c.Compatibility == Backward {
+			c.Compatibility = Breaking
+		}
+		c.Keyword = keyword + "/" + c.Keyword
+		ctx.changes = append(ctx.changes, c)
+	}
+
+	switch {
+	case len(new) > len(old):
+		ctx.report(path, keyword, added, "%d branches were added", len(new)-len(old))
+	case len(new) < len(old):
+		ctx.report(path, keyword, invert(added), "%d branches were removed", len(old)-len(new))
+	}
+	return nil
+}
+
+func (ctx *comparectx) compareDiscriminator(path string, old, new *validator.DiscriminatorConstraint) error {
+	if old.GetPropertyName() != new.GetPropertyName() {
+		ctx.report(path, "discriminator", Breaking, "discriminator property changed from %s to %s", old.GetPropertyName(), new.GetPropertyName())
+		return nil
+	}
+
+	ovalues := toSet(old.GetMappingValues())
+	nvalues := toSet(new.GetMappingValues())
+	for _, value := range old.GetMappingValues() {
+		if _, ok := nvalues[value]; !ok {
+			ctx.report(path, "discriminator", Forward, "discriminator value %s was removed", value)
+			continue
+		}
+		if err := ctx.compare(path, old.GetMapping(value), new.GetMapping(value)); err != nil {
+			return err
+		}
+	}
+	for _, value := range new.GetMappingValues() {
+		if _, ok := ovalues[value]; !ok {
+			ctx.report(path, "discriminator", Backward, "discriminator value %s was added", value)
+		}
+	}
+	return nil
+}
+
+func toSet(l []string) map[string]struct{} {
+	m := make(map[string]struct{}, len(l))
+	for _, s := range l {
+		m[s] = struct{}{}
+	}
+	return m
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	l := make([]string, 0, len(m))
+	for k := range m {
+		l = append(l, k)
+	}
+	sort.Strings(l)
+	return l
+}
+
+func escapePointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
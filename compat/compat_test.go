@@ -0,0 +1,217 @@
+package compat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-json-schema/schema"
+	"github.com/go-json-schema/schema/draft04"
+	"github.com/go-json-schema/validator"
+	"github.com/go-json-schema/validator/builder"
+	"github.com/stretchr/testify/assert"
+)
+
+func build(t *testing.T, src string) *validator.JSVal {
+	s, err := schema.Parse(strings.NewReader(src), schema.WithSchemaID(draft04.SchemaID))
+	if !assert.NoError(t, err, "schema.Parse should succeed") {
+		return nil
+	}
+
+	v, err := builder.New().Build(s)
+	if !assert.NoError(t, err, "Builder.Build should succeed") {
+		return nil
+	}
+	return v
+}
+
+func TestCompare(t *testing.T) {
+	data := []struct {
+		name     string
+		old      string
+		new      string
+		expected Compatibility
+		paths    []string
+	}{
+		{
+			name:     "no change",
+			old:      `{"type": "object", "properties": {"id": {"type": "integer"}}}`,
+			new:      `{"type": "object", "properties": {"id": {"type": "integer"}}}`,
+			expected: Identical,
+		},
+		{
+			name:     "adding a required property",
+			old:      `{"type": "object", "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}, "required": ["id"]}`,
+			new:      `{"type": "object", "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}, "required": ["id", "name"]}`,
+			expected: Forward,
+			paths:    []string{"/name"},
+		},
+		{
+			name:     "narrowing a numeric range",
+			old:      `{"type": "object", "properties": {"age": {"type": "integer", "minimum": 0, "maximum": 200}}}`,
+			new:      `{"type": "object", "properties": {"age": {"type": "integer", "minimum": 0, "maximum": 150}}}`,
+			expected: Forward,
+			paths:    []string{"/age"},
+		},
+		{
+			name:     "widening a string length",
+			old:      `{"type": "string", "maxLength": 10}`,
+			new:      `{"type": "string", "maxLength": 20}`,
+			expected: Backward,
+			paths:    []string{""},
+		},
+		{
+			name:     "removing an enum value",
+			old:      `{"type": "string", "enum": ["a", "b", "c"]}`,
+			new:      `{"type": "string", "enum": ["a", "b"]}`,
+			expected: Forward,
+			paths:    []string{""},
+		},
+		{
+			name:     "replacing an enum value",
+			old:      `{"type": "string", "enum": ["a", "b"]}`,
+			new:      `{"type": "string", "enum": ["a", "c"]}`,
+			expected: Breaking,
+			paths:    []string{"", ""},
+		},
+		{
+			name:     "closing additionalProperties",
+			old:      `{"type": "object", "properties": {"id": {"type": "integer"}}}`,
+			new:      `{"type": "object", "properties": {"id": {"type": "integer"}}, "additionalProperties": false}`,
+			expected: Forward,
+			paths:    []string{""},
+		},
+		{
+			name:     "changing a type",
+			old:      `{"type": "object", "properties": {"id": {"type": "integer"}}}`,
+			new:      `{"type": "object", "properties": {"id": {"type": "string"}}}`,
+			expected: Breaking,
+			paths:    []string{"/id"},
+		},
+		{
+			name:     "changes in array items",
+			old:      `{"type": "array", "items": {"type": "string", "minLength": 1}}`,
+			new:      `{"type": "array", "items": {"type": "string"}, "uniqueItems": true}`,
+			expected: Breaking,
+			paths:    []string{"", "/*"},
+		},
+		{
+			name: "changes through references",
+			old: `{
+  "type": "object",
+  "properties": {"address": {"$ref": "#/definitions/address"}},
+  "definitions": {"address": {"type": "object", "properties": {"zip": {"type": "string"}}}}
+}`,
+			new: `{
+  "type": "object",
+  "properties": {"address": {"$ref": "#/definitions/address"}},
+  "definitions": {"address": {"type": "object", "properties": {"zip": {"type": "string"}}, "required": ["zip"]}}
+}`,
+			expected: Forward,
+			paths:    []string{"/address/zip"},
+		},
+		{
+			name: "adding a discriminator value",
+			old: `{
+  "oneOf": [{"$ref": "#/definitions/cat"}],
+  "discriminator": {"propertyName": "kind"},
+  "definitions": {"cat": {"type": "object"}, "dog": {"type": "object"}}
+}`,
+			new: `{
+  "oneOf": [{"$ref": "#/definitions/cat"}, {"$ref": "#/definitions/dog"}],
+  "discriminator": {"propertyName": "kind"},
+  "definitions": {"cat": {"type": "object"}, "dog": {"type": "object"}}
+}`,
+			expected: Backward,
+			paths:    []string{""},
+		},
+	}
+
+	for _, d := range data {
+		d := d
+		t.Run(d.name, func(t *testing.T) {
+			old := build(t, d.old)
+			new := build(t, d.new)
+			if old == nil || new == nil {
+				return
+			}
+
+			report, err := Compare(old, new)
+			if !assert.NoError(t, err, "Compare should succeed") {
+				return
+			}
+
+			var paths []string
+			for _, c := range report.Changes {
+				t.Logf("%s", c)
+				paths = append(paths, c.Path)
+			}
+
+			if !assert.Equal(t, d.expected, report.Compatibility(), "compatibility matches") {
+				return
+			}
+			if !assert.Equal(t, d.paths, paths, "paths match") {
+				return
+			}
+		})
+	}
+}
+
+func TestCompareRecursive(t *testing.T) {
+	const src = `{
+  "type": "object",
+  "properties": {
+    "value": {"type": "integer"},
+    "next": {"$ref": "#"}
+  }
+}`
+	old := build(t, src)
+	new := build(t, strings.Replace(src, `"integer"`, `"number"`, 1))
+	if old == nil || new == nil {
+		return
+	}
+
+	report, err := Compare(old, new)
+	if !assert.NoError(t, err, "Compare should succeed") {
+		return
+	}
+	if !assert.True(t, report.IsBackwardCompatible(), "widening integer to number is backward compatible") {
+		return
+	}
+	if !assert.False(t, report.IsForwardCompatible(), "widening integer to number is not forward compatible") {
+		return
+	}
+}
+
+func TestCompareSharedReference(t *testing.T) {
+	const src = `{
+  "type": "object",
+  "properties": {
+    "billing": {"$ref": "#/definitions/address"},
+    "shipping": {"$ref": "#/definitions/address"}
+  },
+  "definitions": {
+    "address": {
+      "type": "object",
+      "properties": {"zip": {"type": "string", "maxLength": 10}}
+    }
+  }
+}`
+	old := build(t, src)
+	new := build(t, strings.Replace(src, `"maxLength": 10`, `"maxLength": 5`, 1))
+	if old == nil || new == nil {
+		return
+	}
+
+	report, err := Compare(old, new)
+	if !assert.NoError(t, err, "Compare should succeed") {
+		return
+	}
+
+	var paths []string
+	for _, c := range report.Changes {
+		paths = append(paths, c.Path)
+	}
+	if !assert.Equal(t, []string{"/billing/zip", "/shipping/zip"}, paths, "change is reported at every path using the definition") {
+		return
+	}
+}
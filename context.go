@@ -0,0 +1,317 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// ErrMaxNodesExceeded is returned when a validation run evaluates more
+// constraints than allowed by WithMaxNodes
+var ErrMaxNodesExceeded = errors.New("maximum number of nodes visited exceeded")
+
+// ErrMaxDepthExceeded is returned when a validation run descends into
+// the input deeper than allowed by WithMaxDepth
+var ErrMaxDepthExceeded = errors.New("maximum depth exceeded")
+
+// ErrDataUnavailable is returned when a $data reference points to a
+// value that the validation run does not have, such as the root of a
+// streamed array, or the parents of the value passed to ValidateAt
+var ErrDataUnavailable = errors.New("$data reference points outside of the validated value")
+
+type budgetKey struct{}
+type frameKey struct{}
+type traceKey struct{}
+type directionKey struct{}
+type partialKey struct{}
+
+// Direction tells whether a value is validated as part of a request or
+// a response. Properties marked readOnly are not expected in requests,
+// and properties marked writeOnly are not expected in responses
+type Direction int
+
+const (
+	// DirectionAny validates values regardless of their direction:
+	// readOnly and writeOnly properties are treated like any other
+	// property. This is the default
+	DirectionAny Direction = iota
+	// DirectionRequest validates values sent by clients. readOnly
+	// properties are rejected, and are not required
+	DirectionRequest
+	// DirectionResponse validates values sent back by servers.
+	// writeOnly properties are rejected, and are not required
+	DirectionResponse
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionRequest:
+		return "request"
+	case DirectionResponse:
+		return "response"
+	default:
+		return "any"
+	}
+}
+
+type budget struct {
+	maxNodes int64
+	maxDepth int
+}
+
+// run holds the state that is shared by all constraints that are
+// evaluated as part of a single validation run.
+type run struct {
+	ctx     context.Context
+	done    <-chan struct{}
+	budget  budget
+	nodes   int64
+	trace   *Trace
+	dir     Direction
+	partial bool
+}
+
+// frame describes the position in the input value that is currently
+// being validated. A new frame is pushed each time validation descends
+// into an array element or an object property.
+//
+// frame is itself a context.Context, so that pushing a new frame only
+// costs a single allocation.
+type frame struct {
+	context.Context
+	run    *run
+	parent *frame
+	seg    string
+	depth  int
+	// value is the value this frame describes, used to resolve $data
+	// references. hasValue is false for frames that were pushed
+	// without a value, such as the ancestors in ValidateAt
+	value    interface{}
+	hasValue bool
+}
+
+func (f *frame) Value(key interface{}) interface{} {
+	if _, ok := key.(frameKey); ok {
+		return f
+	}
+	return f.Context.Value(key)
+}
+
+func budgetFromContext(ctx context.Context) budget {
+	if b, ok := ctx.Value(budgetKey{}).(budget); ok {
+		return b
+	}
+	return budget{}
+}
+
+// WithMaxNodes returns a new context that limits the number of constraint
+// evaluations performed by a single validation run using this context.
+// Every time a constraint is applied to a value, it counts as one visit.
+// Once the limit is reached, validation fails with ErrMaxNodesExceeded.
+// A value of 0 or less means no limit.
+func WithMaxNodes(ctx context.Context, n int64) context.Context {
+	b := budgetFromContext(ctx)
+	b.maxNodes = n
+	return context.WithValue(ctx, budgetKey{}, b)
+}
+
+// WithMaxDepth returns a new context that limits how deep into nested
+// arrays and objects a single validation run using this context may
+// descend. Once the limit is reached, validation fails with
+// ErrMaxDepthExceeded. A value of 0 or less means no limit.
+func WithMaxDepth(ctx context.Context, n int) context.Context {
+	b := budgetFromContext(ctx)
+	b.maxDepth = n
+	return context.WithValue(ctx, budgetKey{}, b)
+}
+
+// WithDirection returns a new context that validates values in the
+// given direction. See Direction
+func WithDirection(ctx context.Context, d Direction) context.Context {
+	return context.WithValue(ctx, directionKey{}, d)
+}
+
+// directionFromContext returns the direction of the validation run
+// ctx belongs to
+func directionFromContext(ctx context.Context) Direction {
+	if f := frameFromContext(ctx); f != nil {
+		return f.run.dir
+	}
+	d, _ := ctx.Value(directionKey{}).(Direction)
+	return d
+}
+
+// WithPartial returns a new context that validates partial values, such
+// as the body of a PATCH request. Objects are validated without
+// checking "required" and "minProperties" at any level, and missing
+// properties are not filled in with their default values. The
+// properties that are present must still be valid.
+//
+// Note that oneOf branches that are told apart only by their required
+// properties may all match a partial value
+func WithPartial(ctx context.Context) context.Context {
+	return context.WithValue(ctx, partialKey{}, true)
+}
+
+// isPartial returns true if the validation run ctx belongs to
+// validates partial values
+func isPartial(ctx context.Context) bool {
+	if f := frameFromContext(ctx); f != nil {
+		return f.run.partial
+	}
+	partial, _ := ctx.Value(partialKey{}).(bool)
+	return partial
+}
+
+func frameFromContext(ctx context.Context) *frame {
+	f, _ := ctx.Value(frameKey{}).(*frame)
+	return f
+}
+
+// startRun attaches the state for a new validation run to the context,
+// unless one has already been started.
+func startRun(ctx context.Context) (context.Context, *frame) {
+	if f := frameFromContext(ctx); f != nil {
+		return ctx, f
+	}
+
+	dir, _ := ctx.Value(directionKey{}).(Direction)
+	partial, _ := ctx.Value(partialKey{}).(bool)
+	f := &frame{
+		Context: ctx,
+		run: &run{
+			ctx:     ctx,
+			done:    ctx.Done(),
+			budget:  budgetFromContext(ctx),
+			trace:   traceFromContext(ctx),
+			dir:     dir,
+			partial: partial,
+		},
+	}
+	return f, f
+}
+
+// visit is called every time a constraint is about to be evaluated.
+// It checks for cancellation and enforces the node budget
+func (f *frame) visit() error {
+	select {
+	case <-f.run.done:
+		return f.run.ctx.Err()
+	default:
+	}
+
+	n := atomic.AddInt64(&f.run.nodes, 1)
+	if max := f.run.budget.maxNodes; max > 0 && n > max {
+		return ErrMaxNodesExceeded
+	}
+	return nil
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// pointer returns the JSON pointer to the value this frame describes
+func (f *frame) pointer() string {
+	var segs []string
+	for cur := f; cur.parent != nil; cur = cur.parent {
+		segs = append(segs, cur.seg)
+	}
+
+	var buf strings.Builder
+	for i := len(segs) - 1; i >= 0; i-- {
+		buf.WriteByte('/')
+		pointerEscaper.WriteString(&buf, segs[i])
+	}
+	return buf.String()
+}
+
+// splitPointer splits a JSON pointer into its unescaped reference
+// tokens
+func splitPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, errors.Errorf("invalid JSON pointer '%s'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// validateContext validates v against c, passing ctx along if c
+// knows how to handle it.
+func validateContext(ctx context.Context, c Constraint, v interface{}) error {
+	ctx, f := startRun(ctx)
+	f.value, f.hasValue = v, true
+	if t := f.run.trace; t != nil {
+		node := t.enter(c, f.pointer())
+		err := evaluate(ctx, f, c, v)
+		t.leave(node, err)
+		return err
+	}
+	return evaluate(ctx, f, c, v)
+}
+
+func evaluate(ctx context.Context, f *frame, c Constraint, v interface{}) error {
+	if err := f.visit(); err != nil {
+		return err
+	}
+
+	var err error
+	if cc, ok := c.(ContextConstraint); ok {
+		err = cc.ValidateContext(ctx, v)
+	} else {
+		err = c.Validate(v)
+	}
+
+	if err == nil || isAborted(err) {
+		return err
+	}
+
+	// Record where the failure happened, unless a constraint further
+	// down the tree already did
+	if _, ok := AsValidationError(err); ok {
+		return err
+	}
+	return &ValidationError{
+		Pointer: f.pointer(),
+		Offset:  -1,
+		Err:     err,
+	}
+}
+
+// validateChild validates v, which is an element of the value currently
+// being validated (e.g. an array item or an object property) and is
+// identified by seg, against c.
+func validateChild(ctx context.Context, seg string, c Constraint, v interface{}) error {
+	ctx, f := startRun(ctx)
+
+	child := &frame{
+		Context: ctx,
+		run:     f.run,
+		parent:  f,
+		seg:     seg,
+		depth:   f.depth + 1,
+	}
+	if max := f.run.budget.maxDepth; max > 0 && child.depth > max {
+		return ErrMaxDepthExceeded
+	}
+
+	return validateContext(child, c, v)
+}
+
+// isAborted returns true if the error signals that the whole validation
+// run must be stopped, as opposed to a regular validation failure.
+// Constraints that recover from failures of their children (e.g.
+// AnyConstraint, NotConstraint) must not swallow these errors.
+func isAborted(err error) bool {
+	switch errors.Cause(err) {
+	case ErrMaxNodesExceeded, ErrMaxDepthExceeded, ErrDataUnavailable, context.Canceled, context.DeadlineExceeded:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package validator_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-json-schema/validator"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateContext(t *testing.T) {
+	v := validator.New().SetRoot(
+		validator.Object().
+			AddProp(`tags`, validator.Array().Items(validator.String())).
+			AddProp(`nested`, validator.Object().
+				AddProp(`deeper`, validator.Array().Items(validator.Integer())),
+			),
+	)
+
+	input := map[string]interface{}{
+		"tags": []interface{}{"foo", "bar", "baz"},
+		"nested": map[string]interface{}{
+			"deeper": []interface{}{1, 2, 3},
+		},
+	}
+
+	t.Run("no limits", func(t *testing.T) {
+		if !assert.NoError(t, v.ValidateContext(context.Background(), input), "validation passes") {
+			return
+		}
+	})
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := v.ValidateContext(ctx, input)
+		if !assert.Error(t, err, "validation fails") {
+			return
+		}
+		if !assert.Equal(t, context.Canceled, errors.Cause(err), "error should be context.Canceled") {
+			return
+		}
+	})
+	t.Run("max nodes", func(t *testing.T) {
+		err := v.ValidateContext(validator.WithMaxNodes(context.Background(), 5), input)
+		if !assert.Error(t, err, "validation fails") {
+			return
+		}
+		if !assert.Equal(t, validator.ErrMaxNodesExceeded, errors.Cause(err), "error should be ErrMaxNodesExceeded") {
+			return
+		}
+
+		if !assert.NoError(t, v.ValidateContext(validator.WithMaxNodes(context.Background(), 100), input), "validation passes") {
+			return
+		}
+	})
+	t.Run("max depth", func(t *testing.T) {
+		err := v.ValidateContext(validator.WithMaxDepth(context.Background(), 2), input)
+		if !assert.Error(t, err, "validation fails") {
+			return
+		}
+		if !assert.Equal(t, validator.ErrMaxDepthExceeded, errors.Cause(err), "error should be ErrMaxDepthExceeded") {
+			return
+		}
+
+		if !assert.NoError(t, v.ValidateContext(validator.WithMaxDepth(context.Background(), 3), input), "validation passes") {
+			return
+		}
+	})
+	t.Run("budget is not swallowed by combinators", func(t *testing.T) {
+		v := validator.New().SetRoot(
+			validator.Not(
+				validator.Any().
+					Add(validator.Array().Items(validator.String())).
+					Add(validator.Array().Items(validator.Integer())),
+			),
+		)
+
+		err := v.ValidateContext(validator.WithMaxNodes(context.Background(), 4), []interface{}{1, 2, 3})
+		if !assert.Error(t, err, "validation fails") {
+			return
+		}
+		if !assert.Equal(t, validator.ErrMaxNodesExceeded, errors.Cause(err), "error should be ErrMaxNodesExceeded") {
+			return
+		}
+	})
+}
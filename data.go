@@ -0,0 +1,385 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"github.com/lestrrat/go-pdebug"
+	"github.com/pkg/errors"
+)
+
+// dataKeywords lists the keywords that accept $data references
+var dataKeywords = map[string]struct{}{
+	"const":            {},
+	"enum":             {},
+	"minimum":          {},
+	"maximum":          {},
+	"exclusiveMinimum": {},
+	"exclusiveMaximum": {},
+	"multipleOf":       {},
+	"minLength":        {},
+	"maxLength":        {},
+	"pattern":          {},
+	"minItems":         {},
+	"maxItems":         {},
+}
+
+// IsDataKeyword returns true if keyword can take its value from a
+// $data reference
+func IsDataKeyword(keyword string) bool {
+	_, ok := dataKeywords[keyword]
+	return ok
+}
+
+// Data creates a new DataConstraint, which applies keyword to the value
+// being validated, using the value found at pointer as the value of the
+// keyword. pointer is either a JSON pointer such as "/start", resolved
+// from the root of the instance, or a relative JSON pointer such as
+// "1/start", resolved from the value being validated.
+//
+// If pointer does not resolve to a value, the keyword is ignored. See
+// IsDataKeyword for the list of supported keywords.
+//
+// The whole instance is only known to JSVal.Validate and the entry
+// points that decode the document before validating it. When a
+// top-level array is streamed by JSVal.ValidateReader, or a fragment
+// is validated by JSVal.ValidateAt, pointers must stay within the
+// element or the fragment: pointers that lead outside of it make the
+// validation fail with ErrDataUnavailable
+func Data(keyword, pointer string) *DataConstraint {
+	return &DataConstraint{
+		keyword: keyword,
+		pointer: pointer,
+	}
+}
+
+// GetKeyword returns the keyword that the constraint applies
+func (dc *DataConstraint) GetKeyword() string {
+	return dc.keyword
+}
+
+// GetPointer returns the pointer to the value of the keyword
+func (dc *DataConstraint) GetPointer() string {
+	return dc.pointer
+}
+
+// Validate validates the value against the keyword. Without a context,
+// pointers can only be resolved within the value itself
+func (dc *DataConstraint) Validate(v interface{}) error {
+	return dc.ValidateContext(context.Background(), v)
+}
+
+// ValidateContext is the same as Validate, but honors the given
+// context. Pointers are resolved within the instance that the
+// validation run started from
+func (dc *DataConstraint) ValidateContext(ctx context.Context, v interface{}) (err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("DataConstraint.Validate %s %s", dc.keyword, dc.pointer).BindError(&err)
+		defer g.End()
+	}
+
+	f := frameFromContext(ctx)
+	if f == nil {
+		f = &frame{value: v, hasValue: true}
+	}
+
+	data, ok, err := f.resolve(dc.pointer)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if pdebug.Enabled {
+			pdebug.Printf("'%s' does not resolve to a value, ignoring %s", dc.pointer, dc.keyword)
+		}
+		return nil
+	}
+	return dc.apply(data, v)
+}
+
+// apply validates v against the keyword, using data as its value
+func (dc *DataConstraint) apply(data, v interface{}) error {
+	switch dc.keyword {
+	case "const":
+		if !dataEqual(v, data) {
+			return errors.Errorf("value must be equal to the value at '%s'", dc.pointer)
+		}
+		return nil
+	case "enum":
+		rv := reflect.ValueOf(data)
+		if rv.Kind() != reflect.Slice {
+			return dc.typeError("an array")
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if dataEqual(v, rv.Index(i).Interface()) {
+				return nil
+			}
+		}
+		return errors.New("value is not in enumeration")
+	case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf":
+		n, ok := numberValue(v)
+		if !ok {
+			return nil
+		}
+		limit, ok := toFloat(data)
+		if !ok {
+			return dc.typeError("a number")
+		}
+
+		nc := Number()
+		switch dc.keyword {
+		case "minimum":
+			nc.Minimum(limit)
+		case "maximum":
+			nc.Maximum(limit)
+		case "exclusiveMinimum":
+			nc.ExclusiveMinimum(limit)
+		case "exclusiveMaximum":
+			nc.ExclusiveMaximum(limit)
+		case "multipleOf":
+			if limit <= 0 {
+				return dc.typeError("a positive number")
+			}
+			nc.MultipleOf(limit)
+		}
+		return nc.Validate(n)
+	case "minLength", "maxLength":
+		if _, ok := v.(string); !ok {
+			return nil
+		}
+		limit, ok := dc.count(data)
+		if !ok {
+			return dc.typeError("a non-negative integer")
+		}
+
+		sc := String()
+		if dc.keyword == "minLength" {
+			sc.MinLength(int64(limit))
+		} else {
+			sc.MaxLength(int64(limit))
+		}
+		return sc.Validate(v)
+	case "pattern":
+		if _, ok := v.(string); !ok {
+			return nil
+		}
+		pat, ok := data.(string)
+		if !ok {
+			return dc.typeError("a string")
+		}
+		rx, err := regexp.Compile(pat)
+		if err != nil {
+			return errors.Wrapf(err, `invalid pattern at '%s'`, dc.pointer)
+		}
+		return String().Regexp(rx).Validate(v)
+	case "minItems", "maxItems":
+		if reflect.ValueOf(v).Kind() != reflect.Slice {
+			return nil
+		}
+		limit, ok := dc.count(data)
+		if !ok {
+			return dc.typeError("a non-negative integer")
+		}
+
+		ac := Array()
+		if dc.keyword == "minItems" {
+			ac.MinItems(limit)
+		} else {
+			ac.MaxItems(limit)
+		}
+		return ac.Validate(v)
+	default:
+		return errors.Errorf("keyword %s does not support $data references", dc.keyword)
+	}
+}
+
+func (dc *DataConstraint) typeError(expected string) error {
+	return errors.Errorf("value at '%s' used for %s must be %s", dc.pointer, dc.keyword, expected)
+}
+
+// count returns data as a non-negative integer
+func (dc *DataConstraint) count(data interface{}) (int, bool) {
+	f, ok := toFloat(data)
+	if !ok || f < 0 || f != float64(int(f)) {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// resolve returns the value at pointer, which is either a JSON pointer
+// resolved from the root of the instance, or a relative JSON pointer
+// resolved from the value that f describes. The boolean is false if
+// there is no such value. If pointer starts from a value that the run
+// does not have, ErrDataUnavailable is returned
+func (f *frame) resolve(pointer string) (interface{}, bool, error) {
+	cur := f
+	rest := pointer
+	if pointer == "" || pointer[0] == '/' {
+		for cur.parent != nil {
+			cur = cur.parent
+		}
+	} else {
+		i := 0
+		for i < len(pointer) && pointer[i] >= '0' && pointer[i] <= '9' {
+			i++
+		}
+		if i == 0 || (i > 1 && pointer[0] == '0') {
+			return nil, false, errors.Errorf("invalid relative JSON pointer '%s'", pointer)
+		}
+		up, err := strconv.Atoi(pointer[:i])
+		if err != nil {
+			return nil, false, errors.Wrapf(err, `invalid relative JSON pointer '%s'`, pointer)
+		}
+		for ; up > 0; up-- {
+			if cur.parent == nil {
+				return nil, false, nil
+			}
+			cur = cur.parent
+		}
+
+		rest = pointer[i:]
+		if rest == "#" {
+			// The name or index of the value itself
+			if cur.parent == nil {
+				return nil, false, nil
+			}
+			if cur.parent.hasValue && reflect.ValueOf(cur.parent.value).Kind() == reflect.Slice {
+				if n, err := strconv.Atoi(cur.seg); err == nil {
+					return float64(n), true, nil
+				}
+			}
+			return cur.seg, true, nil
+		}
+	}
+
+	tokens, err := splitPointer(rest)
+	if err != nil {
+		return nil, false, err
+	}
+	if !cur.hasValue {
+		return nil, false, errors.Wrapf(ErrDataUnavailable, `failed to resolve '%s'`, pointer)
+	}
+
+	rv := reflect.ValueOf(cur.value)
+	for _, token := range tokens {
+		for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+			rv = rv.Elem()
+		}
+
+		switch rv.Kind() {
+		case reflect.Map, reflect.Struct:
+			rv = getPropValue(rv, token)
+		case reflect.Slice, reflect.Array:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= rv.Len() {
+				return nil, false, nil
+			}
+			rv = rv.Index(i)
+		default:
+			return nil, false, nil
+		}
+		if rv == zeroval {
+			return nil, false, nil
+		}
+	}
+
+	if !rv.IsValid() {
+		return nil, true, nil
+	}
+	return rv.Interface(), true, nil
+}
+
+// numberValue returns v in a form that NumberConstraint accepts, or
+// false if v is not a number
+func numberValue(v interface{}) (interface{}, bool) {
+	if n, ok := v.(json.Number); ok {
+		return n, true
+	}
+	f, ok := toFloat(v)
+	return f, ok
+}
+
+// toFloat converts a numeric value to float64
+func toFloat(v interface{}) (float64, bool) {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// dataEqual reports whether a and b are equal JSON values. Numbers are
+// compared by value regardless of their Go type
+func dataEqual(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		if !ok {
+			return false
+		}
+		na, aok := a.(json.Number)
+		nb, bok := b.(json.Number)
+		if aok && bok {
+			ra, ok1 := new(big.Rat).SetString(string(na))
+			rb, ok2 := new(big.Rat).SetString(string(nb))
+			if ok1 && ok2 {
+				return ra.Cmp(rb) == 0
+			}
+		}
+		return fa == fb
+	}
+
+	ra := reflect.ValueOf(a)
+	rb := reflect.ValueOf(b)
+	if !ra.IsValid() || !rb.IsValid() {
+		return !ra.IsValid() && !rb.IsValid()
+	}
+
+	switch ra.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rb.Kind() != reflect.Slice && rb.Kind() != reflect.Array {
+			return false
+		}
+		if ra.Len() != rb.Len() {
+			return false
+		}
+		for i := 0; i < ra.Len(); i++ {
+			if !dataEqual(ra.Index(i).Interface(), rb.Index(i).Interface()) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if rb.Kind() != reflect.Map || ra.Len() != rb.Len() {
+			return false
+		}
+		for _, key := range ra.MapKeys() {
+			name, ok := key.Interface().(string)
+			if !ok {
+				return false
+			}
+			bv := getPropValue(rb, name)
+			if bv == zeroval || !dataEqual(ra.MapIndex(key).Interface(), bv.Interface()) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if ra.Kind() == reflect.String && rb.Kind() == reflect.String {
+		return ra.String() == rb.String()
+	}
+	return reflect.DeepEqual(a, b)
+}
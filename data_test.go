@@ -0,0 +1,183 @@
+package validator_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/go-json-schema/schema"
+	"github.com/go-json-schema/validator"
+	"github.com/go-json-schema/validator/builder"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestData(t *testing.T) {
+	const src = `{
+  "type": "object",
+  "properties": {
+    "start": { "type": "integer" },
+    "end": { "type": "integer", "minimum": { "$data": "1/start" } },
+    "password": { "type": "string", "minLength": 8 },
+    "confirmPassword": { "type": "string", "const": { "$data": "/password" } },
+    "sizes": { "type": "array", "items": { "type": "string" } },
+    "size": { "type": "string", "enum": { "$data": "1/sizes" } },
+    "slots": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "index": { "type": "integer", "const": { "$data": "1#" } }
+        }
+      }
+    }
+  }
+}`
+
+	s, err := schema.Parse(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Parse should succeed") {
+		return
+	}
+	v, err := builder.New().Build(s)
+	if !assert.NoError(t, err, "Build should succeed") {
+		return
+	}
+
+	valid := []map[string]interface{}{
+		{"start": float64(1), "end": float64(1)},
+		{"start": float64(1), "end": float64(5)},
+		{"end": float64(5)}, // start is missing, so minimum is ignored
+		{"password": "12345678", "confirmPassword": "12345678"},
+		{"sizes": []interface{}{"S", "M"}, "size": "M"},
+		{"slots": []interface{}{
+			map[string]interface{}{"index": float64(0)},
+			map[string]interface{}{"index": float64(1)},
+		}},
+	}
+	for _, x := range valid {
+		if !assert.NoError(t, v.Validate(x), "%v is valid", x) {
+			return
+		}
+	}
+
+	invalid := map[string]map[string]interface{}{
+		"/end":             {"start": float64(5), "end": float64(1)},
+		"/confirmPassword": {"password": "12345678", "confirmPassword": "87654321"},
+		"/size":            {"sizes": []interface{}{"S", "M"}, "size": "L"},
+		"/slots/1/index": {"slots": []interface{}{
+			map[string]interface{}{"index": float64(0)},
+			map[string]interface{}{"index": float64(0)},
+		}},
+	}
+	for pointer, x := range invalid {
+		err := v.Validate(x)
+		if !assert.Error(t, err, "%v is invalid", x) {
+			return
+		}
+		verr, ok := validator.AsValidationError(err)
+		if !assert.True(t, ok, "ValidationError can be extracted") {
+			return
+		}
+		if !assert.Equal(t, pointer, verr.Pointer, "pointer matches") {
+			return
+		}
+	}
+
+	// The referenced value must have the expected type
+	if !assert.Error(t, v.Validate(map[string]interface{}{"start": "1", "end": float64(1)}), "minimum must be a number") {
+		return
+	}
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, validator.NewGenerator().Process(&buf, v), "Process() succeeds") {
+		return
+	}
+	if !assert.Contains(t, buf.String(), `validator.Data("minimum", "1/start")`, "generated code contains the $data reference") {
+		return
+	}
+
+	buf.Reset()
+	if !assert.NoError(t, validator.NewDocGenerator().Format(validator.DocHTML).Process(&buf, v), "Process() succeeds") {
+		return
+	}
+	if !assert.Contains(t, buf.String(), "minimum from <code>1/start</code>", "HTML documentation uses code elements") {
+		return
+	}
+
+	s, err = schema.Parse(strings.NewReader(`{ "type": "string", "format": { "$data": "1/format" } }`))
+	if !assert.NoError(t, err, "schema.Parse should succeed") {
+		return
+	}
+	if _, err := builder.New().Build(s); !assert.Error(t, err, "format does not support $data references") {
+		return
+	}
+}
+
+func TestDataConstraint(t *testing.T) {
+	v := validator.New().SetRoot(validator.Object().
+		AddProp("min", validator.Integer()).
+		AddProp("max", validator.Integer()).
+		AddProp("values", validator.Array().Items(validator.All().
+			Add(validator.Integer()).
+			Add(validator.Data("minimum", "2/min")).
+			Add(validator.Data("maximum", "/max")))))
+
+	x := map[string]interface{}{
+		"min":    1,
+		"max":    3,
+		"values": []interface{}{1, 2, 3},
+	}
+	if !assert.NoError(t, v.Validate(x), "values within range are valid") {
+		return
+	}
+
+	x["values"] = []interface{}{1, 4}
+	err := v.Validate(x)
+	if !assert.Error(t, err, "values above max are invalid") {
+		return
+	}
+	if verr, _ := validator.AsValidationError(err); !assert.Equal(t, "/values/1", verr.Pointer, "pointer matches") {
+		return
+	}
+
+	if !assert.Error(t, validator.Data("minimum", "x/min").Validate(1), "invalid relative pointers are reported") {
+		return
+	}
+	if !assert.NoError(t, validator.Data("minimum", "1/min").Validate(1), "pointers outside the value are ignored") {
+		return
+	}
+}
+
+func TestDataUnavailable(t *testing.T) {
+	v := validator.New().SetRoot(validator.Array().Items(validator.Object().
+		AddProp("min", validator.Integer()).
+		AddProp("value", validator.All().
+			Add(validator.Integer()).
+			Add(validator.Data("minimum", "1/min")))))
+
+	// Relative pointers that stay within an element are resolved while
+	// the array is streamed
+	if !assert.NoError(t, v.ValidateReader(strings.NewReader(`[{"min": 1, "value": 2}]`)), "value above min is valid") {
+		return
+	}
+	if !assert.Error(t, v.ValidateReader(strings.NewReader(`[{"min": 3, "value": 2}]`)), "value below min is invalid") {
+		return
+	}
+
+	absolute := validator.New().SetRoot(validator.Array().Items(validator.All().
+		Add(validator.Integer()).
+		Add(validator.Data("minimum", "/0"))))
+	doc := []interface{}{float64(1), float64(0)}
+	if !assert.Error(t, absolute.Validate(doc), "absolute pointers are resolved when the document is decoded") {
+		return
+	}
+	err := absolute.ValidateReader(strings.NewReader(`[1, 0]`))
+	if !assert.Equal(t, validator.ErrDataUnavailable, errors.Cause(err), "the root of a streamed array is not available") {
+		return
+	}
+
+	err = v.ValidateAt("/0/value", float64(2))
+	if !assert.Equal(t, validator.ErrDataUnavailable, errors.Cause(err), "the parents of a fragment are not available") {
+		return
+	}
+}
@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/lestrrat/go-pdebug"
+	"github.com/pkg/errors"
+)
+
+// Discriminator creates a new DiscriminatorConstraint, which selects
+// the constraint to validate against using the value of the property
+// named name
+func Discriminator(name string) *DiscriminatorConstraint {
+	return &DiscriminatorConstraint{
+		propertyName: name,
+		mapping:      make(map[string]Constraint),
+	}
+}
+
+// Map registers the constraint that objects whose discriminator
+// property is set to value must satisfy
+func (dc *DiscriminatorConstraint) Map(value string, c Constraint) *DiscriminatorConstraint {
+	dc.lock.Lock()
+	dc.mapping[value] = c
+	dc.lock.Unlock()
+	return dc
+}
+
+// GetPropertyName returns the name of the discriminator property
+func (dc *DiscriminatorConstraint) GetPropertyName() string {
+	return dc.propertyName
+}
+
+// GetMappingValues returns the discriminator values that have a
+// constraint mapped to them, sorted
+func (dc *DiscriminatorConstraint) GetMappingValues() []string {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+
+	l := make([]string, 0, len(dc.mapping))
+	for value := range dc.mapping {
+		l = append(l, value)
+	}
+	sort.Strings(l)
+	return l
+}
+
+// GetMapping returns the constraint mapped to value, or nil if there
+// is none
+func (dc *DiscriminatorConstraint) GetMapping(value string) Constraint {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+	return dc.mapping[value]
+}
+
+// Validate validates the value against the constraint mapped to the
+// value of its discriminator property. It fails if the value is not
+// an object, if the property is missing or is not a string, or if no
+// constraint is mapped to its value.
+func (dc *DiscriminatorConstraint) Validate(v interface{}) error {
+	return dc.ValidateContext(context.Background(), v)
+}
+
+// ValidateContext is the same as Validate, but honors the given context
+func (dc *DiscriminatorConstraint) ValidateContext(ctx context.Context, v interface{}) (err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("DiscriminatorConstraint.Validate").BindError(&err)
+		defer g.End()
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map, reflect.Struct:
+	default:
+		return errors.New("value must be an object to look up discriminator property '" + dc.propertyName + "'")
+	}
+
+	pv := getPropValue(rv, dc.propertyName)
+	if pv == zeroval {
+		return errors.New("discriminator property '" + dc.propertyName + "' is missing")
+	}
+	if pv.Kind() == reflect.Interface {
+		pv = pv.Elem()
+	}
+	if pv.Kind() != reflect.String {
+		return errors.New("discriminator property '" + dc.propertyName + "' must be a string")
+	}
+
+	value := pv.String()
+	c := dc.GetMapping(value)
+	if c == nil {
+		return errors.New("unknown value '" + value + "' for discriminator property '" + dc.propertyName + "' (expected one of: " + strings.Join(dc.GetMappingValues(), ", ") + ")")
+	}
+	return validateContext(ctx, c, v)
+}
@@ -0,0 +1,120 @@
+package validator_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-json-schema/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDiscriminatorValidator() *validator.JSVal {
+	return validator.New().SetRoot(validator.Discriminator("kind").
+		Map("cat", validator.Object().
+			AddProp("kind", validator.String()).
+			AddProp("lives", validator.Integer().Maximum(9))).
+		Map("dog", validator.Object().
+			AddProp("kind", validator.String()).
+			AddProp("bark", validator.Boolean())),
+	)
+}
+
+func TestDiscriminator(t *testing.T) {
+	v := newDiscriminatorValidator()
+
+	if !assert.NoError(t, v.Validate(map[string]interface{}{"kind": "dog", "bark": true}), "dog should be valid") {
+		return
+	}
+
+	trace, err := v.Explain(map[string]interface{}{"kind": "cat", "lives": float64(12)})
+	if !assert.Error(t, err, "cat with 12 lives should be invalid") {
+		return
+	}
+	verr, ok := validator.AsValidationError(err)
+	if !assert.True(t, ok, "error carries a ValidationError") {
+		return
+	}
+	if !assert.Equal(t, "/lives", verr.Pointer, "error points to the invalid property") {
+		return
+	}
+	if !assert.Len(t, trace.Root.Children, 1, "only the selected constraint is evaluated") {
+		return
+	}
+	if !assert.Equal(t, "discriminator kind", trace.Root.Name(), "trace names the discriminator") {
+		return
+	}
+
+	type pet struct {
+		Kind string `json:"kind"`
+		Bark bool   `json:"bark"`
+	}
+	if !assert.NoError(t, v.Validate(pet{Kind: "dog", Bark: true}), "structs should be supported") {
+		return
+	}
+	if !assert.Error(t, v.Validate(map[string]interface{}{"kind": float64(1)}), "non-string discriminator should be invalid") {
+		return
+	}
+}
+
+func TestDiscriminatorWalk(t *testing.T) {
+	var visited []string
+	err := validator.Walk(newDiscriminatorValidator().Root(), validator.VisitorFunc(func(path string, c validator.Constraint) bool {
+		visited = append(visited, path)
+		return false
+	}))
+	if !assert.NoError(t, err, "Walk should succeed") {
+		return
+	}
+	if !assert.Equal(t, []string{""}, visited, "children are skipped") {
+		return
+	}
+
+	visited = nil
+	validator.Walk(newDiscriminatorValidator().Root(), validator.VisitorFunc(func(path string, c validator.Constraint) bool {
+		if _, ok := c.(*validator.ObjectConstraint); ok {
+			visited = append(visited, path)
+		}
+		return true
+	}))
+	if !assert.Equal(t, []string{"/discriminator/mapping/cat", "/discriminator/mapping/dog"}, visited, "mapped constraints are visited") {
+		return
+	}
+}
+
+func TestGenerator_Discriminator(t *testing.T) {
+	g := validator.NewGenerator()
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, g.Process(&buf, newDiscriminatorValidator()), "Process() succeeds") {
+		return
+	}
+	if !assert.Contains(t, buf.String(), `Discriminator("kind")`, "generated code builds the discriminator") {
+		return
+	}
+	if !assert.Contains(t, buf.String(), `"cat"`, "generated code maps the values") {
+		return
+	}
+}
+
+func TestDocGenerator_Discriminator(t *testing.T) {
+	v := newDiscriminatorValidator().SetName("Pet")
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, validator.NewDocGenerator().Process(&buf, v), "Process() succeeds") {
+		return
+	}
+	if !assert.Contains(t, buf.String(), "one of (by kind) `cat`: ", "markdown uses backticks") {
+		return
+	}
+
+	buf.Reset()
+	if !assert.NoError(t, validator.NewDocGenerator().Format(validator.DocHTML).Process(&buf, v), "Process() succeeds") {
+		return
+	}
+	if !assert.Contains(t, buf.String(), "one of (by kind) <code>cat</code>: ", "HTML uses code elements") {
+		return
+	}
+	if !assert.NotContains(t, buf.String(), "`", "HTML does not contain backticks") {
+		return
+	}
+}
@@ -0,0 +1,680 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DocFormat is the output format of a DocGenerator
+type DocFormat int
+
+const (
+	// DocMarkdown renders documentation as Markdown
+	DocMarkdown DocFormat = iota
+	// DocHTML renders documentation as an HTML fragment
+	DocHTML
+)
+
+// DocGenerator is responsible for generating human readable
+// documentation for validators. Since validators do not keep the
+// descriptions found in the schema, these must be registered
+// separately, either one by one using Description, or all at once
+// using DescriptionsFromSchema.
+type DocGenerator struct {
+	format       DocFormat
+	title        string
+	descriptions map[string]string
+}
+
+// NewDocGenerator creates a new DocGenerator, which renders Markdown
+func NewDocGenerator() *DocGenerator {
+	return &DocGenerator{
+		format:       DocMarkdown,
+		descriptions: make(map[string]string),
+	}
+}
+
+// Format sets the output format
+func (g *DocGenerator) Format(f DocFormat) *DocGenerator {
+	g.format = f
+	return g
+}
+
+// Title sets the title of the document
+func (g *DocGenerator) Title(s string) *DocGenerator {
+	g.title = s
+	return g
+}
+
+// Description registers the description of the schema found at
+// pointer, such as "#/properties/name" or "#/definitions/address".
+// Descriptions apply to all validators passed to Process.
+func (g *DocGenerator) Description(pointer, s string) *DocGenerator {
+	g.descriptions[pointer] = s
+	return g
+}
+
+// DescriptionsFromSchema registers the descriptions (or titles, for
+// schemas without a description) of the schema s and all of its
+// subschemas. s must be the result of decoding a JSON schema with
+// encoding/json.
+func (g *DocGenerator) DescriptionsFromSchema(s map[string]interface{}) *DocGenerator {
+	g.collectDescriptions(s, "#")
+	return g
+}
+
+func (g *DocGenerator) collectDescriptions(s map[string]interface{}, pointer string) {
+	if d, ok := s["description"].(string); ok {
+		g.descriptions[pointer] = d
+	} else if t, ok := s["title"].(string); ok {
+		g.descriptions[pointer] = t
+	}
+
+	for _, key := range []string{"additionalItems", "additionalProperties", "items", "not"} {
+		if sub, ok := s[key].(map[string]interface{}); ok {
+			g.collectDescriptions(sub, pointer+"/"+key)
+		}
+	}
+
+	for _, key := range []string{"allOf", "anyOf", "oneOf", "items"} {
+		if l, ok := s[key].([]interface{}); ok {
+			for i, v := range l {
+				if sub, ok := v.(map[string]interface{}); ok {
+					g.collectDescriptions(sub, pointer+"/"+key+"/"+strconv.Itoa(i))
+				}
+			}
+		}
+	}
+
+	for _, key := range []string{"definitions", "patternProperties", "properties"} {
+		if m, ok := s[key].(map[string]interface{}); ok {
+			for name, v := range m {
+				if sub, ok := v.(map[string]interface{}); ok {
+					g.collectDescriptions(sub, pointer+"/"+key+"/"+pointerEscaper.Replace(name))
+				}
+			}
+		}
+	}
+}
+
+// Process renders the documentation for the given validators to out.
+// Each validator gets a section, followed by one section per
+// definition it refers to, and one per object nested in a property.
+func (g *DocGenerator) Process(out io.Writer, validators ...*JSVal) error {
+	ctx := &docctx{
+		format:       g.format,
+		descriptions: g.descriptions,
+		anchors:      make(map[Constraint]string),
+		refs:         make(map[string]Constraint),
+		used:         make(map[string]struct{}),
+	}
+
+	// Sections for validators and references are named up front, so
+	// that they can be linked to before they are rendered
+	sorted := make([]*JSVal, len(validators))
+	copy(sorted, validators)
+	sort.Stable(JSValSlice(sorted))
+
+	var names []string
+	var refnames []string
+	for i, v := range sorted {
+		name := v.Name
+		if name == "" {
+			name = fmt.Sprintf("V%d", i)
+		}
+		names = append(names, name)
+		ctx.setAnchor(v.root, name)
+
+		if v.ConstraintMap == nil {
+			continue
+		}
+		v.ConstraintMap.lock.Lock()
+		for rname, rc := range v.ConstraintMap.refmap() {
+			if _, ok := ctx.refs[rname]; ok {
+				continue
+			}
+			ctx.refs[rname] = rc
+			refnames = append(refnames, rname)
+		}
+		v.ConstraintMap.lock.Unlock()
+	}
+
+	sort.Strings(refnames)
+	for _, rname := range refnames {
+		if rname == "#" {
+			continue
+		}
+		if _, ok := ctx.anchorOf(ctx.refs[rname]); !ok {
+			ctx.setAnchor(ctx.refs[rname], strings.TrimPrefix(rname, "#/"))
+		}
+	}
+
+	for i, v := range sorted {
+		if err := ctx.section(names[i], "", "#", v.root); err != nil {
+			return errors.Wrapf(err, `failed to document validator %s`, names[i])
+		}
+	}
+	for _, rname := range refnames {
+		if rname == "#" {
+			continue
+		}
+		title := rname
+		if i := strings.LastIndexByte(rname, '/'); i >= 0 {
+			title = unescapeDocPointer(rname[i+1:])
+		}
+		if err := ctx.section(title, rname, rname, ctx.refs[rname]); err != nil {
+			return errors.Wrapf(err, `failed to document reference %s`, rname)
+		}
+	}
+
+	var buf bytes.Buffer
+	ctx.render(&buf, g.title)
+	_, err := buf.WriteTo(out)
+	return err
+}
+
+func unescapeDocPointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~1", "/", -1), "~0", "~", -1)
+}
+
+type docctx struct {
+	format       DocFormat
+	descriptions map[string]string
+	anchors      map[Constraint]string
+	refs         map[string]Constraint
+	used         map[string]struct{}
+	sections     []*docSection
+}
+
+// docSection documents a single (sub)schema
+type docSection struct {
+	anchor      string
+	title       string
+	ref         string
+	description string
+	typ         string
+	constraints string
+	enum        string
+	def         string
+	props       []docProp
+}
+
+// docProp is a row in the property table of a docSection
+type docProp struct {
+	name        string
+	typ         string
+	required    bool
+	readOnly    bool
+	writeOnly   bool
+	def         string
+	constraints string
+	enum        string
+	description string
+}
+
+// requiredText describes whether the property is required, and in
+// which direction it is sent
+func (p *docProp) requiredText() string {
+	required := "no"
+	if p.required {
+		required = "yes"
+	}
+	switch {
+	case p.readOnly:
+		required += " (read-only)"
+	case p.writeOnly:
+		required += " (write-only)"
+	}
+	return required
+}
+
+var docAnchorRx = regexp.MustCompile(`[^a-z0-9]+`)
+
+// anchor creates a unique anchor for name
+func (ctx *docctx) anchor(name string) string {
+	base := strings.Trim(docAnchorRx.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if base == "" {
+		base = "section"
+	}
+	a := base
+	for i := 2; ; i++ {
+		if _, ok := ctx.used[a]; !ok {
+			break
+		}
+		a = base + "-" + strconv.Itoa(i)
+	}
+	ctx.used[a] = struct{}{}
+	return a
+}
+
+// resolve follows references within the documented validators
+func (ctx *docctx) resolve(c Constraint) Constraint {
+	for i := 0; i < 100; i++ {
+		rc, ok := c.(*ReferenceConstraint)
+		if !ok {
+			break
+		}
+		if resolved, ok := ctx.refs[rc.reference]; ok {
+			c = resolved
+			continue
+		}
+		resolved, err := rc.Resolved()
+		if err != nil {
+			break
+		}
+		c = resolved
+	}
+	return c
+}
+
+// section documents c, which is found at pointer, and adds sections
+// for the objects nested in its properties
+func (ctx *docctx) section(title, ref, pointer string, c Constraint) error {
+	s := &docSection{
+		title:       title,
+		ref:         ref,
+		description: ctx.descriptions[pointer],
+	}
+	if anchor, ok := ctx.anchorOf(c); ok {
+		s.anchor = anchor
+	} else {
+		s.anchor = ctx.anchor(title)
+	}
+	ctx.sections = append(ctx.sections, s)
+
+	o, ok := c.(*ObjectConstraint)
+	if !ok || len(o.properties) == 0 {
+		s.typ = ctx.bareTypeOf(c, pointer)
+		s.constraints = ctx.constraintsOf(c)
+		s.enum = ctx.enumOf(c)
+		s.def = ctx.defaultOf(c)
+		return nil
+	}
+
+	s.typ = "object"
+	s.constraints = ctx.constraintsOf(c)
+	for _, name := range o.GetPropNames() {
+		pc := o.GetProp(name)
+		ppointer := pointer + "/properties/" + pointerEscaper.Replace(name)
+		description, ok := ctx.descriptions[ppointer]
+		if !ok {
+			if rc, isRef := pc.(*ReferenceConstraint); isRef {
+				description = ctx.descriptions[rc.reference]
+			}
+		}
+
+		// Objects nested in properties get their own section, so
+		// that their properties can be documented as well
+		rc := ctx.resolve(pc)
+		if no, ok := unwrapItems(rc).(*ObjectConstraint); ok && len(no.properties) > 0 {
+			if _, ok := ctx.anchorOf(no); !ok {
+				ctx.setAnchor(no, title+"."+name)
+				npointer := ppointer
+				if no != rc {
+					npointer += "/items"
+				}
+				if err := ctx.section(title+"."+name, "", npointer, no); err != nil {
+					return err
+				}
+			}
+		}
+
+		s.props = append(s.props, docProp{
+			name:        name,
+			typ:         ctx.typeOf(pc, ppointer),
+			required:    o.IsPropRequired(name),
+			readOnly:    o.IsPropReadOnly(name),
+			writeOnly:   o.IsPropWriteOnly(name),
+			def:         ctx.defaultOf(rc),
+			constraints: ctx.constraintsOf(rc),
+			enum:        ctx.enumOf(rc),
+			description: description,
+		})
+	}
+	return nil
+}
+
+// anchorOf returns the anchor of the section that documents c.
+// Constraints of types that can not be used as map keys have none, and
+// are documented inline wherever they are used
+func (ctx *docctx) anchorOf(c Constraint) (string, bool) {
+	if c == nil || !reflect.TypeOf(c).Comparable() {
+		return "", false
+	}
+	anchor, ok := ctx.anchors[c]
+	return anchor, ok
+}
+
+// setAnchor names the section that documents c, unless c can not be
+// used as a map key
+func (ctx *docctx) setAnchor(c Constraint, name string) {
+	if c == nil || !reflect.TypeOf(c).Comparable() {
+		return
+	}
+	ctx.anchors[c] = ctx.anchor(name)
+}
+
+// unwrapItems returns the item constraint of arrays, and c otherwise
+func unwrapItems(c Constraint) Constraint {
+	if a, ok := c.(*ArrayConstraint); ok && a.items != nil {
+		return a.items
+	}
+	return c
+}
+
+// text escapes s for the output format
+func (ctx *docctx) text(s string) string {
+	if ctx.format == DocHTML {
+		return html.EscapeString(s)
+	}
+	s = strings.Replace(s, "|", `\|`, -1)
+	return strings.Replace(s, "\n", " ", -1)
+}
+
+func (ctx *docctx) code(s string) string {
+	if ctx.format == DocHTML {
+		return "<code>" + html.EscapeString(s) + "</code>"
+	}
+	return "`" + ctx.text(s) + "`"
+}
+
+func (ctx *docctx) link(anchor, s string) string {
+	if ctx.format == DocHTML {
+		return `<a href="#` + anchor + `">` + html.EscapeString(s) + "</a>"
+	}
+	return "[" + ctx.text(s) + "](#" + anchor + ")"
+}
+
+// typeOf describes the type of c, linking to the sections of
+// references and nested objects
+func (ctx *docctx) typeOf(c Constraint, pointer string) string {
+	if rc, ok := c.(*ReferenceConstraint); ok {
+		if anchor, ok := ctx.anchorOf(ctx.resolve(c)); ok {
+			return ctx.link(anchor, strings.TrimPrefix(rc.reference, "#/"))
+		}
+		return ctx.code(rc.reference)
+	}
+	if o, ok := c.(*ObjectConstraint); ok {
+		if anchor, ok := ctx.anchorOf(o); ok {
+			return ctx.link(anchor, "object")
+		}
+	}
+	return ctx.bareTypeOf(c, pointer)
+}
+
+// bareTypeOf describes the type of c, without linking to the section
+// that documents c itself
+func (ctx *docctx) bareTypeOf(c Constraint, pointer string) string {
+	switch c := c.(type) {
+	case *StringConstraint:
+		return "string"
+	case *IntegerConstraint:
+		return "integer"
+	case *NumberConstraint:
+		return "number"
+	case *BooleanConstraint:
+		return "boolean"
+	case *EnumConstraint:
+		return "enum"
+	case *ArrayConstraint:
+		if c.items != nil {
+			return "array of " + ctx.typeOf(c.items, pointer+"/items")
+		}
+		if len(c.positionalItems) > 0 {
+			l := make([]string, len(c.positionalItems))
+			for i, item := range c.positionalItems {
+				l[i] = ctx.typeOf(item, pointer+"/items/"+strconv.Itoa(i))
+			}
+			return "array [" + strings.Join(l, ", ") + "]"
+		}
+		return "array"
+	case *ObjectConstraint:
+		return "object"
+	case *AnyConstraint:
+		return ctx.combinationOf("anyOf", c.constraints, pointer)
+	case *AllConstraint:
+		return ctx.combinationOf("allOf", c.constraints, pointer)
+	case *OneOfConstraint:
+		return ctx.combinationOf("oneOf", c.constraints, pointer)
+	case *DiscriminatorConstraint:
+		values := c.GetMappingValues()
+		l := make([]string, len(values))
+		for i, value := range values {
+			l[i] = ctx.code(value) + ": " + ctx.typeOf(c.GetMapping(value), pointer+"/discriminator/mapping/"+pointerEscaper.Replace(value))
+		}
+		return "one of (by " + ctx.text(c.propertyName) + ") " + strings.Join(l, ", ")
+	case *NotConstraint:
+		return "not " + ctx.typeOf(c.child, pointer+"/not")
+	case *DataConstraint:
+		return c.keyword + " from " + ctx.code(c.pointer)
+	}
+
+	switch c {
+	case NullConstraint:
+		return "null"
+	case EmptyConstraint:
+		return "any"
+	}
+	return "unknown"
+}
+
+func (ctx *docctx) combinationOf(name string, l []Constraint, pointer string) string {
+	types := make([]string, len(l))
+	for i, c := range l {
+		types[i] = ctx.typeOf(c, pointer+"/"+name+"/"+strconv.Itoa(i))
+	}
+	return name + "(" + strings.Join(types, ", ") + ")"
+}
+
+// constraintsOf lists the keywords that restrict the values of c
+func (ctx *docctx) constraintsOf(c Constraint) string {
+	var l []string
+	add := func(keyword string, v interface{}) {
+		l = append(l, ctx.text(fmt.Sprintf("%s: %v", keyword, v)))
+	}
+
+	switch c := c.(type) {
+	case *StringConstraint:
+		if c.minLength > 0 {
+			add("minLength", c.minLength)
+		}
+		if c.maxLength > -1 {
+			add("maxLength", c.maxLength)
+		}
+		if c.regexp != nil {
+			l = append(l, ctx.text("pattern: ")+ctx.code(c.regexp.String()))
+		}
+		if c.format != "" {
+			add("format", c.format)
+		}
+	case *IntegerConstraint:
+		return ctx.constraintsOf(&c.NumberConstraint)
+	case *NumberConstraint:
+		switch c.applyMinimum {
+		case applyLimitInclusive:
+			add("minimum", c.minimum)
+		case applyLimitExclusive:
+			add("exclusiveMinimum", c.minimum)
+		}
+		switch c.applyMaximum {
+		case applyLimitInclusive:
+			add("maximum", c.maximum)
+		case applyLimitExclusive:
+			add("exclusiveMaximum", c.maximum)
+		}
+		if c.applyMultipleOf {
+			add("multipleOf", c.multipleOf)
+		}
+	case *ArrayConstraint:
+		if c.minItems > -1 {
+			add("minItems", c.minItems)
+		}
+		if c.maxItems > -1 {
+			add("maxItems", c.maxItems)
+		}
+		if c.uniqueItems {
+			add("uniqueItems", true)
+		}
+		if len(c.positionalItems) > 0 && c.items == nil && c.additionalItems == nil {
+			add("additionalItems", false)
+		}
+	case *ObjectConstraint:
+		if c.minProperties > -1 {
+			add("minProperties", c.minProperties)
+		}
+		if c.maxProperties > -1 {
+			add("maxProperties", c.maxProperties)
+		}
+		patterns := make([]string, 0, len(c.patternProperties))
+		for rx := range c.patternProperties {
+			patterns = append(patterns, rx.String())
+		}
+		sort.Strings(patterns)
+		for _, p := range patterns {
+			l = append(l, ctx.text("patternProperties: ")+ctx.code(p))
+		}
+		if c.additionalProperties == nil {
+			add("additionalProperties", false)
+		}
+	}
+
+	if ctx.format == DocHTML {
+		return strings.Join(l, "<br>")
+	}
+	return strings.Join(l, ", ")
+}
+
+func (ctx *docctx) enumOf(c Constraint) string {
+	var enum []interface{}
+	switch c := c.(type) {
+	case *StringConstraint:
+		enum = c.GetEnum()
+	case *IntegerConstraint:
+		enum = c.GetEnum()
+	case *NumberConstraint:
+		enum = c.GetEnum()
+	case *EnumConstraint:
+		enum = c.enums
+	}
+
+	l := make([]string, len(enum))
+	for i, v := range enum {
+		l[i] = ctx.code(docJSON(v))
+	}
+	return strings.Join(l, ", ")
+}
+
+func (ctx *docctx) defaultOf(c Constraint) string {
+	if c == nil || !c.HasDefault() {
+		return ""
+	}
+	return ctx.code(docJSON(c.DefaultValue()))
+}
+
+func docJSON(v interface{}) string {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(buf)
+}
+
+func (ctx *docctx) render(out *bytes.Buffer, title string) {
+	if ctx.format == DocHTML {
+		ctx.renderHTML(out, title)
+		return
+	}
+	ctx.renderMarkdown(out, title)
+}
+
+func (ctx *docctx) renderMarkdown(out *bytes.Buffer, title string) {
+	if title != "" {
+		fmt.Fprintf(out, "# %s\n\n", title)
+	}
+
+	for _, s := range ctx.sections {
+		fmt.Fprintf(out, "<a name=\"%s\"></a>\n## %s\n\n", s.anchor, s.title)
+		if s.ref != "" {
+			fmt.Fprintf(out, "%s\n\n", ctx.code(s.ref))
+		}
+		if s.description != "" {
+			fmt.Fprintf(out, "%s\n\n", s.description)
+		}
+
+		if len(s.props) == 0 {
+			fmt.Fprintf(out, "Type: %s\n\n", s.typ)
+			if s.constraints != "" {
+				fmt.Fprintf(out, "Constraints: %s\n\n", s.constraints)
+			}
+			if s.enum != "" {
+				fmt.Fprintf(out, "Allowed values: %s\n\n", s.enum)
+			}
+			if s.def != "" {
+				fmt.Fprintf(out, "Default: %s\n\n", s.def)
+			}
+			continue
+		}
+
+		if s.constraints != "" {
+			fmt.Fprintf(out, "Constraints: %s\n\n", s.constraints)
+		}
+		fmt.Fprintf(out, "| Property | Type | Required | Default | Constraints | Enum | Description |\n")
+		fmt.Fprintf(out, "|----------|------|----------|---------|-------------|------|-------------|\n")
+		for _, p := range s.props {
+			required := p.requiredText()
+			fmt.Fprintf(out, "| %s | %s | %s | %s | %s | %s | %s |\n", ctx.code(p.name), p.typ, required, p.def, p.constraints, p.enum, ctx.text(p.description))
+		}
+		fmt.Fprintf(out, "\n")
+	}
+}
+
+func (ctx *docctx) renderHTML(out *bytes.Buffer, title string) {
+	if title != "" {
+		fmt.Fprintf(out, "<h1>%s</h1>\n", html.EscapeString(title))
+	}
+
+	for _, s := range ctx.sections {
+		fmt.Fprintf(out, "<h2 id=\"%s\">%s</h2>\n", s.anchor, html.EscapeString(s.title))
+		if s.ref != "" {
+			fmt.Fprintf(out, "<p>%s</p>\n", ctx.code(s.ref))
+		}
+		if s.description != "" {
+			fmt.Fprintf(out, "<p>%s</p>\n", html.EscapeString(s.description))
+		}
+
+		if len(s.props) == 0 {
+			fmt.Fprintf(out, "<dl>\n<dt>Type</dt><dd>%s</dd>\n", s.typ)
+			if s.constraints != "" {
+				fmt.Fprintf(out, "<dt>Constraints</dt><dd>%s</dd>\n", s.constraints)
+			}
+			if s.enum != "" {
+				fmt.Fprintf(out, "<dt>Allowed values</dt><dd>%s</dd>\n", s.enum)
+			}
+			if s.def != "" {
+				fmt.Fprintf(out, "<dt>Default</dt><dd>%s</dd>\n", s.def)
+			}
+			fmt.Fprintf(out, "</dl>\n")
+			continue
+		}
+
+		if s.constraints != "" {
+			fmt.Fprintf(out, "<p>Constraints: %s</p>\n", s.constraints)
+		}
+		fmt.Fprintf(out, "<table>\n<thead><tr><th>Property</th><th>Type</th><th>Required</th><th>Default</th><th>Constraints</th><th>Enum</th><th>Description</th></tr></thead>\n<tbody>\n")
+		for _, p := range s.props {
+			required := p.requiredText()
+			fmt.Fprintf(out, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n", ctx.code(p.name), p.typ, required, p.def, p.constraints, p.enum, html.EscapeString(p.description))
+		}
+		fmt.Fprintf(out, "</tbody>\n</table>\n")
+	}
+}
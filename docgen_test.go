@@ -0,0 +1,147 @@
+package validator_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-json-schema/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDocValidator() *validator.JSVal {
+	cm := &validator.ConstraintMap{}
+	cm.SetReference("#/definitions/address", validator.Object().
+		AddProp("zip", validator.String().RegexpString(`^[0-9]{5}$`)).
+		AddProp("city", validator.String()).
+		Required("zip"),
+	)
+
+	return validator.New().
+		SetName("Person").
+		SetConstraintMap(cm).
+		SetRoot(validator.Object().
+			AddProp("name", validator.String().MinLength(1).Default("anonymous")).
+			AddProp("status", validator.String().Enum("active", "inactive")).
+			AddProp("age", validator.Integer().Minimum(0).ExclusiveMaximum(200)).
+			AddProp("address", validator.Reference(cm).RefersTo("#/definitions/address")).
+			AddProp("tags", validator.Array().Items(validator.String()).UniqueItems(true)).
+			AddProp("meta", validator.Object().
+				AddProp("created", validator.String().Format("datetime")).
+				AdditionalProperties(validator.EmptyConstraint)).
+			Required("name"),
+		)
+}
+
+const docSchema = `{
+  "description": "A person | someone",
+  "properties": {
+    "name": { "description": "The <full> name" },
+    "address": { "$ref": "#/definitions/address" }
+  },
+  "definitions": {
+    "address": { "title": "Postal address" }
+  }
+}`
+
+func TestDocGenerator_Markdown(t *testing.T) {
+	var s map[string]interface{}
+	if !assert.NoError(t, json.Unmarshal([]byte(docSchema), &s), "json.Unmarshal succeeds") {
+		return
+	}
+
+	g := validator.NewDocGenerator().Title("API").DescriptionsFromSchema(s)
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, g.Process(&buf, newDocValidator()), "Process() succeeds") {
+		return
+	}
+	doc := buf.String()
+	t.Logf("%s", doc)
+
+	for _, expected := range []string{
+		"# API\n",
+		"<a name=\"person\"></a>\n## Person\n\nA person | someone\n",
+		"| `name` | string | yes | `\"anonymous\"` | minLength: 1 |  | The <full> name |",
+		"| `status` | string | no |  |  | `\"active\"`, `\"inactive\"` |  |",
+		"| `age` | integer | no |  | minimum: 0, exclusiveMaximum: 200 |  |  |",
+		"| `address` | [definitions/address](#definitions-address) | no |  | additionalProperties: false |  | Postal address |",
+		"| `tags` | array of string | no |  | uniqueItems: true |  |  |",
+		"| `meta` | [object](#person-meta) | no |  |  |  |  |",
+		"<a name=\"person-meta\"></a>\n## Person.meta\n",
+		"| `created` | string | no |  | format: datetime |  |  |",
+		"<a name=\"definitions-address\"></a>\n## address\n\n`#/definitions/address`\n\nPostal address\n",
+		"| `zip` | string | yes |  | pattern: `^[0-9]{5}$` |  |  |",
+	} {
+		if !assert.Contains(t, doc, expected, "documentation contains %q", expected) {
+			return
+		}
+	}
+}
+
+func TestDocGenerator_HTML(t *testing.T) {
+	g := validator.NewDocGenerator().
+		Format(validator.DocHTML).
+		Description("#/properties/name", "The <full> name")
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, g.Process(&buf, newDocValidator()), "Process() succeeds") {
+		return
+	}
+	doc := buf.String()
+	t.Logf("%s", doc)
+
+	for _, expected := range []string{
+		`<h2 id="person">Person</h2>`,
+		`<tr><td><code>name</code></td><td>string</td><td>yes</td><td><code>&#34;anonymous&#34;</code></td><td>minLength: 1</td><td></td><td>The &lt;full&gt; name</td></tr>`,
+		`<td><a href="#definitions-address">definitions/address</a></td>`,
+		`<h2 id="definitions-address">address</h2>`,
+	} {
+		if !assert.Contains(t, doc, expected, "documentation contains %q", expected) {
+			return
+		}
+	}
+}
+
+func TestDocGenerator_Scalar(t *testing.T) {
+	v := validator.New().SetName("Code").SetRoot(validator.String().MaxLength(3).Enum("abc", "def"))
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, validator.NewDocGenerator().Process(&buf, v), "Process() succeeds") {
+		return
+	}
+
+	expected := "<a name=\"code\"></a>\n## Code\n\nType: string\n\nConstraints: maxLength: 3\n\nAllowed values: `\"abc\"`, `\"def\"`\n\n"
+	if !assert.Equal(t, expected, buf.String(), "documentation matches") {
+		return
+	}
+}
+
+// docgenFuncConstraint is a constraint whose type can not be used as a
+// map key
+type docgenFuncConstraint func(interface{}) error
+
+func (docgenFuncConstraint) DefaultValue() interface{}      { return nil }
+func (docgenFuncConstraint) HasDefault() bool               { return false }
+func (c docgenFuncConstraint) Validate(v interface{}) error { return c(v) }
+
+func TestDocGenerator_NotComparable(t *testing.T) {
+	fc := docgenFuncConstraint(func(interface{}) error { return nil })
+
+	m := &validator.ConstraintMap{}
+	m.SetReference("#/definitions/custom", fc)
+	root := validator.New().SetName("Root").SetConstraintMap(m).SetRoot(validator.Object().
+		AddProp("inline", fc).
+		AddProp("ref", validator.Reference(m).RefersTo("#/definitions/custom")))
+	custom := validator.New().SetName("Custom").SetRoot(fc)
+
+	for _, g := range []*validator.DocGenerator{validator.NewDocGenerator(), validator.NewDocGenerator().Format(validator.DocHTML)} {
+		var buf bytes.Buffer
+		if !assert.NoError(t, g.Process(&buf, root, custom), "Process() succeeds") {
+			return
+		}
+		if !assert.Contains(t, buf.String(), "Custom", "validator is documented") {
+			return
+		}
+	}
+}
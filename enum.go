@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"encoding/json"
 	"errors"
 
 	"github.com/lestrrat/go-pdebug"
@@ -24,6 +25,11 @@ func (c *EnumConstraint) Append(v interface{}) *EnumConstraint {
 	return c
 }
 
+// GetEnum returns the possible enumerations
+func (c *EnumConstraint) GetEnum() []interface{} {
+	return c.enums
+}
+
 // Validate validates the value against the list of enumerations
 func (c *EnumConstraint) Validate(v interface{}) (err error) {
 	if pdebug.Enabled {
@@ -35,5 +41,17 @@ func (c *EnumConstraint) Validate(v interface{}) (err error) {
 			return nil
 		}
 	}
+
+	// Numbers decoded from raw JSON are kept as json.Number, but
+	// enumerations built from schemas contain float64 values
+	if n, ok := v.(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			for _, e := range c.enums {
+				if e == f {
+					return nil
+				}
+			}
+		}
+	}
 	return errors.New("value is not in enumeration")
 }
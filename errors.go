@@ -0,0 +1,108 @@
+package validator
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ValidationError describes a validation failure, along with the
+// location in the input value where it occurred.
+type ValidationError struct {
+	// Pointer is the JSON pointer (RFC 6901) to the value that failed
+	// validation. The empty string refers to the whole input.
+	Pointer string
+	// Offset is the byte offset in the input where the value that
+	// failed validation starts. It is only available when validating
+	// raw JSON (see JSVal.ValidateJSON and JSVal.ValidateReader).
+	// Otherwise it is -1
+	Offset int64
+	// Err is the underlying error
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	msg := e.Err.Error()
+	switch {
+	case e.Offset > -1:
+		return msg + " (at '" + e.Pointer + "', offset " + strconv.FormatInt(e.Offset, 10) + ")"
+	case e.Pointer != "":
+		return msg + " (at '" + e.Pointer + "')"
+	default:
+		return msg
+	}
+}
+
+// Unwrap returns the underlying error
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// AsValidationError returns the ValidationError describing the
+// location where validation failed, if err carries one.
+func AsValidationError(err error) (*ValidationError, bool) {
+	verr, ok := errors.Cause(err).(*ValidationError)
+	return verr, ok
+}
+
+// CombinationError is returned when a value does not satisfy an
+// AnyConstraint or a OneOfConstraint. It retains the errors reported
+// by each branch, and points out the one that is most likely to be
+// the branch the value was meant to satisfy.
+type CombinationError struct {
+	// Keyword is either "anyOf" or "oneOf"
+	Keyword string
+	// Causes holds the error reported by each branch, in the order in
+	// which the branches were declared. Branches that passed have a
+	// nil error
+	Causes []error
+	// Best is the index of the most relevant error in Causes, or -1 if
+	// the value was rejected because more than one branch passed
+	Best int
+	// Matched holds the indices of the branches that passed, when a
+	// OneOfConstraint matched more than one of them
+	Matched []int
+}
+
+func (e *CombinationError) Error() string {
+	if len(e.Matched) > 1 {
+		var buf bytes.Buffer
+		buf.WriteString("more than 1 of the constraints passed (branches ")
+		for i, n := range e.Matched {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(strconv.Itoa(n))
+		}
+		buf.WriteByte(')')
+		return buf.String()
+	}
+
+	msg := "none of the constraints passed"
+	if e.Keyword == "anyOf" {
+		msg = "could not validate against any of the constraints"
+	}
+	best := e.BestCause()
+	if best == nil {
+		return msg
+	}
+	if verr, ok := AsValidationError(best); ok {
+		best = verr
+	}
+	return msg + "; closest match is branch " + strconv.Itoa(e.Best) + ": " + best.Error()
+}
+
+// BestCause returns the error reported by the most relevant branch,
+// or nil if there is none
+func (e *CombinationError) BestCause() error {
+	if e.Best < 0 || e.Best >= len(e.Causes) {
+		return nil
+	}
+	return e.Causes[e.Best]
+}
+
+// Unwrap returns the error reported by the most relevant branch
+func (e *CombinationError) Unwrap() error {
+	return e.BestCause()
+}
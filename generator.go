@@ -17,16 +17,80 @@ import (
 
 // Generator is responsible for generating Go code that
 // sets up a validator
-type Generator struct{}
+type Generator struct {
+	hooks   []GeneratorFunc
+	pkgname string
+	prefix  string
+}
+
+// GeneratorFunc writes the Go expression that creates c to out. It is
+// used for constraints that the generator does not know about and that
+// do not implement CodeGenerator, such as constraints defined in
+// another package. It returns false if it does not handle c
+type GeneratorFunc func(gc *CodeContext, out io.Writer, c Constraint) (bool, error)
+
+// CodeGenerator is implemented by constraints that are not defined in
+// this package, so that the generator can create them
+type CodeGenerator interface {
+	// GenerateCode writes the Go expression that creates the
+	// constraint to out. Packages that the expression refers to must
+	// be imported using gc.Import
+	GenerateCode(gc *CodeContext, out io.Writer) error
+}
+
+// CodeContext is passed to CodeGenerator and GeneratorFunc
+type CodeContext struct {
+	ctx *genctx
+}
+
+// Import records that the generated code refers to the package at
+// path, and returns the name to refer to it by. The name is the last
+// element of the path, unless another package already uses it
+func (gc *CodeContext) Import(path string) string {
+	return gc.ctx.importName(path)
+}
+
+// Generate writes the Go expression that creates c to out. It is used
+// for constraints that contain other constraints
+func (gc *CodeContext) Generate(out io.Writer, c Constraint) error {
+	return generateCode(gc.ctx, out, c)
+}
 
 // NewGenerator creates a new Generator
 func NewGenerator() *Generator {
 	return &Generator{}
 }
 
+// Prefix is prepended to the names of the package level variables that
+// hold the constraint map and the references, so that the generated code
+// can be placed in a package that already uses names such as M or R0
+func (g *Generator) Prefix(s string) *Generator {
+	g.prefix = s
+	return g
+}
+
+// Hook registers fn to generate code for constraints that the
+// generator does not know about. Hooks are tried in the order in which
+// they were registered
+func (g *Generator) Hook(fn GeneratorFunc) *Generator {
+	g.hooks = append(g.hooks, fn)
+	return g
+}
+
+// Package makes Process write a complete file: a package clause with
+// the given name, followed by the imports that the code needs. By
+// default the code is written without a package clause, and only
+// packages other than the validator package are imported
+func (g *Generator) Package(name string) *Generator {
+	g.pkgname = name
+	return g
+}
+
 // Process takes a validator and prints out Go code to out.
 func (g *Generator) Process(out io.Writer, validators ...*JSVal) error {
 	ctx := genctx{
+		hooks:    g.hooks,
+		imports:  make(map[string]string),
 		pkgname:  "validator",
 		refnames: make(map[string]string),
 		vname:    "V",
@@ -60,14 +124,14 @@ func (g *Generator) Process(out io.Writer, validators ...*JSVal) error {
 
 	ctx.refs = refs
 	if len(refs) > 0 { // have refs
-		ctx.cmname = "M"
+		ctx.cmname = g.prefix + "M"
 		// sort them by reference name
 		sort.Strings(refnames)
 		fmt.Fprintf(&buf, "\nvar %s *%s.ConstraintMap", ctx.cmname, ctx.pkgname)
 
 		// Generate reference constraint names
 		for i, rname := range refnames {
-			vname := fmt.Sprintf("R%d", i)
+			vname := fmt.Sprintf("%sR%d", g.prefix, i)
 			ctx.refnames[rname] = vname
 			fmt.Fprintf(&buf, "\nvar %s %s.Constraint", vname, ctx.pkgname)
 		}
@@ -109,23 +173,119 @@ func (g *Generator) Process(out io.Writer, validators ...*JSVal) error {
 	}
 	fmt.Fprintf(&buf, "\n}")
 
-	fsrc, err := format.Source(buf.Bytes())
+	src := bytes.Buffer{}
+	if g.pkgname != "" {
+		fmt.Fprintf(&src, "package %s\n\n", g.pkgname)
+		ctx.imports[validatorPath] = ctx.pkgname
+	}
+	generateImports(&ctx, &src)
+	src.Write(buf.Bytes())
+
+	fsrc, err := format.Source(src.Bytes())
 	if err != nil {
-		os.Stderr.Write(buf.Bytes())
+		os.Stderr.Write(src.Bytes())
 		return err
 	}
 	out.Write(fsrc)
 	return nil
 }
 
+// validatorPath is the import path of this package
+const validatorPath = "github.com/go-json-schema/validator"
+
 type genctx struct {
 	cmname   string
+	hooks    []GeneratorFunc
+	imports  map[string]string // import path to package name
 	pkgname  string
 	refs     map[string]Constraint
 	refnames map[string]string
 	vname    string
 }
 
+// importName returns the name that the package at path is referred to
+// by, choosing one that does not conflict with other imports if the
+// package has not been seen yet
+func (ctx *genctx) importName(path string) string {
+	if path == validatorPath {
+		return ctx.pkgname
+	}
+	if name, ok := ctx.imports[path]; ok {
+		return name
+	}
+
+	base := defaultImportName(path)
+	name := base
+	for i := 2; ; i++ {
+		used := name == ctx.pkgname
+		for _, other := range ctx.imports {
+			if other == name {
+				used = true
+				break
+			}
+		}
+		if !used {
+			break
+		}
+		name = base + strconv.Itoa(i)
+	}
+	ctx.imports[path] = name
+	return name
+}
+
+// defaultImportName guesses the name of the package at path from its
+// last element, ignoring version suffixes such as "yaml.v3" and "v2"
+func defaultImportName(path string) string {
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elems[len(elems)-2]
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return -1
+	}, name)
+}
+
+// generateImports writes an import declaration for the packages that
+// the generated code refers to
+func generateImports(ctx *genctx, out io.Writer) {
+	if len(ctx.imports) == 0 {
+		return
+	}
+
+	paths := make([]string, 0, len(ctx.imports))
+	for path := range ctx.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	spec := func(path string) {
+		if name := ctx.imports[path]; name != defaultImportName(path) {
+			fmt.Fprintf(out, "%s ", name)
+		}
+		fmt.Fprintf(out, "%s\n", strconv.Quote(path))
+	}
+
+	if len(paths) == 1 {
+		fmt.Fprint(out, "import ")
+		spec(paths[0])
+		return
+	}
+
+	fmt.Fprint(out, "import (\n")
+	for _, path := range paths {
+		spec(path)
+	}
+	fmt.Fprint(out, ")\n")
+}
+
 func generateEmptyCode(ctx *genctx, out io.Writer, c emptyConstraint) error {
 	fmt.Fprintf(out, "%s.EmptyConstraint", ctx.pkgname)
 	return nil
@@ -221,16 +381,58 @@ func generateCode(ctx *genctx, out io.Writer, c interface {
 		if err := generateReferenceCode(ctx, buf, c.(*ReferenceConstraint)); err != nil {
 			return err
 		}
+	case *DiscriminatorConstraint:
+		if err := generateDiscriminatorCode(ctx, buf, c.(*DiscriminatorConstraint)); err != nil {
+			return err
+		}
 	case *StringConstraint:
 		if err := generateStringCode(ctx, buf, c.(*StringConstraint)); err != nil {
 			return err
 		}
+	case *DataConstraint:
+		if err := generateDataCode(ctx, buf, c.(*DataConstraint)); err != nil {
+			return err
+		}
+	default:
+		if err := generateExternalCode(ctx, buf, c); err != nil {
+			return err
+		}
 	}
 
 	s := buf.String()
 	s = strings.TrimSuffix(s, ".\n")
-	fmt.Fprintf(out, s)
+	fmt.Fprint(out, s)
+
+	return nil
+}
+
+func generateExternalCode(ctx *genctx, out io.Writer, c interface {
+	Validate(interface{}) error
+}) error {
+	gc := &CodeContext{ctx: ctx}
+	if cg, ok := c.(CodeGenerator); ok {
+		if err := cg.GenerateCode(gc, out); err != nil {
+			return errors.Wrapf(err, `failed to generate code for %T`, c)
+		}
+		return nil
+	}
+
+	if c1, ok := c.(Constraint); ok {
+		for _, fn := range ctx.hooks {
+			ok, err := fn(gc, out, c1)
+			if err != nil {
+				return errors.Wrapf(err, `failed to generate code for %T`, c)
+			}
+			if ok {
+				return nil
+			}
+		}
+	}
+	return errors.Errorf(`can not generate code for constraint of type %T`, c)
+}
 
+func generateDataCode(ctx *genctx, out io.Writer, c *DataConstraint) error {
+	fmt.Fprintf(out, "%s.Data(%s, %s)", ctx.pkgname, strconv.Quote(c.keyword), strconv.Quote(c.pointer))
 	return nil
 }
 
@@ -267,6 +469,18 @@ func generateOneOfCode(ctx *genctx, out io.Writer, c *OneOfConstraint) error {
 	return generateComboCode(ctx, out, "OneOf", c.constraints)
 }
 
+func generateDiscriminatorCode(ctx *genctx, out io.Writer, c *DiscriminatorConstraint) error {
+	fmt.Fprintf(out, "%s.Discriminator(%s)", ctx.pkgname, strconv.Quote(c.propertyName))
+	for _, value := range c.GetMappingValues() {
+		fmt.Fprintf(out, ".\nMap(\n%s,\n", strconv.Quote(value))
+		if err := generateCode(ctx, out, c.GetMapping(value)); err != nil {
+			return err
+		}
+		fmt.Fprint(out, ",\n)")
+	}
+	return nil
+}
+
 func generateIntegerCode(ctx *genctx, out io.Writer, c *IntegerConstraint) error {
 	fmt.Fprintf(out, "%s.Integer()", ctx.pkgname)
 
@@ -378,6 +592,24 @@ func generateStringCode(ctx *genctx, out io.Writer, c *StringConstraint) error {
 	return nil
 }
 
+// generatePropNames generates a call to method with the sorted list of
+// property names in m, if any
+func generatePropNames(out io.Writer, method string, m map[string]struct{}) {
+	if len(m) == 0 {
+		return
+	}
+
+	pnames := sortedNames(m)
+	fmt.Fprintf(out, ".\n%s(", method)
+	for i, pname := range pnames {
+		fmt.Fprint(out, strconv.Quote(pname))
+		if i < len(pnames)-1 {
+			fmt.Fprint(out, ", ")
+		}
+	}
+	fmt.Fprint(out, ")")
+}
+
 func generateObjectCode(ctx *genctx, out io.Writer, c *ObjectConstraint) error {
 	fmt.Fprintf(out, "%s.Object()", ctx.pkgname)
 
@@ -385,22 +617,9 @@ func generateObjectCode(ctx *genctx, out io.Writer, c *ObjectConstraint) error {
 		fmt.Fprintf(out, ".\nDefault(%s)", c.DefaultValue())
 	}
 
-	if len(c.required) > 0 {
-		fmt.Fprint(out, ".\nRequired(")
-		l := len(c.required)
-		pnames := make([]string, 0, l)
-		for pname := range c.required {
-			pnames = append(pnames, pname)
-		}
-		sort.Strings(pnames)
-		for i, pname := range pnames {
-			fmt.Fprint(out, strconv.Quote(pname))
-			if i < l-1 {
-				fmt.Fprint(out, ", ")
-			}
-		}
-		fmt.Fprint(out, ")")
-	}
+	generatePropNames(out, "Required", c.required)
+	generatePropNames(out, "ReadOnly", c.readOnly)
+	generatePropNames(out, "WriteOnly", c.writeOnly)
 
 	if aprop := c.additionalProperties; aprop != nil {
 		fmt.Fprintf(out, ".\nAdditionalProperties(\n")
@@ -482,12 +701,16 @@ func generateArrayCode(ctx *genctx, out io.Writer, c *ArrayConstraint) error {
 			return err
 		}
 		fmt.Fprintf(out, ",\n)")
+	} else {
+		// No additional items are allowed
+		fmt.Fprint(out, ".\nAdditionalItems(nil)")
 	}
 
 	if cc := c.positionalItems; len(cc) > 0 {
 		fmt.Fprintf(out, ".\nPositionalItems([]%s.Constraint{\n", ctx.pkgname)
 		for _, ccc := range cc {
 			if err := generateCode(ctx, out, ccc); err != nil {
+				return err
 			}
 			fmt.Fprintf(out, ",\n")
 		}
@@ -2,6 +2,10 @@ package validator_test
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"testing"
 
 	"github.com/go-json-schema/validator"
@@ -18,4 +22,126 @@ func TestGenerator_StringMinLength(t *testing.T) {
 	}
 
 	t.Logf("%s", buf.String())
-}
\ No newline at end of file
+}
+
+// newGeneratorArrayValidator creates the validator whose generated code
+// is in generator_generated_test.go
+func newGeneratorArrayValidator() *validator.JSVal {
+	return validator.New().SetName("GeneratedArray").SetRoot(
+		validator.Array().
+			PositionalItems([]validator.Constraint{validator.String()}).
+			AdditionalItems(nil),
+	)
+}
+
+func TestGenerator_RoundTrip(t *testing.T) {
+	expected, err := ioutil.ReadFile("generator_generated_test.go")
+	if !assert.NoError(t, err, "reading generator_generated_test.go succeeds") {
+		return
+	}
+
+	v := newGeneratorArrayValidator()
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by the Generator from newGeneratorArrayValidator in generator_test.go. DO NOT EDIT.\n\n")
+	if !assert.NoError(t, validator.NewGenerator().Package("validator_test").Process(&buf, v), "Process() succeeds") {
+		return
+	}
+	if !assert.Equal(t, string(expected), buf.String(), "generator_generated_test.go is up to date") {
+		return
+	}
+
+	// The generated validator accepts exactly what the original does
+	for _, value := range [][]interface{}{{"a"}, {"a", "b"}, {float64(1)}} {
+		if !assert.Equal(t, v.Validate(value) == nil, GeneratedArray.Validate(value) == nil, "results match for %#v", value) {
+			return
+		}
+	}
+	if !assert.Error(t, GeneratedArray.Validate([]interface{}{"a", "b"}), "additional items are rejected") {
+		return
+	}
+}
+
+type evenConstraint struct{}
+
+func (evenConstraint) DefaultValue() interface{} { return nil }
+func (evenConstraint) HasDefault() bool          { return false }
+func (evenConstraint) Validate(x interface{}) error {
+	if f, ok := x.(float64); ok && int64(f)%2 != 0 {
+		return errors.New("value must be even")
+	}
+	return nil
+}
+
+func TestGenerator_Hook(t *testing.T) {
+	v := validator.New().SetRoot(validator.All().
+		Add(validator.Integer()).
+		Add(evenConstraint{}))
+
+	buf := bytes.Buffer{}
+	if !assert.Error(t, validator.NewGenerator().Process(&buf, v), "Process() fails without a hook") {
+		return
+	}
+
+	g := validator.NewGenerator().Hook(func(gc *validator.CodeContext, out io.Writer, c validator.Constraint) (bool, error) {
+		if _, ok := c.(evenConstraint); !ok {
+			return false, nil
+		}
+		fmt.Fprintf(out, "%s.Even()", gc.Import("example.com/rules"))
+		return true, nil
+	})
+	buf.Reset()
+	if !assert.NoError(t, g.Process(&buf, v), "Process() succeeds") {
+		return
+	}
+	if !assert.Contains(t, buf.String(), "rules.Even()", "hook output is used") {
+		return
+	}
+	if !assert.Contains(t, buf.String(), "\"example.com/rules\"", "package is imported") {
+		return
+	}
+}
+
+// loggedConstraint wraps a constraint, and generates its own code
+type loggedConstraint struct {
+	validator.Constraint
+	pkg string
+}
+
+func (c loggedConstraint) GenerateCode(gc *validator.CodeContext, out io.Writer) error {
+	fmt.Fprintf(out, "%s.Logged(\n", gc.Import(c.pkg))
+	if err := gc.Generate(out, c.Constraint); err != nil {
+		return err
+	}
+	fmt.Fprint(out, ",\n)")
+	return nil
+}
+
+func TestGenerator_CodeGenerator(t *testing.T) {
+	v := validator.New().SetName("V").SetRoot(validator.Object().
+		AddProp("a", loggedConstraint{Constraint: validator.String().MinLength(1), pkg: "example.com/a/log"}).
+		AddProp("b", loggedConstraint{Constraint: validator.Integer(), pkg: "example.com/b/log"}).
+		AddProp("c", loggedConstraint{Constraint: validator.Boolean(), pkg: "example.com/validator/v2"}))
+
+	buf := bytes.Buffer{}
+	if !assert.NoError(t, validator.NewGenerator().Package("person").Process(&buf, v), "Process() succeeds") {
+		return
+	}
+
+	src := buf.String()
+	for _, s := range []string{
+		"package person\n",
+		"\"example.com/a/log\"\n",
+		"log2 \"example.com/b/log\"\n",
+		"\"github.com/go-json-schema/validator\"\n",
+		"validator2 \"example.com/validator/v2\"\n",
+		"log.Logged(\n",
+		"log2.Logged(\n",
+		"validator2.Logged(\n",
+	} {
+		if !assert.Contains(t, src, s, "generated code contains %q", s) {
+			t.Logf("%s", src)
+			return
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Package instance decodes YAML and TOML documents into the generic
+// values that validators understand, and keeps track of where each
+// value appears in the source, so that validation errors can point at
+// a line and column.
+//
+//	doc, err := instance.ReadFile("config.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	if err := doc.Validate(v); err != nil {
+//		log.Fatal(err) // config.yaml:12:9: ...
+//	}
+package instance
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-json-schema/validator"
+	"github.com/lestrrat/go-pdebug"
+	"github.com/pkg/errors"
+)
+
+// Position is a location in a source document. Lines and columns
+// start at 1. A zero Column means that only the line is known
+type Position struct {
+	Line   int
+	Column int
+}
+
+// IsValid returns true if the position is known
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if p.Column > 0 {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%d", p.Line)
+}
+
+// Document is a decoded YAML or TOML document
+type Document struct {
+	// Filename is the name of the file the document was read from,
+	// if any. It is used in error messages
+	Filename string
+	// Value is the decoded value. Maps have string keys, arrays are
+	// []interface{}, and timestamps are kept as strings
+	Value     interface{}
+	positions map[string]Position
+}
+
+// Position returns the position of the value at the given JSON
+// pointer. If the value itself has no known position, the position of
+// its closest ancestor is returned
+func (d *Document) Position(pointer string) Position {
+	for {
+		if p, ok := d.positions[pointer]; ok {
+			return p
+		}
+		if pointer == "" {
+			return Position{}
+		}
+		pointer = pointer[:strings.LastIndexByte(pointer, '/')]
+	}
+}
+
+// Validate validates the document against v. If validation fails and
+// the location of the failure is known, an *Error is returned
+func (d *Document) Validate(v *validator.JSVal) error {
+	return d.ValidateContext(context.Background(), v)
+}
+
+// ValidateContext is the same as Validate, but honors the given
+// context
+func (d *Document) ValidateContext(ctx context.Context, v *validator.JSVal) (err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("Document.Validate %s", d.Filename).BindError(&err)
+		defer g.End()
+	}
+
+	err = v.ValidateContext(ctx, d.Value)
+	if err == nil {
+		return nil
+	}
+
+	verr, ok := validator.AsValidationError(err)
+	if !ok {
+		return err
+	}
+	return &Error{
+		Filename: d.Filename,
+		Position: d.Position(verr.Pointer),
+		Pointer:  verr.Pointer,
+		Err:      err,
+	}
+}
+
+// Error is a validation error, located in the source document
+type Error struct {
+	Filename string
+	Position Position
+	// Pointer is the JSON pointer to the invalid value
+	Pointer string
+	// Err is the error returned by the validator
+	Err error
+}
+
+func (e *Error) Error() string {
+	var prefix string
+	if e.Filename != "" {
+		prefix = e.Filename + ":"
+	}
+	if e.Position.IsValid() {
+		prefix += e.Position.String() + ":"
+	}
+	if prefix == "" {
+		return e.Err.Error()
+	}
+	return prefix + " " + e.Err.Error()
+}
+
+// Cause returns the error returned by the validator, so that
+// validator.AsValidationError can be used on an *Error
+func (e *Error) Cause() error {
+	return e.Err
+}
+
+// Unwrap returns the error returned by the validator
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ReadFile reads and decodes the document in path. The format is
+// chosen from the extension: ".toml" files are decoded as TOML, and
+// anything else as YAML, which JSON is a subset of
+func ReadFile(path string) (*Document, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, `failed to read %s`, path)
+	}
+
+	var doc *Document
+	if strings.ToLower(filepath.Ext(path)) == ".toml" {
+		doc, err = DecodeTOML(data)
+	} else {
+		doc, err = DecodeYAML(data)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, `failed to decode %s`, path)
+	}
+	doc.Filename = path
+	return doc, nil
+}
+
+// Normalize converts a value produced by a YAML or TOML decoder into
+// the shapes that JSON decoding produces: maps keyed by arbitrary
+// values become map[string]interface{}, typed slices become
+// []interface{}, and timestamps become RFC 3339 strings. The input is
+// not modified
+func Normalize(x interface{}) (interface{}, error) {
+	switch x := x.(type) {
+	case nil, bool, string, float64, int, int64, uint64:
+		return x, nil
+	case time.Time:
+		return x.Format(time.RFC3339Nano), nil
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for key, val := range x {
+			nv, err := Normalize(val)
+			if err != nil {
+				return nil, errors.Wrapf(err, `failed to normalize %s`, key)
+			}
+			m[key] = nv
+		}
+		return m, nil
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for key, val := range x {
+			name, err := keyString(key)
+			if err != nil {
+				return nil, err
+			}
+			nv, err := Normalize(val)
+			if err != nil {
+				return nil, errors.Wrapf(err, `failed to normalize %s`, name)
+			}
+			m[name] = nv
+		}
+		return m, nil
+	}
+
+	rv := reflect.ValueOf(x)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		l := make([]interface{}, rv.Len())
+		for i := range l {
+			nv, err := Normalize(rv.Index(i).Interface())
+			if err != nil {
+				return nil, errors.Wrapf(err, `failed to normalize element %d`, i)
+			}
+			l[i] = nv
+		}
+		return l, nil
+	case reflect.Map:
+		m := make(map[string]interface{}, rv.Len())
+		for _, key := range rv.MapKeys() {
+			name, err := keyString(key.Interface())
+			if err != nil {
+				return nil, err
+			}
+			nv, err := Normalize(rv.MapIndex(key).Interface())
+			if err != nil {
+				return nil, errors.Wrapf(err, `failed to normalize %s`, name)
+			}
+			m[name] = nv
+		}
+		return m, nil
+	}
+	return x, nil
+}
+
+// keyString converts a scalar map key into a string
+func keyString(key interface{}) (string, error) {
+	switch key := key.(type) {
+	case string:
+		return key, nil
+	case nil:
+		return "null", nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(key), nil
+	default:
+		return "", errors.Errorf("map key of type %T can not be converted to a string", key)
+	}
+}
@@ -0,0 +1,219 @@
+package instance
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-json-schema/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func newServerValidator() *validator.JSVal {
+	return validator.New().SetRoot(validator.Object().
+		AddProp("name", validator.String()).
+		AddProp("port", validator.Integer().Minimum(1).Maximum(65535)).
+		AddProp("backends", validator.Array().Items(validator.Object().
+			AddProp("host", validator.String().MinLength(1)).
+			AddProp("weight", validator.Integer()).
+			Required("host"))).
+		Required("name", "port"))
+}
+
+const serverYAML = `defaults: &defaults
+  weight: 1
+name: web
+port: 8080
+backends:
+  - <<: *defaults
+    host: a.example.com
+  - <<: *defaults
+    host: ""
+    weight: 2
+`
+
+func TestDecodeYAML(t *testing.T) {
+	doc, err := DecodeYAML([]byte(serverYAML))
+	if !assert.NoError(t, err, "DecodeYAML should succeed") {
+		return
+	}
+
+	expected := map[string]interface{}{
+		"defaults": map[string]interface{}{"weight": 1},
+		"name":     "web",
+		"port":     8080,
+		"backends": []interface{}{
+			map[string]interface{}{"host": "a.example.com", "weight": 1},
+			map[string]interface{}{"host": "", "weight": 2},
+		},
+	}
+	if !assert.Equal(t, expected, doc.Value, "value matches") {
+		return
+	}
+
+	positions := map[string]Position{
+		"/port":              {Line: 4, Column: 7},
+		"/backends/1":        {Line: 8, Column: 5},
+		"/backends/1/host":   {Line: 9, Column: 11},
+		"/backends/0/weight": {Line: 2, Column: 11},
+		"/backends/1/weight": {Line: 10, Column: 13},
+		"/backends/1/other":  {Line: 8, Column: 5},
+	}
+	for pointer, pos := range positions {
+		if !assert.Equal(t, pos, doc.Position(pointer), "position of %s matches", pointer) {
+			return
+		}
+	}
+
+	err = doc.Validate(newServerValidator())
+	if !assert.Error(t, err, "empty host is invalid") {
+		return
+	}
+	verr, ok := err.(*Error)
+	if !assert.True(t, ok, "error is an *Error") {
+		return
+	}
+	if !assert.Equal(t, "/backends/1/host", verr.Pointer, "pointer matches") {
+		return
+	}
+	if !assert.True(t, strings.HasPrefix(err.Error(), "9:11: "), "message starts with the position: %s", err) {
+		return
+	}
+	if _, ok := validator.AsValidationError(err); !assert.True(t, ok, "ValidationError can be extracted") {
+		return
+	}
+}
+
+func TestDecodeYAMLScalars(t *testing.T) {
+	doc, err := DecodeYAML([]byte("1: one\ntrue: yes\nnull: ~\ncreated: 2001-12-14T21:59:43Z\n"))
+	if !assert.NoError(t, err, "DecodeYAML should succeed") {
+		return
+	}
+	expected := map[string]interface{}{
+		"1":       "one",
+		"true":    "yes",
+		"null":    nil,
+		"created": "2001-12-14T21:59:43Z",
+	}
+	if !assert.Equal(t, expected, doc.Value, "keys are strings and timestamps are kept as strings") {
+		return
+	}
+
+	invalid := map[string]string{
+		"multiple documents": "a: 1\n---\nb: 2\n",
+		"complex key":        "? [a, b]\n: c\n",
+		"syntax":             "a: [1, 2\n",
+	}
+	for name, src := range invalid {
+		if _, err := DecodeYAML([]byte(src)); !assert.Error(t, err, "%s should fail", name) {
+			return
+		}
+	}
+}
+
+const serverTOML = `# server
+name = "web"
+port = 0
+description = """
+[not a table]
+"""
+
+[[backends]]
+host = "a.example.com"
+weight = 1
+
+[[backends]]
+host = ""
+tags = [
+  "x",
+]
+
+[limits]
+"max.conns" = 10
+`
+
+func TestDecodeTOML(t *testing.T) {
+	doc, err := DecodeTOML([]byte(serverTOML))
+	if !assert.NoError(t, err, "DecodeTOML should succeed") {
+		return
+	}
+
+	expected := map[string]interface{}{
+		"name":        "web",
+		"port":        int64(0),
+		"description": "[not a table]\n",
+		"backends": []interface{}{
+			map[string]interface{}{"host": "a.example.com", "weight": int64(1)},
+			map[string]interface{}{"host": "", "tags": []interface{}{"x"}},
+		},
+		"limits": map[string]interface{}{"max.conns": int64(10)},
+	}
+	if !assert.Equal(t, expected, doc.Value, "value matches") {
+		return
+	}
+
+	positions := map[string]Position{
+		"/port":              {Line: 3, Column: 8},
+		"/backends/0/host":   {Line: 9, Column: 8},
+		"/backends/1":        {Line: 12, Column: 1},
+		"/backends/1/host":   {Line: 13, Column: 8},
+		"/backends/1/tags/0": {Line: 14, Column: 8},
+		"/limits/max.conns":  {Line: 19, Column: 15},
+	}
+	for pointer, pos := range positions {
+		if !assert.Equal(t, pos, doc.Position(pointer), "position of %s matches", pointer) {
+			return
+		}
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "instance")
+	if !assert.NoError(t, err, "TempDir should succeed") {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "server.toml")
+	if !assert.NoError(t, ioutil.WriteFile(path, []byte(serverTOML), 0644), "WriteFile should succeed") {
+		return
+	}
+
+	doc, err := ReadFile(path)
+	if !assert.NoError(t, err, "ReadFile should succeed") {
+		return
+	}
+	err = doc.Validate(newServerValidator())
+	if !assert.Error(t, err, "empty host is invalid") {
+		return
+	}
+	if !assert.True(t, strings.HasPrefix(err.Error(), path+":13:8: "), "message starts with the location: %s", err) {
+		return
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	ts := time.Date(2001, 12, 14, 21, 59, 43, 0, time.UTC)
+	x, err := Normalize(map[interface{}]interface{}{
+		"a": []map[string]interface{}{{"b": ts}},
+		1:   map[interface{}]interface{}{"c": "d"},
+	})
+	if !assert.NoError(t, err, "Normalize should succeed") {
+		return
+	}
+	expected := map[string]interface{}{
+		"a": []interface{}{map[string]interface{}{"b": "2001-12-14T21:59:43Z"}},
+		"1": map[string]interface{}{"c": "d"},
+	}
+	if !assert.Equal(t, expected, x, "value matches") {
+		return
+	}
+
+	_, err = Normalize(map[interface{}]interface{}{[2]int{1, 2}: "x"})
+	if !assert.Error(t, err, "composite keys can not be converted") {
+		return
+	}
+}
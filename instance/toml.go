@@ -0,0 +1,223 @@
+package instance
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// DecodeTOML decodes the TOML document in data. Datetimes are
+// converted to RFC 3339 strings, and arrays of tables to arrays of
+// objects.
+//
+// The TOML decoder does not report positions, so they are recovered
+// by scanning the source: every table and key/value pair gets the
+// position of its header or value. Elements of arrays get the
+// position of the array
+func DecodeTOML(data []byte) (*Document, error) {
+	var m map[string]interface{}
+	if _, err := toml.Decode(string(data), &m); err != nil {
+		return nil, errors.Wrap(err, `failed to decode TOML`)
+	}
+
+	x, err := Normalize(m)
+	if err != nil {
+		return nil, errors.Wrap(err, `failed to convert TOML`)
+	}
+	return &Document{Value: x, positions: scanTOML(data)}, nil
+}
+
+// tomlScanner records the positions of tables and keys in a TOML
+// document
+type tomlScanner struct {
+	positions map[string]Position
+	// arrays holds the number of elements of each array of tables,
+	// keyed by pointer
+	arrays map[string]int
+	// table is the pointer to the current table
+	table string
+}
+
+func scanTOML(data []byte) map[string]Position {
+	s := tomlScanner{
+		positions: map[string]Position{"": {Line: 1, Column: 1}},
+		arrays:    make(map[string]int),
+	}
+
+	var closing string // delimiter of the multi-line string we are in
+	depth := 0         // nesting of the multi-line array we are in
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		if closing != "" {
+			if i := strings.Index(text, closing); i >= 0 {
+				closing = ""
+				depth = bracketDepth(text[i+3:], depth)
+			}
+			continue
+		}
+		if depth > 0 {
+			closing = openString(text)
+			depth = bracketDepth(text, depth)
+			continue
+		}
+
+		trimmed := strings.TrimSpace(text)
+		column := len(text) - len(strings.TrimLeft(text, " \t")) + 1
+		switch {
+		case trimmed == "" || trimmed[0] == '#':
+		case strings.HasPrefix(trimmed, "[["):
+			end := strings.Index(trimmed, "]]")
+			if end < 0 {
+				continue
+			}
+			ptr := s.resolve(splitKey(trimmed[2:end]))
+			n := s.arrays[ptr]
+			s.arrays[ptr] = n + 1
+			s.table = ptr + "/" + strconv.Itoa(n)
+			s.record(ptr, line, column)
+			s.positions[s.table] = Position{Line: line, Column: column}
+		case trimmed[0] == '[':
+			end := strings.IndexByte(trimmed, ']')
+			if end < 0 {
+				continue
+			}
+			s.table = s.resolve(splitKey(trimmed[1:end]))
+			s.record(s.table, line, column)
+		default:
+			eq := keyEnd(trimmed)
+			if eq < 0 {
+				continue
+			}
+			value := strings.TrimLeft(trimmed[eq+1:], " \t")
+			valueColumn := column + len(trimmed) - len(value)
+
+			ptr := s.table
+			for _, seg := range splitKey(trimmed[:eq]) {
+				ptr += "/" + pointerEscaper.Replace(seg)
+				s.record(ptr, line, column)
+			}
+			s.positions[ptr] = Position{Line: line, Column: valueColumn}
+
+			closing = openString(value)
+			if closing == "" {
+				depth = bracketDepth(value, 0)
+			}
+		}
+	}
+	return s.positions
+}
+
+// record sets the position of ptr, unless it is already known
+func (s *tomlScanner) record(ptr string, line, column int) {
+	if _, ok := s.positions[ptr]; !ok {
+		s.positions[ptr] = Position{Line: line, Column: column}
+	}
+}
+
+// resolve returns the pointer to the table named by the key segments,
+// descending into the last element of the arrays of tables on the way
+func (s *tomlScanner) resolve(segs []string) string {
+	var ptr string
+	for i, seg := range segs {
+		ptr += "/" + pointerEscaper.Replace(seg)
+		if n, ok := s.arrays[ptr]; ok && i < len(segs)-1 {
+			ptr += "/" + strconv.Itoa(n-1)
+		}
+	}
+	return ptr
+}
+
+// splitKey splits a dotted key into its segments, removing quotes
+func splitKey(key string) []string {
+	var segs []string
+	var buf strings.Builder
+	var quote byte
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' && i+1 < len(key) {
+				i++
+				buf.WriteByte(key[i])
+			} else {
+				buf.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			segs = append(segs, strings.TrimSpace(buf.String()))
+			buf.Reset()
+		case c == ' ' || c == '\t':
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return append(segs, strings.TrimSpace(buf.String()))
+}
+
+// keyEnd returns the index of the "=" that ends the key of a key/value
+// pair, or -1
+func keyEnd(s string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '=':
+			return i
+		}
+	}
+	return -1
+}
+
+// openString returns the closing delimiter of the multi-line string
+// that is left open at the end of s, if any
+func openString(s string) string {
+	for _, delim := range []string{`"""`, `'''`} {
+		i := strings.Index(s, delim)
+		if i < 0 {
+			continue
+		}
+		if !strings.Contains(s[i+3:], delim) {
+			return delim
+		}
+	}
+	return ""
+}
+
+// bracketDepth returns the nesting of arrays at the end of s, starting
+// from depth, ignoring brackets in strings and comments
+func bracketDepth(s string, depth int) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return depth
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		}
+	}
+	return depth
+}
@@ -0,0 +1,174 @@
+package instance
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v3"
+)
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// maxAliasNodes limits the number of nodes decoded through aliases, to
+// protect against documents that expand exponentially
+const maxAliasNodes = 1 << 20
+
+// DecodeYAML decodes the YAML document in data. Mapping keys are
+// converted to strings, aliases and merge keys ("<<") are expanded,
+// and timestamps and binary values are kept as strings
+func DecodeYAML(data []byte) (*Document, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+
+	var root yaml.Node
+	if err := dec.Decode(&root); err != nil {
+		if err == io.EOF {
+			return &Document{positions: map[string]Position{}}, nil
+		}
+		return nil, errors.Wrap(err, `failed to decode YAML`)
+	}
+	var next yaml.Node
+	if err := dec.Decode(&next); err != io.EOF {
+		return nil, errors.New("YAML stream must contain a single document")
+	}
+
+	d := yamlDecoder{
+		positions: make(map[string]Position),
+		expanding: make(map[*yaml.Node]struct{}),
+	}
+	x, err := d.decode(&root, "")
+	if err != nil {
+		return nil, err
+	}
+	return &Document{Value: x, positions: d.positions}, nil
+}
+
+type yamlDecoder struct {
+	positions map[string]Position
+	expanding map[*yaml.Node]struct{}
+	aliased   int
+}
+
+func (d *yamlDecoder) decode(n *yaml.Node, pointer string) (interface{}, error) {
+	if len(d.expanding) > 0 {
+		d.aliased++
+		if d.aliased > maxAliasNodes {
+			return nil, errors.New("too many nodes expanded from aliases")
+		}
+	}
+	if n.Kind != yaml.DocumentNode {
+		d.positions[pointer] = Position{Line: n.Line, Column: n.Column}
+	}
+
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return d.decode(n.Content[0], pointer)
+	case yaml.AliasNode:
+		if _, ok := d.expanding[n.Alias]; ok {
+			return nil, errors.Errorf("alias at line %d refers to a value that contains it", n.Line)
+		}
+		d.expanding[n.Alias] = struct{}{}
+		x, err := d.decode(n.Alias, pointer)
+		delete(d.expanding, n.Alias)
+
+		// The position of the alias is more useful than the one of
+		// the anchor, so it is kept
+		d.positions[pointer] = Position{Line: n.Line, Column: n.Column}
+		return x, err
+	case yaml.SequenceNode:
+		l := make([]interface{}, len(n.Content))
+		for i, item := range n.Content {
+			x, err := d.decode(item, pointer+"/"+strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+			l[i] = x
+		}
+		return l, nil
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(n.Content)/2)
+		if err := d.decodeMapping(n, pointer, m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case yaml.ScalarNode:
+		switch n.ShortTag() {
+		case "!!timestamp", "!!binary":
+			return n.Value, nil
+		}
+		var x interface{}
+		if err := n.Decode(&x); err != nil {
+			return nil, errors.Wrapf(err, `failed to decode value at line %d`, n.Line)
+		}
+		return x, nil
+	default:
+		return nil, errors.Errorf("unexpected YAML node at line %d", n.Line)
+	}
+}
+
+// decodeMapping adds the entries of the mapping n to m. Entries that
+// are merged using "<<" are added first, so that explicit entries
+// override them, and so do their positions
+func (d *yamlDecoder) decodeMapping(n *yaml.Node, pointer string, m map[string]interface{}) error {
+	var sources []*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, val := n.Content[i], n.Content[i+1]
+		if key.Kind != yaml.ScalarNode || key.ShortTag() != "!!merge" {
+			continue
+		}
+		for val.Kind == yaml.AliasNode {
+			val = val.Alias
+		}
+		if val.Kind == yaml.SequenceNode {
+			sources = append(sources, val.Content...)
+		} else {
+			sources = append(sources, val)
+		}
+	}
+
+	// Earlier sources take precedence over later ones
+	for i := len(sources) - 1; i >= 0; i-- {
+		src := sources[i]
+		for src.Kind == yaml.AliasNode {
+			src = src.Alias
+		}
+		if src.Kind != yaml.MappingNode {
+			return errors.Errorf("merge key at line %d must refer to a mapping", src.Line)
+		}
+		if _, ok := d.expanding[src]; ok {
+			return errors.Errorf("merge key at line %d refers to a mapping that contains it", src.Line)
+		}
+		d.expanding[src] = struct{}{}
+		err := d.decodeMapping(src, pointer, m)
+		delete(d.expanding, src)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, val := n.Content[i], n.Content[i+1]
+		if key.Kind == yaml.ScalarNode && key.ShortTag() == "!!merge" {
+			continue
+		}
+		if key.Kind != yaml.ScalarNode {
+			return errors.Errorf("mapping key at line %d must be a scalar", key.Line)
+		}
+
+		name := key.Value
+		if key.ShortTag() == "!!null" {
+			name = "null"
+		}
+		x, err := d.decode(val, pointer+"/"+pointerEscaper.Replace(name))
+		if err != nil {
+			return err
+		}
+		m[name] = x
+	}
+	return nil
+}
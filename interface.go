@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"context"
 	"reflect"
 	"regexp"
 	"sync"
@@ -18,9 +19,10 @@ type JSVal struct {
 	// `V2`, etc. If you want to generate more meaningful names, you should
 	// set this value manually. For example, if you are using validator with a
 	// scaffold generator, you might want to set this to a human-readable value
-	Name     string
-	root     Constraint
-	resolver *jsref.Resolver
+	Name      string
+	root      Constraint
+	resolver  *jsref.Resolver
+	direction Direction
 }
 
 // JSValSlice is a list of JSVal validators. This exists in order to define
@@ -35,6 +37,15 @@ type Constraint interface {
 	Validate(interface{}) error
 }
 
+// ContextConstraint is a Constraint that can also validate using a
+// context.Context. Constraints that contain child constraints implement
+// this interface so that cancellation, deadlines and budgets set on
+// the context are honored throughout the whole tree.
+type ContextConstraint interface {
+	Constraint
+	ValidateContext(context.Context, interface{}) error
+}
+
 type emptyConstraint struct{}
 
 // EmptyConstraint is a constraint that returns true for any value
@@ -94,13 +105,13 @@ const (
 // a number (i.e. float) type.
 type NumberConstraint struct {
 	defaultValue
-	applyMinimum     limitApplicationType
-	applyMaximum     limitApplicationType
-	applyMultipleOf  bool
-	minimum          float64
-	maximum          float64
-	multipleOf       float64
-	enums            *EnumConstraint
+	applyMinimum    limitApplicationType
+	applyMaximum    limitApplicationType
+	applyMultipleOf bool
+	minimum         float64
+	maximum         float64
+	multipleOf      float64
+	enums           *EnumConstraint
 }
 
 // IntegerConstraint implements a constraint to match against
@@ -135,9 +146,13 @@ type ObjectConstraint struct {
 	propdeps             map[string][]string
 	reqlock              sync.Mutex
 	required             map[string]struct{}
+	readOnly             map[string]struct{}
+	writeOnly            map[string]struct{}
 	maxProperties        int64
 	minProperties        int64
 	schemadeps           map[string]Constraint
+	proplist             []objectProp
+	propgen              uint64
 
 	// FieldNameFromName takes a struct wrapped in reflect.Value, and a
 	// field name -- in JSON format (i.e. what you specified in your
@@ -190,6 +205,26 @@ type OneOfConstraint struct {
 	comboconstraint
 }
 
+// DiscriminatorConstraint implements a constraint where the value of
+// a single property selects which child constraint the object must
+// satisfy. It is the equivalent of a OneOfConstraint annotated with
+// the OpenAPI discriminator keyword, but validates against the
+// selected child only.
+type DiscriminatorConstraint struct {
+	emptyConstraint
+	propertyName string
+	lock         sync.Mutex
+	mapping      map[string]Constraint
+}
+
+// DataConstraint implements a constraint whose keyword takes its value
+// from the instance being validated, as with ajv's $data references.
+type DataConstraint struct {
+	emptyConstraint
+	keyword string
+	pointer string
+}
+
 // NotConstraint implements a constraint where the result of
 // child constraint is negated -- that is, validation passes
 // only if the child constraint fails.
@@ -8,7 +8,11 @@
 // of JSON Schema.
 package validator
 
-import "github.com/pkg/errors"
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
 
 // New creates a new JSVal instance.
 func New() *JSVal {
@@ -20,11 +24,35 @@ func New() *JSVal {
 // Validate validates the input, and return an error
 // if any of the validations fail
 func (v *JSVal) Validate(x interface{}) error {
+	return v.ValidateContext(context.Background(), x)
+}
+
+// ValidateContext validates the input, and return an error if any of
+// the validations fail. Validation is aborted when the context is
+// canceled or its deadline expires, or when any of the limits set by
+// WithMaxNodes and WithMaxDepth are exceeded.
+func (v *JSVal) ValidateContext(ctx context.Context, x interface{}) error {
+	return v.wrapError(validateContext(v.withDirection(ctx), v.root, x))
+}
+
+// withDirection attaches the direction of the validator to ctx, unless
+// one has been given explicitly using WithDirection
+func (v *JSVal) withDirection(ctx context.Context) context.Context {
+	if v.direction == DirectionAny {
+		return ctx
+	}
+	if _, ok := ctx.Value(directionKey{}).(Direction); ok {
+		return ctx
+	}
+	return WithDirection(ctx, v.direction)
+}
+
+func (v *JSVal) wrapError(err error) error {
 	name := v.Name
 	if len(name) == 0 {
-		return errors.Wrapf(v.root.Validate(x), "validator %p failed", v)
+		return errors.Wrapf(err, "validator %p failed", v)
 	}
-	return errors.Wrapf(v.root.Validate(x), "validator %s failed", name)
+	return errors.Wrapf(err, "validator %s failed", name)
 }
 
 // SetName sets the name for the validator
@@ -33,6 +61,18 @@ func (v *JSVal) SetName(s string) *JSVal {
 	return v
 }
 
+// SetDirection sets the direction that values are validated in, when
+// the context does not specify one. See Direction
+func (v *JSVal) SetDirection(d Direction) *JSVal {
+	v.direction = d
+	return v
+}
+
+// Direction returns the direction that values are validated in
+func (v *JSVal) Direction() Direction {
+	return v.direction
+}
+
 // SetRoot sets the root Constraint object.
 func (v *JSVal) SetRoot(c Constraint) *JSVal {
 	v.root = c
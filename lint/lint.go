@@ -0,0 +1,211 @@
+// Package lint analyzes JSON schemas for problems that do not prevent
+// a validator from being built, but make it behave differently from
+// what the author of the schema most likely intended: contradictory
+// constraints, enum values that can never be valid, unreachable
+// oneOf branches, unknown formats and unused definitions.
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/lestrrat/go-pdebug"
+	"github.com/pkg/errors"
+)
+
+// Severity describes how serious a Problem is
+type Severity int
+
+const (
+	// Warning is used for problems that do not change the outcome of
+	// validation, such as unused definitions
+	Warning Severity = iota
+	// Error is used for problems that cause validation to fail (or
+	// pass) unexpectedly
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Rule names, as reported in Problem.Rule
+const (
+	RuleEmptyRange          = "empty-range"
+	RuleUndeclaredRequired  = "undeclared-required"
+	RuleInvalidEnum         = "invalid-enum"
+	RuleUnreachableOneOf    = "unreachable-oneof"
+	RuleUnknownFormat       = "unknown-format"
+	RuleUnusedDefinition    = "unused-definition"
+	RuleUnresolvedReference = "unresolved-reference"
+)
+
+// Problem is a single problem found in a schema
+type Problem struct {
+	// Pointer is the JSON pointer to the (sub)schema that has the problem
+	Pointer string
+	// Rule is the name of the rule that found the problem
+	Rule string
+	// Severity is the severity of the problem
+	Severity Severity
+	// Message describes the problem
+	Message string
+}
+
+func (p Problem) String() string {
+	pointer := p.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return fmt.Sprintf("%s: %s: %s (%s)", pointer, p.Severity, p.Message, p.Rule)
+}
+
+// Linter analyzes schemas
+type Linter struct {
+	formats map[string]struct{}
+}
+
+// DefaultFormats lists the formats that are checked by
+// validator.StringConstraint
+var DefaultFormats = []string{"datetime", "email", "hostname", "ipv4", "ipv6", "uri"}
+
+// New creates a new Linter that knows about the DefaultFormats
+func New() *Linter {
+	l := &Linter{formats: make(map[string]struct{})}
+	return l.KnownFormats(DefaultFormats...)
+}
+
+// KnownFormats registers additional formats that should not be
+// reported as unknown
+func (l *Linter) KnownFormats(names ...string) *Linter {
+	for _, name := range names {
+		l.formats[name] = struct{}{}
+	}
+	return l
+}
+
+// LintReader reads a JSON schema from src, and lints it
+func (l *Linter) LintReader(src io.Reader) ([]Problem, error) {
+	var s interface{}
+	if err := json.NewDecoder(src).Decode(&s); err != nil {
+		return nil, errors.Wrap(err, `failed to decode schema`)
+	}
+	return l.Lint(s)
+}
+
+// LintJSON lints the JSON schema in data
+func (l *Linter) LintJSON(data []byte) ([]Problem, error) {
+	return l.LintReader(bytes.NewReader(data))
+}
+
+// Lint analyzes the schema s, which must be the result of decoding a
+// JSON schema with encoding/json, and returns the problems found,
+// sorted by their pointers.
+func (l *Linter) Lint(s interface{}) (problems []Problem, err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("Linter.Lint").BindError(&err)
+		defer g.End()
+	}
+
+	root, ok := s.(map[string]interface{})
+	if !ok {
+		if _, ok := s.(bool); ok {
+			return nil, nil
+		}
+		return nil, errors.Errorf(`schema must be an object (was: %T)`, s)
+	}
+
+	ctx := &lintctx{
+		linter: l,
+		root:   root,
+		refs:   make(map[string][]string),
+	}
+	ctx.walk(root, "")
+	ctx.checkDefinitions()
+
+	sort.SliceStable(ctx.problems, func(i, j int) bool {
+		return ctx.problems[i].Pointer < ctx.problems[j].Pointer
+	})
+	return ctx.problems, nil
+}
+
+type lintctx struct {
+	linter   *Linter
+	root     map[string]interface{}
+	refs     map[string][]string // definition -> definitions it refers to
+	problems []Problem
+}
+
+func (ctx *lintctx) report(pointer, rule string, severity Severity, format string, args ...interface{}) {
+	ctx.problems = append(ctx.problems, Problem{
+		Pointer:  pointer,
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// walk lints the schema s found at pointer, and all of its subschemas
+func (ctx *lintctx) walk(s map[string]interface{}, pointer string) {
+	ctx.checkReference(s, pointer)
+	ctx.checkRanges(s, pointer)
+	ctx.checkRequired(s, pointer)
+	ctx.checkEnum(s, pointer)
+	ctx.checkOneOf(s, pointer)
+	ctx.checkFormat(s, pointer)
+
+	for _, key := range []string{"additionalItems", "additionalProperties", "items", "not"} {
+		if sub, ok := s[key].(map[string]interface{}); ok {
+			ctx.walk(sub, pointer+"/"+key)
+		}
+	}
+
+	for _, key := range []string{"allOf", "anyOf", "oneOf", "items"} {
+		if l, ok := s[key].([]interface{}); ok {
+			for i, v := range l {
+				if sub, ok := v.(map[string]interface{}); ok {
+					ctx.walk(sub, fmt.Sprintf("%s/%s/%d", pointer, key, i))
+				}
+			}
+		}
+	}
+
+	for _, key := range []string{"definitions", "dependencies", "patternProperties", "properties"} {
+		m, ok := s[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, name := range sortedKeys(m) {
+			if sub, ok := m[name].(map[string]interface{}); ok {
+				ctx.walk(sub, pointer+"/"+key+"/"+escapePointer(name))
+			}
+		}
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func escapePointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
+
+func unescapePointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~1", "/", -1), "~0", "~", -1)
+}
@@ -0,0 +1,150 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	data := []struct {
+		name     string
+		src      string
+		expected []Problem
+	}{
+		{
+			name: "clean schema",
+			src: `{
+  "type": "object",
+  "properties": {
+    "name": { "type": "string", "minLength": 1, "maxLength": 10, "format": "email" },
+    "address": { "$ref": "#/definitions/address" }
+  },
+  "definitions": {
+    "address": { "properties": { "zip": { "$ref": "#/definitions/zip" } } },
+    "zip": { "type": "string", "pattern": "^[0-9]{5}$" }
+  }
+}`,
+		},
+		{
+			name: "empty ranges",
+			src: `{
+  "properties": {
+    "a": { "minimum": 10, "maximum": 5 },
+    "b": { "minimum": 5, "maximum": 5, "exclusiveMaximum": true },
+    "c": { "exclusiveMinimum": 5, "maximum": 5 },
+    "d": { "minLength": 3, "maxLength": 2 },
+    "e": { "minItems": 3, "maxItems": 2 },
+    "f": { "minimum": 5, "maximum": 5 }
+  }
+}`,
+			expected: []Problem{
+				{Pointer: "/properties/a", Rule: RuleEmptyRange, Severity: Error},
+				{Pointer: "/properties/b", Rule: RuleEmptyRange, Severity: Error},
+				{Pointer: "/properties/c", Rule: RuleEmptyRange, Severity: Error},
+				{Pointer: "/properties/d", Rule: RuleEmptyRange, Severity: Error},
+				{Pointer: "/properties/e", Rule: RuleEmptyRange, Severity: Error},
+			},
+		},
+		{
+			name: "undeclared required properties",
+			src: `{
+  "properties": { "foo": {} },
+  "patternProperties": { "^x-": {} },
+  "required": ["foo", "x-bar", "baz"],
+  "additionalProperties": false
+}`,
+			expected: []Problem{
+				{Pointer: "", Rule: RuleUndeclaredRequired, Severity: Error},
+			},
+		},
+		{
+			name: "invalid enum values",
+			src: `{
+  "properties": {
+    "s": { "type": "string", "maxLength": 3, "enum": ["foo", "quux", 1] },
+    "n": { "type": "number", "multipleOf": 0.1, "enum": [0.3, 1.05] }
+  }
+}`,
+			expected: []Problem{
+				{Pointer: "/properties/n", Rule: RuleInvalidEnum, Severity: Error},
+				{Pointer: "/properties/s", Rule: RuleInvalidEnum, Severity: Error},
+				{Pointer: "/properties/s", Rule: RuleInvalidEnum, Severity: Error},
+			},
+		},
+		{
+			name: "unreachable oneOf branches",
+			src: `{
+  "type": "string",
+  "oneOf": [
+    { "minLength": 1 },
+    { "type": "integer" },
+    { "minLength": 1 },
+    { "not": {} }
+  ]
+}`,
+			expected: []Problem{
+				{Pointer: "/oneOf/1", Rule: RuleUnreachableOneOf, Severity: Error},
+				{Pointer: "/oneOf/2", Rule: RuleUnreachableOneOf, Severity: Error},
+				{Pointer: "/oneOf/3", Rule: RuleUnreachableOneOf, Severity: Error},
+			},
+		},
+		{
+			name: "unknown formats",
+			src:  `{ "type": "string", "format": "date-time" }`,
+			expected: []Problem{
+				{Pointer: "", Rule: RuleUnknownFormat, Severity: Warning},
+			},
+		},
+		{
+			name: "unused definitions and unresolved references",
+			src: `{
+  "properties": { "foo": { "$ref": "#/definitions/foo" }, "bar": { "$ref": "#/definitions/bar" } },
+  "definitions": {
+    "foo": { "type": "string" },
+    "unused": { "$ref": "#/definitions/alsoUnused" },
+    "alsoUnused": { "type": "integer" }
+  }
+}`,
+			expected: []Problem{
+				{Pointer: "/definitions/alsoUnused", Rule: RuleUnusedDefinition, Severity: Warning},
+				{Pointer: "/definitions/unused", Rule: RuleUnusedDefinition, Severity: Warning},
+				{Pointer: "/properties/bar", Rule: RuleUnresolvedReference, Severity: Error},
+			},
+		},
+	}
+
+	for _, d := range data {
+		d := d
+		t.Run(d.name, func(t *testing.T) {
+			problems, err := New().LintJSON([]byte(d.src))
+			if !assert.NoError(t, err, "Lint should succeed") {
+				return
+			}
+
+			for _, p := range problems {
+				t.Logf("%s", p)
+			}
+
+			if !assert.Len(t, problems, len(d.expected), "number of problems") {
+				return
+			}
+			for i, p := range problems {
+				p.Message = ""
+				if !assert.Equal(t, d.expected[i], p, "problem #%d", i) {
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestKnownFormats(t *testing.T) {
+	problems, err := New().KnownFormats("date-time").LintJSON([]byte(`{ "format": "date-time" }`))
+	if !assert.NoError(t, err, "Lint should succeed") {
+		return
+	}
+	if !assert.Empty(t, problems, "registered formats are not reported") {
+		return
+	}
+}
@@ -0,0 +1,384 @@
+package lint
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// checkReference records the definitions referred to by s, and makes
+// sure that local references can be resolved
+func (ctx *lintctx) checkReference(s map[string]interface{}, pointer string) {
+	ref, ok := s["$ref"].(string)
+	if !ok || !strings.HasPrefix(ref, "#") {
+		return
+	}
+
+	if !resolvePointer(ctx.root, ref[1:]) {
+		ctx.report(pointer, RuleUnresolvedReference, Error, "reference %s can not be resolved", ref)
+		return
+	}
+
+	const prefix = "#/definitions/"
+	if !strings.HasPrefix(ref, prefix) {
+		return
+	}
+	name := ref[len(prefix):]
+	if i := strings.IndexByte(name, '/'); i > -1 {
+		name = name[:i]
+	}
+
+	owner := definitionOwner(pointer)
+	ctx.refs[owner] = append(ctx.refs[owner], unescapePointer(name))
+}
+
+// definitionOwner returns the name of the top-level definition that
+// contains pointer, or the empty string if it is not in a definition
+func definitionOwner(pointer string) string {
+	const prefix = "/definitions/"
+	if !strings.HasPrefix(pointer, prefix) {
+		return ""
+	}
+	name := pointer[len(prefix):]
+	if i := strings.IndexByte(name, '/'); i > -1 {
+		name = name[:i]
+	}
+	return unescapePointer(name)
+}
+
+func resolvePointer(root interface{}, pointer string) bool {
+	if pointer == "" {
+		return true
+	}
+	if pointer[0] != '/' {
+		return false
+	}
+
+	cur := root
+	for _, seg := range strings.Split(pointer[1:], "/") {
+		seg = unescapePointer(seg)
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			next, ok := v[seg]
+			if !ok {
+				return false
+			}
+			cur = next
+		case []interface{}:
+			i, err := json.Number(seg).Int64()
+			if err != nil || i < 0 || int(i) >= len(v) {
+				return false
+			}
+			cur = v[i]
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// checkDefinitions reports the top-level definitions that can not be
+// reached from the root schema
+func (ctx *lintctx) checkDefinitions() {
+	defs, ok := ctx.root["definitions"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	reached := make(map[string]struct{})
+	queue := append([]string(nil), ctx.refs[""]...)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if _, ok := reached[name]; ok {
+			continue
+		}
+		reached[name] = struct{}{}
+		queue = append(queue, ctx.refs[name]...)
+	}
+
+	for _, name := range sortedKeys(defs) {
+		if _, ok := reached[name]; !ok {
+			ctx.report("/definitions/"+escapePointer(name), RuleUnusedDefinition, Warning, "definition %q is never referred to", name)
+		}
+	}
+}
+
+// bound is a lower or upper limit on a numeric value
+type bound struct {
+	value     float64
+	exclusive bool
+	set       bool
+}
+
+// numericBounds returns the lower and upper limits set by s, taking
+// both the draft-04 (boolean) and the draft-06+ (numeric) forms of
+// exclusiveMinimum and exclusiveMaximum into account
+func numericBounds(s map[string]interface{}) (lower, upper bound) {
+	if v, ok := s["minimum"].(float64); ok {
+		lower = bound{value: v, set: true}
+		if b, ok := s["exclusiveMinimum"].(bool); ok {
+			lower.exclusive = b
+		}
+	}
+	if v, ok := s["exclusiveMinimum"].(float64); ok && (!lower.set || v >= lower.value) {
+		lower = bound{value: v, exclusive: true, set: true}
+	}
+
+	if v, ok := s["maximum"].(float64); ok {
+		upper = bound{value: v, set: true}
+		if b, ok := s["exclusiveMaximum"].(bool); ok {
+			upper.exclusive = b
+		}
+	}
+	if v, ok := s["exclusiveMaximum"].(float64); ok && (!upper.set || v <= upper.value) {
+		upper = bound{value: v, exclusive: true, set: true}
+	}
+	return
+}
+
+// checkRanges reports minimum/maximum style pairs that no value can
+// satisfy
+func (ctx *lintctx) checkRanges(s map[string]interface{}, pointer string) {
+	lower, upper := numericBounds(s)
+	if lower.set && upper.set {
+		if lower.value > upper.value || (lower.value == upper.value && (lower.exclusive || upper.exclusive)) {
+			ctx.report(pointer, RuleEmptyRange, Error, "no number satisfies both the lower limit %v and the upper limit %v", lower.value, upper.value)
+		}
+	}
+
+	for _, pair := range [][2]string{
+		{"minLength", "maxLength"},
+		{"minItems", "maxItems"},
+		{"minProperties", "maxProperties"},
+	} {
+		min, ok1 := s[pair[0]].(float64)
+		max, ok2 := s[pair[1]].(float64)
+		if ok1 && ok2 && min > max {
+			ctx.report(pointer, RuleEmptyRange, Error, "%s (%v) is greater than %s (%v)", pair[0], min, pair[1], max)
+		}
+	}
+}
+
+// checkRequired reports required properties that can never be present,
+// because they are not declared while additionalProperties is false
+func (ctx *lintctx) checkRequired(s map[string]interface{}, pointer string) {
+	if ap, ok := s["additionalProperties"].(bool); !ok || ap {
+		return
+	}
+
+	required, _ := s["required"].([]interface{})
+	props, _ := s["properties"].(map[string]interface{})
+	patterns, _ := s["patternProperties"].(map[string]interface{})
+	for _, r := range required {
+		name, ok := r.(string)
+		if !ok {
+			continue
+		}
+		if _, ok := props[name]; ok {
+			continue
+		}
+
+		matched := false
+		for pat := range patterns {
+			if rx, err := regexp.Compile(pat); err == nil && rx.MatchString(name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			ctx.report(pointer, RuleUndeclaredRequired, Error, "required property %q is not declared, and additionalProperties is false", name)
+		}
+	}
+}
+
+// checkEnum reports enum values that violate the constraints declared
+// next to the enum
+func (ctx *lintctx) checkEnum(s map[string]interface{}, pointer string) {
+	enum, ok := s["enum"].([]interface{})
+	if !ok {
+		return
+	}
+
+	for i, v := range enum {
+		if reason := violation(s, v); reason != "" {
+			buf, _ := json.Marshal(v)
+			ctx.report(pointer, RuleInvalidEnum, Error, "enum value #%d (%s) can never be valid: %s", i, buf, reason)
+		}
+	}
+}
+
+// violation returns the reason why v violates the keywords in s, or
+// the empty string if it does not violate any of them. Only the
+// keywords that apply to values of a single type are checked.
+func violation(s map[string]interface{}, v interface{}) string {
+	if types := schemaTypes(s); len(types) > 0 && !typeAllowed(types, jsonType(v)) {
+		return "type " + jsonType(v) + " is not allowed"
+	}
+
+	switch x := v.(type) {
+	case float64:
+		lower, upper := numericBounds(s)
+		if lower.set && (x < lower.value || (lower.exclusive && x == lower.value)) {
+			return "less than the lower limit"
+		}
+		if upper.set && (x > upper.value || (upper.exclusive && x == upper.value)) {
+			return "greater than the upper limit"
+		}
+		if m, ok := s["multipleOf"].(float64); ok && m > 0 {
+			// allow for rounding errors, e.g. 0.3 / 0.1 = 2.9999999999999996
+			if q := x / m; math.Abs(q-math.Floor(q+0.5)) > 1e-9 {
+				return "not a multiple of multipleOf"
+			}
+		}
+	case string:
+		l := float64(utf8.RuneCountInString(x))
+		if min, ok := s["minLength"].(float64); ok && l < min {
+			return "shorter than minLength"
+		}
+		if max, ok := s["maxLength"].(float64); ok && l > max {
+			return "longer than maxLength"
+		}
+		if pat, ok := s["pattern"].(string); ok {
+			if rx, err := regexp.Compile(pat); err == nil && !rx.MatchString(x) {
+				return "does not match pattern"
+			}
+		}
+	case []interface{}:
+		l := float64(len(x))
+		if min, ok := s["minItems"].(float64); ok && l < min {
+			return "fewer items than minItems"
+		}
+		if max, ok := s["maxItems"].(float64); ok && l > max {
+			return "more items than maxItems"
+		}
+	case map[string]interface{}:
+		l := float64(len(x))
+		if min, ok := s["minProperties"].(float64); ok && l < min {
+			return "fewer properties than minProperties"
+		}
+		if max, ok := s["maxProperties"].(float64); ok && l > max {
+			return "more properties than maxProperties"
+		}
+		if required, ok := s["required"].([]interface{}); ok {
+			for _, r := range required {
+				if name, ok := r.(string); ok {
+					if _, ok := x[name]; !ok {
+						return "required property " + name + " is missing"
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func jsonType(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if x == math.Trunc(x) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
+
+// schemaTypes returns the types declared in s
+func schemaTypes(s map[string]interface{}) []string {
+	switch t := s["type"].(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		var types []string
+		for _, v := range t {
+			if name, ok := v.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// typeAllowed reports if a value of type typ is allowed by types
+func typeAllowed(types []string, typ string) bool {
+	for _, t := range types {
+		if t == typ || (t == "number" && typ == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typesIntersect reports if any value can be of one of a and one of b
+func typesIntersect(a, b []string) bool {
+	for _, t := range a {
+		if typeAllowed(b, t) || (t == "number" && typeAllowed(b, "integer")) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOneOf reports oneOf branches that can never be the one and only
+// branch that matches a value
+func (ctx *lintctx) checkOneOf(s map[string]interface{}, pointer string) {
+	branches, ok := s["oneOf"].([]interface{})
+	if !ok {
+		return
+	}
+
+	parent := schemaTypes(s)
+	for i, branch := range branches {
+		bp := pointer + "/oneOf/" + strconv.Itoa(i)
+
+		if b, ok := branch.(bool); ok && !b {
+			ctx.report(bp, RuleUnreachableOneOf, Error, "branch never matches")
+			continue
+		}
+
+		for j := 0; j < i; j++ {
+			if reflect.DeepEqual(branches[j], branch) {
+				ctx.report(bp, RuleUnreachableOneOf, Error, "branch is identical to branch %d, so values that match one also match the other", j)
+				break
+			}
+		}
+
+		m, ok := branch.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if not, ok := m["not"].(map[string]interface{}); ok && len(not) == 0 {
+			ctx.report(bp, RuleUnreachableOneOf, Error, "branch never matches")
+		}
+		if types := schemaTypes(m); len(parent) > 0 && len(types) > 0 && !typesIntersect(parent, types) {
+			ctx.report(bp, RuleUnreachableOneOf, Error, "branch types %v do not overlap with types %v", types, parent)
+		}
+	}
+}
+
+// checkFormat reports formats that are not checked by the validator
+func (ctx *lintctx) checkFormat(s map[string]interface{}, pointer string) {
+	format, ok := s["format"].(string)
+	if !ok {
+		return
+	}
+	if _, ok := ctx.linter.formats[format]; !ok {
+		ctx.report(pointer, RuleUnknownFormat, Warning, "format %q is unknown, and will not be checked", format)
+	}
+}
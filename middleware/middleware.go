@@ -0,0 +1,389 @@
+// Package middleware provides an http.Handler middleware that validates
+// requests, and optionally responses, against validators.
+//
+// Routes are registered by method and path pattern, where path
+// parameters are written as "{name}". Requests that do not match any
+// route are passed through unchanged. Invalid requests are answered
+// with an RFC 7807 problem+json document listing the violations.
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-json-schema/validator"
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxBodySize is the default limit for the size of the bodies
+// that are read for validation
+const DefaultMaxBodySize = 10 << 20
+
+// ErrorHandler is called to report invalid requests. It must write the
+// response
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, p *Problem)
+
+// ResponseLogger is called with the result of validating a response
+// when response validation is enabled. err is nil if the response is
+// valid
+type ResponseLogger func(r *http.Request, status int, err error)
+
+// Validator validates the requests passed to the handlers it wraps
+type Validator struct {
+	lock        sync.RWMutex
+	routes      []*Route
+	onError     ErrorHandler
+	onResponse  ResponseLogger
+	maxBodySize int64
+}
+
+// New creates a new Validator without any routes. Invalid requests are
+// reported using WriteProblem
+func New() *Validator {
+	return &Validator{
+		onError:     WriteProblem,
+		maxBodySize: DefaultMaxBodySize,
+	}
+}
+
+// Route registers a new route for requests with the given method and
+// path pattern, and returns it so that validators can be attached.
+// When several routes match a request, the one with the fewest path
+// parameters is used
+func (v *Validator) Route(method, pattern string) *Route {
+	r := newRoute(method, pattern)
+	v.lock.Lock()
+	v.routes = append(v.routes, r)
+	v.lock.Unlock()
+	return r
+}
+
+// ErrorHandler sets the function used to report invalid requests
+func (v *Validator) ErrorHandler(h ErrorHandler) *Validator {
+	v.onError = h
+	return v
+}
+
+// ValidateResponses enables response validation in shadow mode: JSON
+// responses of routes that have a response validator are checked
+// after they have been sent, and the result is passed to l. Responses
+// are never modified
+func (v *Validator) ValidateResponses(l ResponseLogger) *Validator {
+	v.onResponse = l
+	return v
+}
+
+// MaxBodySize sets the maximum size of the bodies read for validation.
+// Larger requests are rejected, and larger responses are not validated
+func (v *Validator) MaxBodySize(n int64) *Validator {
+	v.maxBodySize = n
+	return v
+}
+
+// Handler returns a handler that validates requests before passing
+// them to next. Requests and responses are validated in their
+// respective validator.Direction, so that readOnly and writeOnly
+// properties are handled accordingly
+func (v *Validator) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, params := v.lookup(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if p := v.validateRequest(route, params, r); p != nil {
+			p.Instance = r.URL.Path
+			v.onError(w, r, p)
+			return
+		}
+
+		if v.onResponse == nil || len(route.response) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w, max: v.maxBodySize}
+		next.ServeHTTP(rec, r)
+		v.validateResponse(route, rec, r)
+	})
+}
+
+func (v *Validator) lookup(r *http.Request) (*Route, map[string]string) {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
+	var best *Route
+	var bestParams map[string]string
+	for _, route := range v.routes {
+		params, ok := route.match(r.Method, r.URL.Path)
+		if !ok {
+			continue
+		}
+		if best == nil || len(params) < len(bestParams) {
+			best, bestParams = route, params
+		}
+	}
+	return best, bestParams
+}
+
+func (v *Validator) validateRequest(route *Route, params map[string]string, r *http.Request) *Problem {
+	var violations []Violation
+	ctx := validator.WithDirection(r.Context(), validator.DirectionRequest)
+
+	names := make([]string, 0, len(route.path))
+	for name := range route.path {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := validateParam(ctx, route.path[name], []string{params[name]}); err != nil {
+			violations = append(violations, newViolation("path", name, err))
+		}
+	}
+
+	query := r.URL.Query()
+	names = names[:0]
+	for name := range route.query {
+		names = append(names, name)
+	}
+	for name := range route.required {
+		if _, ok := route.query[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values, ok := query[name]
+		if !ok {
+			if _, required := route.required[name]; required {
+				violations = append(violations, Violation{In: "query", Name: name, Message: "parameter is required"})
+			}
+			continue
+		}
+		if pv := route.query[name]; pv != nil {
+			if err := validateParam(ctx, pv, values); err != nil {
+				violations = append(violations, newViolation("query", name, err))
+			}
+		}
+	}
+
+	if route.body != nil {
+		p, violation := v.validateBody(route, r)
+		if p != nil {
+			return p
+		}
+		if violation != nil {
+			violations = append(violations, *violation)
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &Problem{
+		Type:       "about:blank",
+		Title:      "Request validation failed",
+		Status:     http.StatusBadRequest,
+		Violations: violations,
+	}
+}
+
+// validateBody validates the request body, and replaces it so that the
+// next handler can read it again. It returns a Problem if the body can
+// not be validated at all
+func (v *Validator) validateBody(route *Route, r *http.Request) (*Problem, *Violation) {
+	if r.Body == nil || r.Body == http.NoBody {
+		if route.optional {
+			return nil, nil
+		}
+		return nil, &Violation{In: "body", Message: "request body is required"}
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, v.maxBodySize+1))
+	r.Body.Close()
+	if err != nil {
+		return &Problem{
+			Type:   "about:blank",
+			Title:  "Bad request",
+			Status: http.StatusBadRequest,
+			Detail: "failed to read request body",
+		}, nil
+	}
+	if int64(len(data)) > v.maxBodySize {
+		return &Problem{
+			Type:   "about:blank",
+			Title:  "Request entity too large",
+			Status: http.StatusRequestEntityTooLarge,
+			Detail: "request body exceeds " + strconv.FormatInt(v.maxBodySize, 10) + " bytes",
+		}, nil
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		if route.optional {
+			return nil, nil
+		}
+		return nil, &Violation{In: "body", Message: "request body is required"}
+	}
+	if !isJSON(r.Header.Get("Content-Type")) {
+		return &Problem{
+			Type:   "about:blank",
+			Title:  "Unsupported media type",
+			Status: http.StatusUnsupportedMediaType,
+			Detail: "request body must be JSON",
+		}, nil
+	}
+	ctx := validator.WithDirection(r.Context(), validator.DirectionRequest)
+	if err := route.body.ValidateReaderContext(ctx, bytes.NewReader(data)); err != nil {
+		violation := newViolation("body", "", err)
+		return nil, &violation
+	}
+	return nil, nil
+}
+
+func (v *Validator) validateResponse(route *Route, rec *recorder, r *http.Request) {
+	if rec.hijacked {
+		return
+	}
+
+	status := rec.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	rv := route.responseValidator(status)
+	if rv == nil || !isJSON(rec.Header().Get("Content-Type")) {
+		return
+	}
+	if rec.truncated {
+		v.onResponse(r, status, errors.New("response body too large to be validated"))
+		return
+	}
+	ctx := validator.WithDirection(r.Context(), validator.DirectionResponse)
+	v.onResponse(r, status, rv.ValidateReaderContext(ctx, bytes.NewReader(rec.body.Bytes())))
+}
+
+// validateParam validates the values of a parameter. Parameters are
+// always strings, so values that look like numbers or booleans are
+// also tried in that form
+func validateParam(ctx context.Context, v *validator.JSVal, values []string) error {
+	var candidates []interface{}
+	if len(values) == 1 {
+		candidates = coerce(values[0])
+	} else {
+		strs := make([]interface{}, len(values))
+		coerced := make([]interface{}, len(values))
+		for i, s := range values {
+			strs[i] = s
+			l := coerce(s)
+			coerced[i] = l[0]
+		}
+		candidates = []interface{}{coerced, strs}
+	}
+
+	var first error
+	for _, x := range candidates {
+		err := v.ValidateContext(ctx, x)
+		if err == nil {
+			return nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// coerce returns the possible interpretations of s, most specific first
+func coerce(s string) []interface{} {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return []interface{}{f, s}
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return []interface{}{b, s}
+	}
+	return []interface{}{s}
+}
+
+func isJSON(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+// recorder passes the response through to the client, while keeping a
+// copy of the body for validation
+type recorder struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	max       int64
+	truncated bool
+	hijacked  bool
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	if !rec.truncated {
+		if int64(rec.body.Len()+len(b)) > rec.max {
+			rec.truncated = true
+			rec.body.Reset()
+		} else {
+			rec.body.Write(b)
+		}
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher, if the underlying ResponseWriter does
+func (rec *recorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, if the underlying ResponseWriter
+// does. Once the connection has been taken over, whatever the handler
+// sends is out of sight, so the response is not validated
+func (rec *recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("underlying ResponseWriter does not implement http.Hijacker")
+	}
+
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		rec.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Push implements http.Pusher, if the underlying ResponseWriter does
+func (rec *recorder) Push(target string, opts *http.PushOptions) error {
+	if p, ok := rec.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
@@ -0,0 +1,280 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-json-schema/validator"
+	"github.com/go-json-schema/validator/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestValidator() *Validator {
+	v := New()
+	v.Route("POST", "/pets").
+		Body(validator.New().SetRoot(validator.Object().
+			AddProp("id", validator.Integer()).
+			AddProp("name", validator.String().MinLength(1)).
+			AddProp("age", validator.Integer().Minimum(0)).
+			Required("id", "name").
+			ReadOnly("id")))
+	v.Route("GET", "/pets/{id}").
+		PathParam("id", validator.New().SetRoot(validator.Integer().Minimum(1))).
+		Query("verbose", validator.New().SetRoot(validator.Boolean())).
+		Query("fields", validator.New().SetRoot(validator.String().MaxLength(10))).
+		RequiredQuery("fields").
+		Response("200", validator.New().SetRoot(validator.Object().
+			AddProp("id", validator.Integer()).
+			Required("id")))
+	v.Route("GET", "/pets/mine")
+	return v
+}
+
+func echo(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	w.Write(body)
+}
+
+func serve(h http.Handler, method, target, contentType, body string) *httptest.ResponseRecorder {
+	var req *http.Request
+	if body == "" {
+		req = httptest.NewRequest(method, target, nil)
+	} else {
+		req = httptest.NewRequest(method, target, strings.NewReader(body))
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) *Problem {
+	if !assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"), "response is a problem") {
+		return nil
+	}
+	var p Problem
+	if !assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p), "problem is JSON") {
+		return nil
+	}
+	return &p
+}
+
+func TestBody(t *testing.T) {
+	h := newTestValidator().Handler(http.HandlerFunc(echo))
+
+	w := serve(h, "POST", "/pets", "application/json", `{"name": "Tama"}`)
+	if !assert.Equal(t, http.StatusOK, w.Code, "valid body is accepted") {
+		return
+	}
+	if !assert.Equal(t, `{"name": "Tama"}`, w.Body.String(), "body can be read by the next handler") {
+		return
+	}
+
+	w = serve(h, "POST", "/pets", "application/json", `{"name": "Tama", "age": -1}`)
+	if !assert.Equal(t, http.StatusBadRequest, w.Code, "invalid body is rejected") {
+		return
+	}
+	p := decodeProblem(t, w)
+	if p == nil {
+		return
+	}
+	expected := []Violation{{In: "body", Pointer: "/age", Message: "numeric value is less than the minimum"}}
+	if !assert.Equal(t, expected, p.Violations, "violations match") {
+		return
+	}
+	if !assert.Equal(t, "/pets", p.Instance, "instance is the request path") {
+		return
+	}
+
+	w = serve(h, "POST", "/pets", "application/json", `{"id": 1, "name": "Tama"}`)
+	if !assert.Equal(t, http.StatusBadRequest, w.Code, "readOnly property is rejected in requests") {
+		return
+	}
+
+	w = serve(h, "POST", "/pets", "text/plain", `name=Tama`)
+	if !assert.Equal(t, http.StatusUnsupportedMediaType, w.Code, "non-JSON body is rejected") {
+		return
+	}
+
+	w = serve(h, "POST", "/pets", "", "")
+	if !assert.Equal(t, http.StatusBadRequest, w.Code, "missing body is rejected") {
+		return
+	}
+
+	w = serve(h, "PUT", "/pets", "text/plain", "anything")
+	if !assert.Equal(t, http.StatusOK, w.Code, "unknown routes are passed through") {
+		return
+	}
+}
+
+func TestParams(t *testing.T) {
+	h := newTestValidator().Handler(http.HandlerFunc(echo))
+
+	w := serve(h, "GET", "/pets/1?fields=name&verbose=true", "", "")
+	if !assert.Equal(t, http.StatusOK, w.Code, "valid parameters are accepted") {
+		return
+	}
+
+	w = serve(h, "GET", "/pets/mine", "", "")
+	if !assert.Equal(t, http.StatusOK, w.Code, "literal routes win over parameters") {
+		return
+	}
+
+	w = serve(h, "GET", "/pets/0?verbose=maybe", "", "")
+	p := decodeProblem(t, w)
+	if p == nil {
+		return
+	}
+	var names []string
+	for _, violation := range p.Violations {
+		names = append(names, violation.In+" "+violation.Name)
+	}
+	if !assert.Equal(t, []string{"path id", "query fields", "query verbose"}, names, "all invalid parameters are reported") {
+		return
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	v := newTestValidator().ErrorHandler(func(w http.ResponseWriter, r *http.Request, p *Problem) {
+		p.Type = "https://example.com/problems/validation"
+		p.Status = http.StatusUnprocessableEntity
+		WriteProblem(w, r, p)
+	})
+
+	w := serve(v.Handler(http.HandlerFunc(echo)), "POST", "/pets", "application/json", `{}`)
+	if !assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "custom status is used") {
+		return
+	}
+	p := decodeProblem(t, w)
+	if p == nil {
+		return
+	}
+	if !assert.Equal(t, "https://example.com/problems/validation", p.Type, "custom type is used") {
+		return
+	}
+}
+
+func TestValidateResponses(t *testing.T) {
+	var logged []error
+	v := newTestValidator().ValidateResponses(func(r *http.Request, status int, err error) {
+		logged = append(logged, err)
+	})
+
+	respond := func(body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		})
+	}
+
+	w := serve(v.Handler(respond(`{"id": 1}`)), "GET", "/pets/1?fields=name", "", "")
+	if !assert.Equal(t, `{"id": 1}`, w.Body.String(), "response is passed through") {
+		return
+	}
+	w = serve(v.Handler(respond(`{"name": "Tama"}`)), "GET", "/pets/1?fields=name", "", "")
+	if !assert.Equal(t, `{"name": "Tama"}`, w.Body.String(), "invalid response is passed through") {
+		return
+	}
+
+	if !assert.Len(t, logged, 2, "both responses are validated") {
+		return
+	}
+	if !assert.NoError(t, logged[0], "first response is valid") {
+		return
+	}
+	if !assert.Error(t, logged[1], "second response is invalid") {
+		return
+	}
+}
+
+func TestHijackedResponses(t *testing.T) {
+	var logged []error
+	v := newTestValidator().ValidateResponses(func(r *http.Request, status int, err error) {
+		logged = append(logged, err)
+	})
+
+	srv := httptest.NewServer(v.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Pusher); !ok {
+			t.Errorf("ResponseWriter does not implement http.Pusher")
+		}
+		h, ok := w.(http.Hijacker)
+		if !ok {
+			t.Errorf("ResponseWriter does not implement http.Hijacker")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		conn, rw, err := h.Hijack()
+		if err != nil {
+			t.Errorf("Hijack failed: %s", err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 16\r\nConnection: close\r\n\r\n")
+		rw.WriteString(`{"name": "Tama"}`)
+		rw.Flush()
+	})))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "/pets/1?fields=name")
+	if !assert.NoError(t, err, "request succeeds") {
+		return
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !assert.Equal(t, `{"name": "Tama"}`, string(body), "hijacked response reaches the client") {
+		return
+	}
+	if !assert.Len(t, logged, 0, "hijacked responses are not validated") {
+		return
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	doc, err := openapi.Parse([]byte(`{
+  "openapi": "3.0.0",
+  "paths": {
+    "/pets/{id}": {
+      "put": {
+        "parameters": [
+          { "name": "id", "in": "path", "required": true, "schema": { "type": "integer" } },
+          { "name": "dryRun", "in": "query", "required": true, "schema": { "type": "boolean" } }
+        ],
+        "requestBody": {
+          "content": {
+            "application/json": { "schema": { "type": "object", "properties": { "name": { "type": "string" } }, "required": ["name"] } }
+          }
+        },
+        "responses": {}
+      }
+    }
+  }
+}`))
+	if !assert.NoError(t, err, "openapi.Parse should succeed") {
+		return
+	}
+
+	h := New().OpenAPI(doc).Handler(http.HandlerFunc(echo))
+
+	w := serve(h, "PUT", "/pets/1?dryRun=false", "application/json", `{"name": "Tama"}`)
+	if !assert.Equal(t, http.StatusOK, w.Code, "valid request is accepted") {
+		return
+	}
+	w = serve(h, "PUT", "/pets/1?dryRun=false", "", "")
+	if !assert.Equal(t, http.StatusOK, w.Code, "request body is optional") {
+		return
+	}
+	w = serve(h, "PUT", "/pets/abc", "application/json", `{}`)
+	p := decodeProblem(t, w)
+	if p == nil {
+		return
+	}
+	if !assert.Len(t, p.Violations, 3, "path, query and body violations are reported") {
+		return
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"github.com/go-json-schema/validator/openapi"
+)
+
+// OpenAPI registers a route for every operation of doc, using the
+// validators for JSON request and response bodies, and for path and
+// query parameters
+func (v *Validator) OpenAPI(doc *openapi.Document) *Validator {
+	for _, id := range doc.OperationIDs() {
+		op := doc.Operation(id)
+		route := v.Route(op.Method, op.Path)
+
+		if b := op.RequestBody; b != nil {
+			if bv := b.Validator("application/json"); bv != nil {
+				if b.Required {
+					route.Body(bv)
+				} else {
+					route.OptionalBody(bv)
+				}
+			}
+		}
+
+		for _, p := range op.Parameters {
+			switch p.In {
+			case "path":
+				if p.Validator != nil {
+					route.PathParam(p.Name, p.Validator)
+				}
+			case "query":
+				route.Query(p.Name, p.Validator)
+				if p.Required {
+					route.RequiredQuery(p.Name)
+				}
+			}
+		}
+
+		for status, b := range op.Responses {
+			if rv := b.Validator("application/json"); rv != nil {
+				route.Response(status, rv)
+			}
+		}
+	}
+	return v
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-json-schema/validator"
+)
+
+// Problem is an RFC 7807 problem details document, extended with the
+// list of violations found in the request
+type Problem struct {
+	Type       string      `json:"type"`
+	Title      string      `json:"title"`
+	Status     int         `json:"status"`
+	Detail     string      `json:"detail,omitempty"`
+	Instance   string      `json:"instance,omitempty"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// Violation describes a part of the request that failed validation
+type Violation struct {
+	// In is the part of the request: "body", "query" or "path"
+	In string `json:"in"`
+	// Name is the name of the parameter, for query and path parameters
+	Name string `json:"name,omitempty"`
+	// Pointer is the JSON pointer to the invalid value in the body
+	Pointer string `json:"pointer,omitempty"`
+	// Message describes the failure
+	Message string `json:"message"`
+}
+
+func newViolation(in, name string, err error) Violation {
+	violation := Violation{In: in, Name: name, Message: err.Error()}
+	if verr, ok := validator.AsValidationError(err); ok {
+		violation.Message = verr.Err.Error()
+		if in == "body" {
+			violation.Pointer = verr.Pointer
+		}
+	}
+	return violation
+}
+
+// WriteProblem writes p as an application/problem+json response. It is
+// the default ErrorHandler
+func WriteProblem(w http.ResponseWriter, _ *http.Request, p *Problem) {
+	buf, err := json.Marshal(p)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	w.Write(buf)
+}
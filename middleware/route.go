@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-json-schema/validator"
+)
+
+// Route holds the validators for requests matching a method and a
+// path pattern
+type Route struct {
+	method   string
+	segments []string
+	body     *validator.JSVal
+	optional bool
+	query    map[string]*validator.JSVal
+	required map[string]struct{}
+	path     map[string]*validator.JSVal
+	response map[string]*validator.JSVal
+}
+
+func newRoute(method, pattern string) *Route {
+	return &Route{
+		method:   strings.ToUpper(method),
+		segments: splitPath(pattern),
+		query:    make(map[string]*validator.JSVal),
+		required: make(map[string]struct{}),
+		path:     make(map[string]*validator.JSVal),
+		response: make(map[string]*validator.JSVal),
+	}
+}
+
+// Body sets the validator for the JSON request body. Once set, a body
+// is required
+func (r *Route) Body(v *validator.JSVal) *Route {
+	r.body = v
+	r.optional = false
+	return r
+}
+
+// OptionalBody is the same as Body, but requests without a body are
+// accepted
+func (r *Route) OptionalBody(v *validator.JSVal) *Route {
+	r.body = v
+	r.optional = true
+	return r
+}
+
+// Query sets the validator for the query parameter name
+func (r *Route) Query(name string, v *validator.JSVal) *Route {
+	r.query[name] = v
+	return r
+}
+
+// RequiredQuery marks the query parameters in l as required
+func (r *Route) RequiredQuery(l ...string) *Route {
+	for _, name := range l {
+		r.required[name] = struct{}{}
+	}
+	return r
+}
+
+// PathParam sets the validator for the path parameter name, which
+// must appear in the pattern of the route as "{name}"
+func (r *Route) PathParam(name string, v *validator.JSVal) *Route {
+	r.path[name] = v
+	return r
+}
+
+// Response sets the validator for JSON response bodies with the given
+// status, which is either a status code ("200"), a range ("2XX") or
+// "default". Responses are only validated if response validation has
+// been enabled on the Validator
+func (r *Route) Response(status string, v *validator.JSVal) *Route {
+	r.response[strings.ToUpper(status)] = v
+	return r
+}
+
+// responseValidator returns the validator for responses with the
+// given status code
+func (r *Route) responseValidator(status int) *validator.JSVal {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", "DEFAULT"} {
+		if v, ok := r.response[key]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// match returns true and the values of the path parameters if the
+// request method and path match the route
+func (r *Route) match(method, path string) (map[string]string, bool) {
+	if r.method != method {
+		return nil, false
+	}
+
+	segments := splitPath(path)
+	if len(segments) != len(r.segments) {
+		return nil, false
+	}
+
+	var params map[string]string
+	for i, seg := range r.segments {
+		if name, ok := paramName(seg); ok {
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[name] = segments[i]
+			continue
+		}
+		if seg != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func paramName(seg string) (string, bool) {
+	if len(seg) > 2 && seg[0] == '{' && seg[len(seg)-1] == '}' {
+		return seg[1 : len(seg)-1], true
+	}
+	return "", false
+}
@@ -1,9 +1,12 @@
 package validator
 
 import (
+	"encoding/json"
 	"errors"
 	"math"
+	"math/big"
 	"reflect"
+	"strconv"
 
 	"github.com/lestrrat/go-pdebug"
 )
@@ -61,6 +64,62 @@ func (nc *NumberConstraint) ExclusiveMaximum(v float64) NumericConstraint {
 	return nc
 }
 
+// GetEnum returns the values that this constraint can have, or nil if
+// unspecified
+func (nc *NumberConstraint) GetEnum() []interface{} {
+	if nc.enums == nil {
+		return nil
+	}
+	return nc.enums.GetEnum()
+}
+
+// HasMaximum returns true if a maximum (inclusive or exclusive)
+// has been specified
+func (nc *NumberConstraint) HasMaximum() bool {
+	return nc.applyMaximum != applyLimitNone
+}
+
+// GetMaximum returns the maximum value. Use HasMaximum to check if it
+// has been specified
+func (nc *NumberConstraint) GetMaximum() float64 {
+	return nc.maximum
+}
+
+// IsExclusiveMaximum returns true if the maximum value itself is
+// not allowed
+func (nc *NumberConstraint) IsExclusiveMaximum() bool {
+	return nc.applyMaximum == applyLimitExclusive
+}
+
+// HasMinimum returns true if a minimum (inclusive or exclusive)
+// has been specified
+func (nc *NumberConstraint) HasMinimum() bool {
+	return nc.applyMinimum != applyLimitNone
+}
+
+// GetMinimum returns the minimum value. Use HasMinimum to check if it
+// has been specified
+func (nc *NumberConstraint) GetMinimum() float64 {
+	return nc.minimum
+}
+
+// IsExclusiveMinimum returns true if the minimum value itself is
+// not allowed
+func (nc *NumberConstraint) IsExclusiveMinimum() bool {
+	return nc.applyMinimum == applyLimitExclusive
+}
+
+// HasMultipleOf returns true if multipleOf has been specified
+func (nc *NumberConstraint) HasMultipleOf() bool {
+	return nc.applyMultipleOf
+}
+
+// GetMultipleOf returns the number that the value must be divisible
+// by. Use HasMultipleOf to check if it has been specified
+func (nc *NumberConstraint) GetMultipleOf() float64 {
+	return nc.multipleOf
+}
+
 // Validate validates the value against this constraint
 func (nc *NumberConstraint) Validate(v interface{}) (err error) {
 	if pdebug.Enabled {
@@ -74,19 +133,31 @@ func (nc *NumberConstraint) Validate(v interface{}) (err error) {
 		}()
 	}
 
-	rv := reflect.ValueOf(v)
-	switch rv.Kind() {
-	case reflect.Ptr, reflect.Interface:
-		rv = rv.Elem()
-	}
+	var f float64
+	var exact *big.Rat
+	if n, ok := v.(json.Number); ok {
+		// json.Number values are compared using their exact
+		// representation, so that precision is not lost
+		exact, ok = new(big.Rat).SetString(string(n))
+		if !ok {
+			return errors.New("value is not a valid number")
+		}
+		f, _ = exact.Float64()
+	} else {
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			rv = rv.Elem()
+		}
 
-	switch rv.Kind() {
-	case reflect.Float32, reflect.Float64:
-	default:
-		return errors.New("value is not a float")
-	}
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+		default:
+			return errors.New("value is not a float")
+		}
 
-	f := rv.Float()
+		f = rv.Float()
+	}
 
 	switch nc.applyMinimum {
 	case applyLimitNone:
@@ -94,14 +165,14 @@ func (nc *NumberConstraint) Validate(v interface{}) (err error) {
 		if pdebug.Enabled {
 			pdebug.Printf("Checking inclusive minimum (%f)", nc.minimum)
 		}
-		if nc.minimum >= f {
+		if compareNumber(f, exact, nc.minimum) < 0 {
 			return errors.New("numeric value is less than the minimum")
 		}
 	case applyLimitExclusive:
 		if pdebug.Enabled {
 			pdebug.Printf("Checking exclusive minimum (%f)", nc.minimum)
 		}
-		if nc.minimum > f {
+		if compareNumber(f, exact, nc.minimum) <= 0 {
 			return errors.New("numeric value is less than the minimum")
 		}
 	}
@@ -112,15 +183,15 @@ func (nc *NumberConstraint) Validate(v interface{}) (err error) {
 		if pdebug.Enabled {
 			pdebug.Printf("Checking inclusive maximum (%f)", nc.maximum)
 		}
-		if nc.maximum <= f {
-			return errors.New("numeric value is less than the maximum")
+		if compareNumber(f, exact, nc.maximum) > 0 {
+			return errors.New("numeric value is greater than the maximum")
 		}
 	case applyLimitExclusive:
 		if pdebug.Enabled {
 			pdebug.Printf("Checking exclusive maximum (%f)", nc.maximum)
 		}
-		if nc.maximum < f {
-			return errors.New("numeric value is less than the maximum")
+		if compareNumber(f, exact, nc.maximum) >= 0 {
+			return errors.New("numeric value is greater than the maximum")
 		}
 	}
 
@@ -130,7 +201,12 @@ func (nc *NumberConstraint) Validate(v interface{}) (err error) {
 		}
 
 		if nc.multipleOf != 0 {
-	    if math.Mod(f, nc.multipleOf) != 0 {
+			if exact != nil {
+				q := new(big.Rat).Quo(exact, limitRat(nc.multipleOf))
+				if !q.IsInt() {
+					return errors.New("numeric value is fails multipleOf validation")
+				}
+			} else if math.Mod(f, nc.multipleOf) != 0 {
 				return errors.New("numeric value is fails multipleOf validation")
 			}
 		}
@@ -145,6 +221,32 @@ func (nc *NumberConstraint) Validate(v interface{}) (err error) {
 	return nil
 }
 
+// compareNumber compares the value being validated against a limit.
+// If exact is non-nil, it is used instead of f
+func compareNumber(f float64, exact *big.Rat, limit float64) int {
+	if exact != nil {
+		return exact.Cmp(limitRat(limit))
+	}
+
+	switch {
+	case f < limit:
+		return -1
+	case f > limit:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// limitRat converts a limit to a big.Rat. Limits come from decimal
+// literals in schemas, so the shortest decimal representation of the
+// float is used, rather than its exact binary value: 0.1 is converted
+// to 1/10, and not to 3602879701896397/36028797018963968
+func limitRat(limit float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(limit, 'g', -1, 64))
+	return r
+}
+
 // Number creates a new NumberConstraint
 func Number() *NumberConstraint {
 	return &NumberConstraint{}
@@ -202,6 +304,17 @@ func (ic *IntegerConstraint) Validate(v interface{}) (err error) {
 		}()
 	}
 
+	if n, ok := v.(json.Number); ok {
+		r, ok := new(big.Rat).SetString(string(n))
+		if !ok {
+			return errors.New("value is not numeric")
+		}
+		if !r.IsInt() {
+			return errors.New("value is not an int/uint")
+		}
+		return ic.NumberConstraint.Validate(n)
+	}
+
 	rv := reflect.ValueOf(v)
 	switch rv.Kind() {
 	case reflect.Interface, reflect.Ptr:
@@ -1,6 +1,7 @@
 package validator_test
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -69,3 +70,117 @@ func TestNumber(t *testing.T) {
 		return
 	}
 }
+
+func TestNumberDecimalLimits(t *testing.T) {
+	// Values that lie between a decimal limit and the float64 closest
+	// to it are compared against the decimal value
+	c := validator.Number().Minimum(0.1).Maximum(0.3)
+	for _, n := range []json.Number{"0.1000000000000000001", "0.2", "0.2999999999999999999"} {
+		if !assert.NoError(t, c.Validate(n), "validate should succeed for %s", n) {
+			return
+		}
+	}
+	for _, n := range []json.Number{"0.09", "0.0999999999999999999", "0.3000000000000000001", "0.30001"} {
+		if !assert.Error(t, c.Validate(n), "validate should fail for %s", n) {
+			return
+		}
+	}
+
+	m := validator.Number().MultipleOf(0.01)
+	if !assert.NoError(t, m.Validate(json.Number("19.99")), "19.99 is a multiple of 0.01") {
+		return
+	}
+	if !assert.Error(t, m.Validate(json.Number("19.991")), "19.991 is not a multiple of 0.01") {
+		return
+	}
+
+	v := validator.New().SetRoot(validator.Object().AddProp("p", validator.Number().Minimum(0.1)))
+	if !assert.NoError(t, v.ValidateJSON([]byte(`{"p":0.1000000000000000001}`)), "ValidateJSON should succeed") {
+		return
+	}
+	if !assert.Error(t, v.ValidateJSON([]byte(`{"p":0.0999}`)), "ValidateJSON should fail") {
+		return
+	}
+}
+
+func TestNumberLimits(t *testing.T) {
+	c := validator.Number()
+	c.Minimum(5).Maximum(15)
+	for _, f := range []float64{5, 10, 15} {
+		if !assert.NoError(t, c.Validate(f), "validate should succeed for %f", f) {
+			return
+		}
+	}
+
+	c.ExclusiveMinimum(5).ExclusiveMaximum(15)
+	for _, f := range []float64{5, 15} {
+		if !assert.Error(t, c.Validate(f), "validate should fail for %f", f) {
+			return
+		}
+	}
+	if !assert.NoError(t, c.Validate(float64(10)), "validate should succeed for 10") {
+		return
+	}
+
+	d := validator.Number().Minimum(0.1).Maximum(0.3)
+	for _, n := range []json.Number{"0.1", "0.3"} {
+		if !assert.NoError(t, d.Validate(n), "decimal limits are inclusive for %s", n) {
+			return
+		}
+	}
+
+	v := validator.New().SetRoot(validator.Object().AddProp("p", validator.Number().Minimum(0.1)))
+	if !assert.NoError(t, v.ValidateJSON([]byte(`{"p":0.1}`)), "ValidateJSON should succeed") {
+		return
+	}
+}
+
+func TestNumberLimitMessages(t *testing.T) {
+	data := []struct {
+		c       validator.Constraint
+		value   interface{}
+		message string
+	}{
+		{validator.Number().Minimum(5), float64(4), "numeric value is less than the minimum"},
+		{validator.Number().ExclusiveMinimum(5), float64(5), "numeric value is less than the minimum"},
+		{validator.Number().Maximum(15), float64(16), "numeric value is greater than the maximum"},
+		{validator.Number().ExclusiveMaximum(15), float64(15), "numeric value is greater than the maximum"},
+		{validator.Integer().Maximum(15), 16, "numeric value is greater than the maximum"},
+		{validator.Number().Maximum(15), json.Number("15.5"), "numeric value is greater than the maximum"},
+	}
+	for _, d := range data {
+		err := d.c.Validate(d.value)
+		if !assert.Error(t, err, "validate should fail for %v", d.value) {
+			return
+		}
+		if !assert.Equal(t, d.message, err.Error(), "message matches") {
+			return
+		}
+	}
+
+	for _, x := range []interface{}{json.Number("5"), json.Number("15"), 5, 15} {
+		if !assert.NoError(t, validator.Integer().Minimum(5).Maximum(15).Validate(x), "limits are inclusive for %v", x) {
+			return
+		}
+	}
+}
+
+func TestNumberGetters(t *testing.T) {
+	c := validator.Integer()
+	if !assert.False(t, c.HasMinimum() || c.HasMaximum() || c.HasMultipleOf(), "no limits are set") {
+		return
+	}
+
+	c.ExclusiveMinimum(0)
+	c.Maximum(10)
+	c.MultipleOf(2)
+	if !assert.True(t, c.HasMinimum() && c.IsExclusiveMinimum(), "minimum is exclusive") {
+		return
+	}
+	if !assert.True(t, c.HasMaximum() && !c.IsExclusiveMaximum(), "maximum is inclusive") {
+		return
+	}
+	if !assert.Equal(t, []float64{0, 10, 2}, []float64{c.GetMinimum(), c.GetMaximum(), c.GetMultipleOf()}, "limits match") {
+		return
+	}
+}
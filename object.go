@@ -2,6 +2,7 @@ package validator
 
 import (
 	"bytes"
+	"context"
 	"reflect"
 	"regexp"
 	"sort"
@@ -20,6 +21,11 @@ type getPropNameser interface {
 	GetPropNames() ([]string, error)
 }
 
+var (
+	gpvType = reflect.TypeOf((*getPropValuer)(nil)).Elem()
+	gpnType = reflect.TypeOf((*getPropNameser)(nil)).Elem()
+)
+
 // Object creates a new ObjectConstraint
 func Object() *ObjectConstraint {
 	return &ObjectConstraint{
@@ -30,6 +36,8 @@ func Object() *ObjectConstraint {
 		properties:           make(map[string]Constraint),
 		propdeps:             make(map[string][]string),
 		required:             make(map[string]struct{}),
+		readOnly:             make(map[string]struct{}),
+		writeOnly:            make(map[string]struct{}),
 		schemadeps:           make(map[string]Constraint),
 	}
 }
@@ -37,11 +45,12 @@ func Object() *ObjectConstraint {
 // Required specifies required property names
 func (o *ObjectConstraint) Required(l ...string) *ObjectConstraint {
 	o.reqlock.Lock()
-	defer o.reqlock.Unlock()
-
 	for _, pname := range l {
 		o.required[pname] = struct{}{}
 	}
+	o.reqlock.Unlock()
+
+	o.invalidate()
 	return o
 }
 
@@ -55,6 +64,52 @@ func (o *ObjectConstraint) IsPropRequired(s string) bool {
 	return ok
 }
 
+// ReadOnly marks the given properties as readOnly. They are managed by
+// the server: they are rejected when validating requests, and are not
+// required in them. See WithDirection
+func (o *ObjectConstraint) ReadOnly(l ...string) *ObjectConstraint {
+	o.reqlock.Lock()
+	for _, pname := range l {
+		o.readOnly[pname] = struct{}{}
+	}
+	o.reqlock.Unlock()
+
+	o.invalidate()
+	return o
+}
+
+// WriteOnly marks the given properties as writeOnly. They are never
+// sent back: they are rejected when validating responses, and are not
+// required in them. See WithDirection
+func (o *ObjectConstraint) WriteOnly(l ...string) *ObjectConstraint {
+	o.reqlock.Lock()
+	for _, pname := range l {
+		o.writeOnly[pname] = struct{}{}
+	}
+	o.reqlock.Unlock()
+
+	o.invalidate()
+	return o
+}
+
+// IsPropReadOnly returns true if the given name is marked readOnly
+func (o *ObjectConstraint) IsPropReadOnly(s string) bool {
+	o.reqlock.Lock()
+	defer o.reqlock.Unlock()
+
+	_, ok := o.readOnly[s]
+	return ok
+}
+
+// IsPropWriteOnly returns true if the given name is marked writeOnly
+func (o *ObjectConstraint) IsPropWriteOnly(s string) bool {
+	o.reqlock.Lock()
+	defer o.reqlock.Unlock()
+
+	_, ok := o.writeOnly[s]
+	return ok
+}
+
 // MinProperties specifies the minimum number of properties this
 // constraint can allow. If unspecified, it is not checked.
 func (o *ObjectConstraint) MinProperties(n int64) *ObjectConstraint {
@@ -82,17 +137,20 @@ func (o *ObjectConstraint) AddProp(name string, c Constraint) *ObjectConstraint
 	defer o.proplock.Unlock()
 
 	o.properties[name] = c
+	o.resetPropList()
 	return o
 }
 
 // PatternProperties specifies constraints that properties matching
-// this pattern must be validated against. Note that properties listed
-// using `AddProp` takes precedence.
+// this pattern must be validated against. Properties listed using
+// `AddProp` are validated against both their own constraint and the
+// patterns that they match.
 func (o *ObjectConstraint) PatternProperties(key *regexp.Regexp, c Constraint) *ObjectConstraint {
 	o.proplock.Lock()
 	defer o.proplock.Unlock()
 
 	o.patternProperties[key] = c
+	o.resetPropList()
 	return o
 }
 
@@ -104,6 +162,99 @@ func (o *ObjectConstraint) PatternPropertiesString(key string, c Constraint) *Ob
 	return o.PatternProperties(rx, c)
 }
 
+// GetRequired returns the sorted list of required property names
+func (o *ObjectConstraint) GetRequired() []string {
+	o.reqlock.Lock()
+	defer o.reqlock.Unlock()
+
+	l := make([]string, 0, len(o.required))
+	for pname := range o.required {
+		l = append(l, pname)
+	}
+	sort.Strings(l)
+	return l
+}
+
+// GetReadOnly returns the sorted list of readOnly property names
+func (o *ObjectConstraint) GetReadOnly() []string {
+	o.reqlock.Lock()
+	defer o.reqlock.Unlock()
+
+	return sortedNames(o.readOnly)
+}
+
+// GetWriteOnly returns the sorted list of writeOnly property names
+func (o *ObjectConstraint) GetWriteOnly() []string {
+	o.reqlock.Lock()
+	defer o.reqlock.Unlock()
+
+	return sortedNames(o.writeOnly)
+}
+
+func sortedNames(m map[string]struct{}) []string {
+	l := make([]string, 0, len(m))
+	for pname := range m {
+		l = append(l, pname)
+	}
+	sort.Strings(l)
+	return l
+}
+
+// GetMinProperties returns the minimum number of properties, or -1 if
+// unspecified
+func (o *ObjectConstraint) GetMinProperties() int64 {
+	return o.minProperties
+}
+
+// GetMaxProperties returns the maximum number of properties, or -1 if
+// unspecified
+func (o *ObjectConstraint) GetMaxProperties() int64 {
+	return o.maxProperties
+}
+
+// GetAdditionalProperties returns the constraint that additional
+// properties are validated against, or nil if additional properties
+// are not allowed
+func (o *ObjectConstraint) GetAdditionalProperties() Constraint {
+	return o.additionalProperties
+}
+
+// GetPropNames returns the sorted list of names of the properties
+// added using AddProp
+func (o *ObjectConstraint) GetPropNames() []string {
+	o.proplock.Lock()
+	defer o.proplock.Unlock()
+
+	l := make([]string, 0, len(o.properties))
+	for pname := range o.properties {
+		l = append(l, pname)
+	}
+	sort.Strings(l)
+	return l
+}
+
+// GetProp returns the constraint for the named property, or nil if
+// there is none
+func (o *ObjectConstraint) GetProp(name string) Constraint {
+	o.proplock.Lock()
+	defer o.proplock.Unlock()
+
+	return o.properties[name]
+}
+
+// GetPatternProperties returns a copy of the constraints for
+// properties matching patterns
+func (o *ObjectConstraint) GetPatternProperties() map[*regexp.Regexp]Constraint {
+	o.proplock.Lock()
+	defer o.proplock.Unlock()
+
+	m := make(map[*regexp.Regexp]Constraint, len(o.patternProperties))
+	for rx, c := range o.patternProperties {
+		m[rx] = c
+	}
+	return m
+}
+
 // PropDependency specifies properties that must be present when
 // `from` is present.
 func (o *ObjectConstraint) PropDependency(from string, to ...string) *ObjectConstraint {
@@ -141,6 +292,34 @@ func (o *ObjectConstraint) GetPropDependencies(from string) []string {
 	return l
 }
 
+// GetPropDependencyNames returns the sorted list of property names
+// that have property dependencies
+func (o *ObjectConstraint) GetPropDependencyNames() []string {
+	o.deplock.Lock()
+	defer o.deplock.Unlock()
+
+	l := make([]string, 0, len(o.propdeps))
+	for from := range o.propdeps {
+		l = append(l, from)
+	}
+	sort.Strings(l)
+	return l
+}
+
+// GetSchemaDependencyNames returns the sorted list of property names
+// that have schema dependencies
+func (o *ObjectConstraint) GetSchemaDependencyNames() []string {
+	o.deplock.Lock()
+	defer o.deplock.Unlock()
+
+	l := make([]string, 0, len(o.schemadeps))
+	for from := range o.schemadeps {
+		l = append(l, from)
+	}
+	sort.Strings(l)
+	return l
+}
+
 // GetSchemaDependency returns the Constraint that must be used when
 // the property `from` is present.
 func (o *ObjectConstraint) GetSchemaDependency(from string) Constraint {
@@ -156,7 +335,15 @@ func (o *ObjectConstraint) GetSchemaDependency(from string) Constraint {
 }
 
 var structInfoRegistry = StructInfoRegistry{
-	registry: make(map[reflect.Type]StructInfo),
+	registry: make(map[reflect.Type]*StructInfo),
+}
+
+func lookupStructInfo(t reflect.Type) *StructInfo {
+	si, ok := structInfoRegistry.LookupPtr(t)
+	if !ok {
+		si = structInfoRegistry.RegisterPtr(t)
+	}
+	return si
 }
 
 // getProps return all of the property names for this object.
@@ -174,25 +361,26 @@ func (o *ObjectConstraint) getPropNames(rv reflect.Value) ([]string, error) {
 		vk := rv.MapKeys()
 		keys = make([]string, len(vk))
 		for i, v := range vk {
+			// YAML decoders may produce maps keyed by interface{},
+			// which is fine as long as the keys are strings
+			if v.Kind() == reflect.Interface {
+				v = v.Elem()
+			}
 			if v.Kind() != reflect.String {
 				return nil, errors.New("error: can only handle maps with string keys")
 			}
 			keys[i] = v.String()
 		}
 	case reflect.Struct:
-		if gpv, ok := rv.Interface().(getPropNameser); ok {
+		if rv.Type().Implements(gpnType) {
+			gpv := rv.Interface().(getPropNameser)
 			pv, err := gpv.GetPropNames()
 			if err == nil {
 				return pv, nil
 			}
 		}
 
-		si, ok := structInfoRegistry.Lookup(rv.Type())
-		if !ok {
-			si = structInfoRegistry.Register(rv.Type())
-		}
-
-		return si.PropNames(rv), nil
+		return lookupStructInfo(rv.Type()).PropNames(rv), nil
 	default:
 		return nil, errors.New("cannot get property names from this value (Kind: " + rv.Kind().String() + ")")
 	}
@@ -296,42 +484,118 @@ func (o *ObjectConstraint) getProp(rv reflect.Value, pname string) reflect.Value
 		defer g.End()
 	}
 
+	return getPropValue(rv, pname)
+}
+
+// getPropValue returns the value of the property pname of the map or
+// struct rv, or the zero reflect.Value if it does not exist
+func getPropValue(rv reflect.Value, pname string) reflect.Value {
 	switch rv.Kind() {
 	case reflect.Map:
 		if pdebug.Enabled {
-		pdebug.Printf("%#v", rv.Interface())
+			pdebug.Printf("%#v", rv.Interface())
 			pdebug.Printf("Looking up map index")
 		}
 		return rv.MapIndex(reflect.ValueOf(pname))
 	case reflect.Struct:
 		// This guy knows how to grab the value, given a name. Use that
-		if gpv, ok := rv.Interface().(getPropValuer); ok {
+		if rv.Type().Implements(gpvType) {
+			gpv := rv.Interface().(getPropValuer)
 			pv, err := gpv.GetPropValue(pname)
 			if err == nil {
 				return reflect.ValueOf(pv)
 			}
 		}
 
-		si, ok := structInfoRegistry.Lookup(rv.Type())
-		if !ok {
-			si = structInfoRegistry.Register(rv.Type())
-		}
-
-		fn, ok := si.FieldName(pname)
-		if !ok {
+		fv := lookupStructInfo(rv.Type()).Field(rv, pname)
+		if fv == zeroval {
 			if pdebug.Enabled {
 				pdebug.Printf("Could not resolve name '%s'", pname)
 			}
-			return zeroval
 		}
-		return rv.FieldByName(fn)
+		return fv
 	default:
 		return zeroval
 	}
 }
 
+// objectProp is a property definition, as consumed by the validation
+type objectProp struct {
+	name       string
+	constraint Constraint
+	required   bool
+	readOnly   bool
+	writeOnly  bool
+}
+
+// excluded returns true if the property must not appear in values
+// validated in the given direction
+func (p *objectProp) excluded(d Direction) bool {
+	switch d {
+	case DirectionRequest:
+		return p.readOnly
+	case DirectionResponse:
+		return p.writeOnly
+	}
+	return false
+}
+
+// propList returns the list of property definitions, sorted by name.
+// The list is computed once, and reused until the properties change
+func (o *ObjectConstraint) propList() []objectProp {
+	o.proplock.Lock()
+	if l := o.proplist; l != nil {
+		o.proplock.Unlock()
+		return l
+	}
+	gen := o.propgen
+	l := make([]objectProp, 0, len(o.properties))
+	for pname, c := range o.properties {
+		l = append(l, objectProp{name: pname, constraint: c})
+	}
+	o.proplock.Unlock()
+
+	o.reqlock.Lock()
+	for i := range l {
+		_, l[i].required = o.required[l[i].name]
+		_, l[i].readOnly = o.readOnly[l[i].name]
+		_, l[i].writeOnly = o.writeOnly[l[i].name]
+	}
+	o.reqlock.Unlock()
+
+	sort.Slice(l, func(i, j int) bool { return l[i].name < l[j].name })
+
+	// The list is only kept if no property changed in the meantime
+	o.proplock.Lock()
+	if o.propgen == gen {
+		o.proplist = l
+	}
+	o.proplock.Unlock()
+	return l
+}
+
+// invalidate discards the list of property definitions computed by
+// propList, as it no longer reflects the constraint
+func (o *ObjectConstraint) invalidate() {
+	o.proplock.Lock()
+	o.resetPropList()
+	o.proplock.Unlock()
+}
+
+// resetPropList is the same as invalidate, but must be called with
+// proplock held
+func (o *ObjectConstraint) resetPropList() {
+	o.proplist = nil
+	o.propgen++
+}
+
 // Validate validates the given value against this ObjectConstraint
-func (o *ObjectConstraint) Validate(v interface{}) (err error) {
+func (o *ObjectConstraint) Validate(v interface{}) error {
+	return o.ValidateContext(context.Background(), v)
+}
+
+// ValidateContext is the same as Validate, but honors the given context
+func (o *ObjectConstraint) ValidateContext(ctx context.Context, v interface{}) (err error) {
 	if pdebug.Enabled {
 		g := pdebug.Marker("ObjectConstraint.Validate").BindError(&err)
 		defer g.End()
@@ -348,8 +612,9 @@ func (o *ObjectConstraint) Validate(v interface{}) (err error) {
 		return errors.Wrap(err, `failed to fetch property names for target`)
 	}
 
+	partial := isPartial(ctx)
 	lf := int64(len(fields))
-	if o.minProperties > -1 && lf < o.minProperties {
+	if o.minProperties > -1 && lf < o.minProperties && !partial {
 		return errors.New("fewer properties than minProperties")
 	}
 	if o.maxProperties > -1 && lf > o.maxProperties {
@@ -358,9 +623,7 @@ func (o *ObjectConstraint) Validate(v interface{}) (err error) {
 
 	// Find the list of field names that were passed to us
 	// "premain" shows extra props, if any.
-	// "pseen" shows props that we have already seen
 	premain := map[string]struct{}{}
-	pseen := map[string]struct{}{}
 	for _, k := range fields {
 		premain[k] = struct{}{}
 	}
@@ -369,16 +632,16 @@ func (o *ObjectConstraint) Validate(v interface{}) (err error) {
 		pdebug.Printf("%d properties to be checked", len(premain))
 	}
 
-	// Now, for all known constraints, validate the prop
-	// create a copy of properties so that we don't have to keep the lock
-	propdefs := make(map[string]Constraint)
-	o.proplock.Lock()
-	for pname, c := range o.properties {
-		propdefs[pname] = c
-	}
-	o.proplock.Unlock()
-
-	for pname, c := range propdefs {
+	dir := DirectionAny
+	for _, prop := range o.propList() {
+		pname, c := prop.name, prop.constraint
+		excluded := false
+		if prop.readOnly || prop.writeOnly {
+			if dir == DirectionAny {
+				dir = directionFromContext(ctx)
+			}
+			excluded = prop.excluded(dir)
+		}
 		if pdebug.Enabled {
 			pdebug.Printf("Validating property '%s'", pname)
 		}
@@ -389,18 +652,16 @@ func (o *ObjectConstraint) Validate(v interface{}) (err error) {
 		switch {
 		case pval == zeroval:
 			// If we got a zeroval, we're done for.
-		case pval.Type().Implements(maybeif) || reflect.PtrTo(pval.Type()).Implements(maybeif):
+		default:
 			// If we have a Maybe value, we check the Valid() flag
-			mv := pval.MethodByName("Valid")
-			out := mv.Call(nil)
-			if out[0].Bool() {
-				propExists = true
-				// Swap out pval to be the value pointed to by the Maybe value
-				mv = pval.MethodByName("Value")
-				out = mv.Call(nil)
-				pval = out[0]
+			if mv, ok := maybeValue(pval); ok {
+				if mv.Valid() {
+					propExists = true
+					// Swap out pval to be the value pointed to by the Maybe value
+					pval = reflect.ValueOf(mv.Value())
+				}
+				break
 			}
-		default:
 			// Everything else, we have *something*
 			propExists = true
 		}
@@ -410,7 +671,14 @@ func (o *ObjectConstraint) Validate(v interface{}) (err error) {
 				pdebug.Printf("Property '%s' does not exist", pname)
 			}
 
-			if o.IsPropRequired(pname) { // required, and not present.
+			if excluded || partial {
+				// Not expected in this direction, or left out of a
+				// partial value, so neither required nor filled in
+				// with a default
+				continue
+			}
+
+			if prop.required { // required, and not present.
 				return errors.New("object property '" + pname + "' is required")
 			}
 
@@ -432,38 +700,50 @@ func (o *ObjectConstraint) Validate(v interface{}) (err error) {
 			continue
 		}
 
+		if excluded {
+			if dir == DirectionRequest {
+				return errors.New("object property '" + pname + "' is read-only and must not be sent in requests")
+			}
+			return errors.New("object property '" + pname + "' is write-only and must not be sent in responses")
+		}
+
 		// delete from remaining props
 		delete(premain, pname)
-		// ...and add to props that we have seen
-		pseen[pname] = struct{}{}
 
-		if err := c.Validate(pval.Interface()); err != nil {
-			return errors.New("object property '" + pname + "' validation failed: " + err.Error())
+		if err := validateChild(ctx, pname, c, pval.Interface()); err != nil {
+			return errors.Wrap(err, "object property '"+pname+"' validation failed")
 		}
 	}
 
-	if err := o.validatePatternProperties(rv, premain, pseen); err != nil {
+	if err := o.validatePatternProperties(ctx, rv, fields, premain); err != nil {
 		return errors.Wrap(err, `failed to validate pattern properties`)
 	}
 
-	if err := o.validateAdditionalProperties(rv, premain); err != nil {
+	if err := o.validateAdditionalProperties(ctx, rv, premain); err != nil {
 		return errors.Wrap(err, `failed to validate against additional properties`)
 	}
 
-	if err := o.validateDependencies(rv, pseen); err != nil {
+	if err := o.validateDependencies(ctx, rv, fields); err != nil {
 		return errors.Wrap(err, `failed to validate dependecies`)
 	}
 
 	return nil
 }
 
-func (o *ObjectConstraint) validateDependencies(rv reflect.Value, pseen map[string]struct{}) (err error) {
+func (o *ObjectConstraint) validateDependencies(ctx context.Context, rv reflect.Value, fields []string) (err error) {
 	if pdebug.Enabled {
 		g := pdebug.Marker("ObjectConstraint.validateDependencies").BindError(&err)
 		defer g.End()
 	}
 
-	for pname := range pseen {
+	// Dependencies apply to every property that is present, whether it
+	// has a definition or not
+	pseen := make(map[string]struct{}, len(fields))
+	for _, pname := range fields {
+		pseen[pname] = struct{}{}
+	}
+
+	for _, pname := range fields {
 		if pdebug.Enabled {
 			pdebug.Printf("Checking property %s", pname)
 		}
@@ -483,7 +763,7 @@ func (o *ObjectConstraint) validateDependencies(rv reflect.Value, pseen map[stri
 		}
 
 		if depc := o.GetSchemaDependency(pname); depc != nil {
-			if err := depc.Validate(rv.Interface()); err != nil {
+			if err := validateContext(ctx, depc, rv.Interface()); err != nil {
 				return err
 			}
 		}
@@ -492,7 +772,7 @@ func (o *ObjectConstraint) validateDependencies(rv reflect.Value, pseen map[stri
 	return nil
 }
 
-func (o *ObjectConstraint) validateAdditionalProperties(rv reflect.Value, premain map[string]struct{}) (err error) {
+func (o *ObjectConstraint) validateAdditionalProperties(ctx context.Context, rv reflect.Value, premain map[string]struct{}) (err error) {
 	if pdebug.Enabled {
 		g := pdebug.Marker("ObjectConstraint.validateAdditionalProperties").BindError(&err)
 		defer g.End()
@@ -529,23 +809,28 @@ func (o *ObjectConstraint) validateAdditionalProperties(rv reflect.Value, premai
 			pdebug.Printf("Property '%s' needs to be validated", pname)
 		}
 		pval := o.getProp(rv, pname)
-		if err := c.Validate(pval.Interface()); err != nil {
-			return errors.New("object property for '" + pname + "' validation failed: " + err.Error())
+		if err := validateChild(ctx, pname, c, pval.Interface()); err != nil {
+			return errors.Wrap(err, "object property for '"+pname+"' validation failed")
 		}
 	}
 	return nil
 }
 
-func (o *ObjectConstraint) validatePatternProperties(rv reflect.Value, premain map[string]struct{}, pseen map[string]struct{}) (err error) {
+func (o *ObjectConstraint) validatePatternProperties(ctx context.Context, rv reflect.Value, fields []string, premain map[string]struct{}) (err error) {
 	if pdebug.Enabled {
 		g := pdebug.Marker("ObjectConstraint.validatePatternProperties").BindError(&err)
 		defer g.End()
 	}
+
+	// Every property that matches a pattern is validated against it,
+	// including properties that have their own definition, and
+	// properties that match several patterns
+	matched := map[string]struct{}{}
 	for pat, c := range o.patternProperties {
 		if pdebug.Enabled {
 			pdebug.Printf("Checking patternProperty '%s'", pat.String())
 		}
-		for pname := range premain {
+		for _, pname := range fields {
 			if !pat.MatchString(pname) {
 				if pdebug.Enabled {
 					pdebug.Printf("Property '%s' does not match pattern...", pname)
@@ -555,17 +840,20 @@ func (o *ObjectConstraint) validatePatternProperties(rv reflect.Value, premain m
 			if pdebug.Enabled {
 				pdebug.Printf("Property '%s' matches!", pname)
 			}
-			// No need to check if this pname exists, as we're taking
-			// this from "premain"
 			pval := o.getProp(rv, pname)
+			if pval == zeroval {
+				continue
+			}
 
-			delete(premain, pname)
-			pseen[pname] = struct{}{}
-			if err := c.Validate(pval.Interface()); err != nil {
-				return errors.New("object property '" + pname + "' validation failed: " + err.Error())
+			matched[pname] = struct{}{}
+			if err := validateChild(ctx, pname, c, pval.Interface()); err != nil {
+				return errors.Wrap(err, "object property '"+pname+"' validation failed")
 			}
 		}
 	}
 
+	for pname := range matched {
+		delete(premain, pname)
+	}
 	return nil
 }
@@ -1,12 +1,15 @@
 package validator_test
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/go-json-schema/schema"
 	"github.com/go-json-schema/schema/draft04"
+	"github.com/go-json-schema/validator"
 	"github.com/go-json-schema/validator/builder"
 	"github.com/stretchr/testify/assert"
 )
@@ -125,6 +128,38 @@ func TestObjectDependency(t *testing.T) {
 	}
 }
 
+func TestObjectPatternProperties(t *testing.T) {
+	v := validator.New().SetRoot(validator.Object().
+		AddProp("foo", validator.String()).
+		PatternPropertiesString("^f", validator.String().MaxLength(3)).
+		PatternPropertiesString("o$", validator.String().MinLength(2)).
+		PropDependency("quux", "foo").
+		AdditionalProperties(validator.EmptyConstraint))
+
+	data := map[string]struct {
+		value map[string]interface{}
+		valid bool
+	}{
+		"matches both patterns":             {map[string]interface{}{"fro": "ab"}, true},
+		"fails the second pattern":          {map[string]interface{}{"fro": "a"}, false},
+		"defined property fails a pattern":  {map[string]interface{}{"foo": "abcd"}, false},
+		"dependency of undefined property":  {map[string]interface{}{"quux": true}, false},
+		"dependency of undefined satisfied": {map[string]interface{}{"quux": true, "foo": "ab"}, true},
+	}
+	for name, d := range data {
+		err := v.Validate(d.value)
+		if d.valid {
+			if !assert.NoError(t, err, "%s: validation passes", name) {
+				return
+			}
+		} else {
+			if !assert.Error(t, err, "%s: validation fails", name) {
+				return
+			}
+		}
+	}
+}
+
 func TestObjectSchemaDependency(t *testing.T) {
 	const src = `{
   "type": "object",
@@ -190,3 +225,279 @@ func TestObjectSchemaDependency(t *testing.T) {
 		})
 	}
 }
+
+func TestObjectModify(t *testing.T) {
+	o := validator.Object().AddProp(`name`, validator.String()).AdditionalProperties(validator.EmptyConstraint)
+	v := validator.New().SetRoot(o)
+
+	m := map[string]interface{}{"name": "John Doe", "age": "thirty"}
+	if !assert.NoError(t, v.Validate(m), "validation passes") {
+		return
+	}
+
+	// Properties added after the first validation are not ignored
+	o.AddProp(`age`, validator.Integer())
+	if !assert.Error(t, v.Validate(m), "added property is validated") {
+		return
+	}
+
+	delete(m, "age")
+	if !assert.NoError(t, v.Validate(m), "validation passes") {
+		return
+	}
+	o.Required(`age`)
+	if !assert.Error(t, v.Validate(m), "required property is checked") {
+		return
+	}
+}
+
+func TestObjectConcurrent(t *testing.T) {
+	type Address struct {
+		Street string `json:"street"`
+	}
+	type Person struct {
+		Name    string  `json:"name"`
+		Address Address `json:"address"`
+	}
+
+	o := validator.Object().
+		AddProp(`name`, validator.String().MaxLength(20)).
+		AddProp(`address`, validator.Object().AddProp(`street`, validator.String().MinLength(1)).Required(`street`)).
+		Required(`name`, `address`)
+	v := validator.New().SetRoot(o)
+	p := &Person{Name: "John Doe", Address: Address{Street: "1 Infinite Loop"}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if err := v.Validate(p); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	// Modifying the constraint discards the cached property list while
+	// other goroutines are using it
+	for i := 0; i < 100; i++ {
+		o.AddProp(fmt.Sprintf(`extra%d`, i), validator.EmptyConstraint)
+	}
+	wg.Wait()
+}
+
+func TestObjectGetters(t *testing.T) {
+	name := validator.String()
+	c := validator.Object().
+		AddProp("name", name).
+		AddProp("age", validator.Integer()).
+		PatternPropertiesString("^x-", validator.String()).
+		Required("name").
+		MinProperties(1).
+		MaxProperties(5)
+
+	if !assert.Equal(t, []string{"age", "name"}, c.GetPropNames(), "property names match") {
+		return
+	}
+	if !assert.Equal(t, name, c.GetProp("name"), "property matches") {
+		return
+	}
+	if !assert.Nil(t, c.GetProp("unknown"), "unknown property is nil") {
+		return
+	}
+	if !assert.Equal(t, []string{"name"}, c.GetRequired(), "required properties match") {
+		return
+	}
+	if !assert.Equal(t, []int64{1, 5}, []int64{c.GetMinProperties(), c.GetMaxProperties()}, "limits match") {
+		return
+	}
+	if !assert.Len(t, c.GetPatternProperties(), 1, "pattern properties match") {
+		return
+	}
+	if !assert.Nil(t, c.GetAdditionalProperties(), "additional properties are not allowed") {
+		return
+	}
+}
+
+func TestObjectDirection(t *testing.T) {
+	const src = `{
+  "type": "object",
+  "required": ["id", "name", "password"],
+  "properties": {
+    "id": { "type": "integer", "readOnly": true },
+    "name": { "type": "string" },
+    "password": { "type": "string", "writeOnly": true }
+  }
+}`
+
+	s, err := schema.Parse(strings.NewReader(src), schema.WithSchemaID(draft04.SchemaID))
+	if !assert.NoError(t, err, "reading schema should succeed") {
+		return
+	}
+
+	v, err := builder.New().Build(s)
+	if !assert.NoError(t, err, "Builder.Build should succeed") {
+		return
+	}
+
+	o, ok := v.Root().(*validator.ObjectConstraint)
+	if !assert.True(t, ok, "root is an object") {
+		return
+	}
+	if !assert.Equal(t, []string{"id"}, o.GetReadOnly(), "readOnly properties match") {
+		return
+	}
+	if !assert.Equal(t, []string{"password"}, o.GetWriteOnly(), "writeOnly properties match") {
+		return
+	}
+
+	full := map[string]interface{}{"id": 1, "name": "Tama", "password": "s3cr3t"}
+	request := map[string]interface{}{"name": "Tama", "password": "s3cr3t"}
+	response := map[string]interface{}{"id": 1, "name": "Tama"}
+
+	data := []struct {
+		dir   validator.Direction
+		value map[string]interface{}
+		valid bool
+	}{
+		{validator.DirectionAny, full, true},
+		{validator.DirectionAny, request, false},
+		{validator.DirectionAny, response, false},
+		{validator.DirectionRequest, request, true},
+		{validator.DirectionRequest, full, false},
+		{validator.DirectionRequest, map[string]interface{}{"name": "Tama"}, false},
+		{validator.DirectionResponse, response, true},
+		{validator.DirectionResponse, full, false},
+		{validator.DirectionResponse, map[string]interface{}{"name": "Tama"}, false},
+	}
+	for _, d := range data {
+		err := v.ValidateContext(validator.WithDirection(context.Background(), d.dir), d.value)
+		if d.valid {
+			if !assert.NoError(t, err, "%#v should be valid in %s", d.value, d.dir) {
+				return
+			}
+		} else {
+			if !assert.Error(t, err, "%#v should be invalid in %s", d.value, d.dir) {
+				return
+			}
+		}
+	}
+
+	err = v.ValidateContext(validator.WithDirection(context.Background(), validator.DirectionRequest), full)
+	if !assert.Contains(t, err.Error(), "object property 'id' is read-only", "error names the property") {
+		return
+	}
+
+	// The direction of the validator is used, unless the context
+	// specifies one
+	v.SetDirection(validator.DirectionRequest)
+	if !assert.NoError(t, v.Validate(request), "validator direction is used") {
+		return
+	}
+	if !assert.NoError(t, v.ValidateContext(validator.WithDirection(context.Background(), validator.DirectionResponse), response), "context direction wins") {
+		return
+	}
+
+	// Access modes of nested properties are found as well, and must
+	// be booleans
+	s, err = schema.Parse(strings.NewReader(`{ "type": "array", "items": { "type": "object", "properties": { "id": { "type": "integer", "readOnly": true } } } }`), schema.WithSchemaID(draft04.SchemaID))
+	if !assert.NoError(t, err, "reading schema should succeed") {
+		return
+	}
+	v, err = builder.New().Build(s)
+	if !assert.NoError(t, err, "Builder.Build should succeed") {
+		return
+	}
+	if !assert.Error(t, v.ValidateContext(validator.WithDirection(context.Background(), validator.DirectionRequest), []interface{}{map[string]interface{}{"id": 1}}), "nested readOnly property is rejected in requests") {
+		return
+	}
+
+	s, err = schema.Parse(strings.NewReader(`{ "type": "object", "properties": { "id": { "type": "integer", "readOnly": "yes" } } }`), schema.WithSchemaID(draft04.SchemaID))
+	if !assert.NoError(t, err, "reading schema should succeed") {
+		return
+	}
+	if _, err := builder.New().Build(s); !assert.Error(t, err, "readOnly must be a boolean") {
+		return
+	}
+}
+
+func TestObjectDirectionAfterValidation(t *testing.T) {
+	o := validator.Object().AddProp("id", validator.Integer())
+	v := validator.New().SetRoot(o)
+	ctx := validator.WithDirection(context.Background(), validator.DirectionRequest)
+	value := map[string]interface{}{"id": 1}
+
+	if !assert.NoError(t, v.ValidateContext(ctx, value), "id is accepted before it is marked readOnly") {
+		return
+	}
+	o.ReadOnly("id")
+	if !assert.Error(t, v.ValidateContext(ctx, value), "id is rejected once it is marked readOnly") {
+		return
+	}
+}
+
+func TestObjectPartial(t *testing.T) {
+	v := validator.New().SetRoot(validator.Object().
+		AddProp("name", validator.String().MinLength(1)).
+		AddProp("owner", validator.Object().
+			AddProp("email", validator.String()).
+			AddProp("phone", validator.String()).
+			Required("email").
+			MinProperties(2)).
+		AddProp("kind", validator.String().Default("cat")).
+		Required("name", "owner"))
+
+	ctx := validator.WithPartial(context.Background())
+	data := []struct {
+		value map[string]interface{}
+		valid bool
+	}{
+		{map[string]interface{}{}, true},
+		{map[string]interface{}{"owner": map[string]interface{}{"phone": "555"}}, true},
+		{map[string]interface{}{"name": ""}, false},
+		{map[string]interface{}{"owner": map[string]interface{}{"email": 1}}, false},
+	}
+	for _, d := range data {
+		err := v.ValidateContext(ctx, d.value)
+		if d.valid {
+			if !assert.NoError(t, err, "%#v should be a valid partial value", d.value) {
+				return
+			}
+		} else {
+			if !assert.Error(t, err, "%#v should be an invalid partial value", d.value) {
+				return
+			}
+		}
+	}
+
+	value := map[string]interface{}{}
+	if !assert.NoError(t, v.ValidateContext(ctx, value), "partial value is valid") {
+		return
+	}
+	if !assert.Empty(t, value, "defaults are not filled in") {
+		return
+	}
+	if !assert.Error(t, v.Validate(value), "full validation still checks required") {
+		return
+	}
+}
+
+func TestObjectInterfaceKeys(t *testing.T) {
+	v := validator.New().SetRoot(validator.Object().
+		AddProp("name", validator.String()).
+		Required("name"))
+
+	// As decoded from YAML
+	if !assert.NoError(t, v.Validate(map[interface{}]interface{}{"name": "Tama"}), "string keys are accepted") {
+		return
+	}
+	if !assert.Error(t, v.Validate(map[interface{}]interface{}{"name": 1}), "values are validated") {
+		return
+	}
+	if !assert.Error(t, v.Validate(map[interface{}]interface{}{1: "Tama"}), "non-string keys are rejected") {
+		return
+	}
+}
@@ -0,0 +1,137 @@
+package openapi
+
+// schemaLists are the keywords whose values are lists of schemas
+var schemaLists = []string{"allOf", "anyOf", "oneOf"}
+
+// schemaMaps are the keywords whose values are maps of schemas
+var schemaMaps = []string{"properties", "patternProperties", "definitions"}
+
+// schemaValues are the keywords whose values are schemas
+var schemaValues = []string{"not", "additionalProperties", "additionalItems"}
+
+// convertSchema rewrites an OpenAPI schema object into the draft-04
+// dialect understood by the builder. The input is not modified.
+//
+// For OpenAPI 3.0, "nullable" is turned into an anyOf that also
+// accepts null. For OpenAPI 3.1, which uses JSON Schema 2020-12,
+// numeric exclusiveMinimum/exclusiveMaximum are turned into their
+// draft-04 boolean form, "const" into a single valued enum, and
+// boolean schemas into their object equivalents.
+//
+// readOnly and writeOnly are kept on the converted schema, so that the
+// builder can record them. Components are shared by requests and
+// responses, and validators apply them according to their direction
+func convertSchema(x interface{}, version string) interface{} {
+	switch x := x.(type) {
+	case bool:
+		if x {
+			return map[string]interface{}{}
+		}
+		return map[string]interface{}{"not": map[string]interface{}{}}
+	case map[string]interface{}:
+		return convertSchemaObject(x, version)
+	}
+	return x
+}
+
+func convertSchemaObject(s map[string]interface{}, version string) interface{} {
+	out := make(map[string]interface{}, len(s))
+	for key, val := range s {
+		out[key] = val
+	}
+
+	for _, key := range schemaLists {
+		if l, ok := out[key].([]interface{}); ok {
+			converted := make([]interface{}, len(l))
+			for i, s1 := range l {
+				converted[i] = convertSchema(s1, version)
+			}
+			out[key] = converted
+		}
+	}
+	for _, key := range schemaMaps {
+		if m, ok := out[key].(map[string]interface{}); ok {
+			converted := make(map[string]interface{}, len(m))
+			for name, s1 := range m {
+				converted[name] = convertSchema(s1, version)
+			}
+			out[key] = converted
+		}
+	}
+	for _, key := range schemaValues {
+		if s1, ok := out[key]; ok {
+			if _, isBool := s1.(bool); isBool && key != "not" {
+				// draft-04 accepts booleans for these
+				continue
+			}
+			out[key] = convertSchema(s1, version)
+		}
+	}
+	switch items := out["items"].(type) {
+	case []interface{}:
+		converted := make([]interface{}, len(items))
+		for i, s1 := range items {
+			converted[i] = convertSchema(s1, version)
+		}
+		out["items"] = converted
+	case map[string]interface{}, bool:
+		out["items"] = convertSchema(items, version)
+	}
+
+	if version == version31 {
+		convertExclusive(out, "exclusiveMinimum", "minimum", func(a, b float64) bool { return a >= b })
+		convertExclusive(out, "exclusiveMaximum", "maximum", func(a, b float64) bool { return a <= b })
+		if v, ok := out["const"]; ok {
+			delete(out, "const")
+			out["enum"] = []interface{}{v}
+		}
+		return out
+	}
+
+	if nullable, _ := out["nullable"].(bool); nullable {
+		delete(out, "nullable")
+		wrapped := map[string]interface{}{
+			"anyOf": []interface{}{out, map[string]interface{}{"type": "null"}},
+		}
+		// The annotations apply to the property, whatever its value
+		for _, key := range []string{"readOnly", "writeOnly"} {
+			if v, ok := out[key]; ok {
+				wrapped[key] = v
+			}
+		}
+		return wrapped
+	}
+	return out
+}
+
+// convertExclusive turns the numeric exclusive limit of JSON Schema
+// 2020-12 into the boolean form of draft-04. stricter reports whether
+// the first inclusive limit is at least as strict as the second,
+// exclusive one, in which case the exclusive limit is redundant
+func convertExclusive(s map[string]interface{}, exclusive, inclusive string, stricter func(float64, float64) bool) {
+	limit, ok := toFloat(s[exclusive])
+	if !ok {
+		return
+	}
+	delete(s, exclusive)
+
+	if current, ok := toFloat(s[inclusive]); ok && current != limit && stricter(current, limit) {
+		return
+	}
+	s[inclusive] = limit
+	s[exclusive] = true
+}
+
+func toFloat(x interface{}) (float64, bool) {
+	switch x := x.(type) {
+	case float64:
+		return x, true
+	case int:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	}
+	return 0, false
+}
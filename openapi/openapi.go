@@ -0,0 +1,481 @@
+// Package openapi builds validators for the operations described by
+// an OpenAPI 3.0 or 3.1 document.
+//
+// Every request body, response body and parameter schema is converted
+// to the draft-04 dialect understood by the builder, and built into a
+// validator. All validators share a single ConstraintMap, in which the
+// schemas under components/schemas are built once.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"sort"
+	"strings"
+
+	"github.com/go-json-schema/schema"
+	"github.com/go-json-schema/schema/draft04"
+	"github.com/go-json-schema/validator"
+	"github.com/go-json-schema/validator/builder"
+	"github.com/go-json-schema/validator/instance"
+	"github.com/lestrrat/go-pdebug"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	version30 = "3.0"
+	version31 = "3.1"
+)
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Document holds the validators for the operations of an OpenAPI
+// document
+type Document struct {
+	// Version is the minor version of OpenAPI the document conforms to,
+	// either "3.0" or "3.1"
+	Version string
+	// Operations maps operation IDs to operations. Operations that do
+	// not have an ID are keyed by their method and path, as in
+	// "GET /pets/{id}"
+	Operations map[string]*Operation
+
+	cm *validator.ConstraintMap
+}
+
+// Operation holds the validators for a single operation
+type Operation struct {
+	ID     string
+	Method string
+	Path   string
+	// Parameters lists the parameters of the operation, including
+	// those inherited from the path item
+	Parameters []*Parameter
+	// RequestBody is nil if the operation does not accept a body
+	RequestBody *Body
+	// Responses maps status codes (including "default" and ranges such
+	// as "2XX") to the responses that have a body
+	Responses map[string]*Body
+}
+
+// Parameter describes a parameter of an operation
+type Parameter struct {
+	Name     string
+	In       string
+	Required bool
+	// Validator validates the decoded value of the parameter. It is nil
+	// if the parameter does not have a schema
+	Validator *validator.JSVal
+}
+
+// Body describes a request or response body
+type Body struct {
+	Required bool
+	// Content maps media types to the validator for bodies of that type
+	Content map[string]*validator.JSVal
+}
+
+// Components returns the ConstraintMap shared by all validators of
+// the document
+func (d *Document) Components() *validator.ConstraintMap {
+	return d.cm
+}
+
+// Operation returns the operation with the given ID, or nil if there
+// is none
+func (d *Document) Operation(id string) *Operation {
+	return d.Operations[id]
+}
+
+// OperationIDs returns the keys of Operations, sorted
+func (d *Document) OperationIDs() []string {
+	l := make([]string, 0, len(d.Operations))
+	for id := range d.Operations {
+		l = append(l, id)
+	}
+	sort.Strings(l)
+	return l
+}
+
+// Parameter returns the parameter with the given name and location
+// ("path", "query", "header" or "cookie"), or nil if there is none
+func (o *Operation) Parameter(in, name string) *Parameter {
+	for _, p := range o.Parameters {
+		if p.In == in && p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Response returns the body of the response for the given status code,
+// falling back to the range of the status code (e.g. "2XX") and then
+// to "default". It returns nil if none of them has a body
+func (o *Operation) Response(status int) *Body {
+	code := fmt.Sprint(status)
+	for _, key := range []string{code, code[:1] + "XX", "default"} {
+		if b, ok := o.Responses[key]; ok {
+			return b
+		}
+	}
+	return nil
+}
+
+// Validator returns the validator for bodies of the given media type.
+// Parameters of the media type are ignored, and when there is no exact
+// match, wildcard entries such as "application/*" and "*/*" are used.
+// It returns nil if the media type is not accepted
+func (b *Body) Validator(mediaType string) *validator.JSVal {
+	if mt, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = mt
+	}
+	if v, ok := b.Content[mediaType]; ok {
+		return v
+	}
+	if i := strings.IndexByte(mediaType, '/'); i > 0 {
+		if v, ok := b.Content[mediaType[:i]+"/*"]; ok {
+			return v
+		}
+	}
+	return b.Content["*/*"]
+}
+
+// Load reads an OpenAPI document in JSON or YAML format from r, and
+// builds the validators for its operations
+func Load(r io.Reader) (*Document, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, `failed to read document`)
+	}
+	return Parse(data)
+}
+
+// LoadFile is the same as Load, but reads the document from path
+func LoadFile(path string) (*Document, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, `failed to read %s`, path)
+	}
+	return Parse(data)
+}
+
+// Parse builds the validators for the operations of the OpenAPI
+// document in data, which may be in JSON or YAML format
+func Parse(data []byte) (doc *Document, err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("openapi.Parse").BindError(&err)
+		defer g.End()
+	}
+
+	root, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := versionOf(root)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := &loadctx{
+		root:    root,
+		version: version,
+		cm:      &validator.ConstraintMap{},
+	}
+	ctx.b = builder.New().ConstraintMap(ctx.cm)
+	ctx.jsctx = ctx.convertComponents()
+
+	doc = &Document{
+		Version:    version,
+		Operations: make(map[string]*Operation),
+		cm:         ctx.cm,
+	}
+
+	paths, _ := root["paths"].(map[string]interface{})
+	for _, path := range sortedKeys(paths) {
+		item, err := ctx.resolve(paths[path])
+		if err != nil {
+			return nil, errors.Wrapf(err, `failed to resolve path %s`, path)
+		}
+
+		for _, method := range methods {
+			op, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			o, err := ctx.buildOperation(strings.ToUpper(method), path, item, op)
+			if err != nil {
+				return nil, errors.Wrapf(err, `failed to build operation %s %s`, strings.ToUpper(method), path)
+			}
+			if _, ok := doc.Operations[o.ID]; ok {
+				return nil, errors.Errorf(`duplicate operation %s`, o.ID)
+			}
+			doc.Operations[o.ID] = o
+		}
+	}
+	return doc, nil
+}
+
+type loadctx struct {
+	root    map[string]interface{}
+	version string
+	cm      *validator.ConstraintMap
+	b       *builder.Builder
+	// jsctx is the document that references are resolved against. It
+	// is the original document, with converted component schemas
+	jsctx map[string]interface{}
+}
+
+func decode(data []byte) (map[string]interface{}, error) {
+	var x interface{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(data, &x); err != nil {
+			return nil, errors.Wrap(err, `failed to decode JSON`)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &x); err != nil {
+			return nil, errors.Wrap(err, `failed to decode YAML`)
+		}
+		var err error
+		if x, err = instance.Normalize(x); err != nil {
+			return nil, errors.Wrap(err, `failed to convert YAML`)
+		}
+	}
+
+	root, ok := x.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("document must be an object")
+	}
+	return root, nil
+}
+
+func versionOf(root map[string]interface{}) (string, error) {
+	v, _ := root["openapi"].(string)
+	switch {
+	case strings.HasPrefix(v, version30+"."):
+		return version30, nil
+	case strings.HasPrefix(v, version31+"."):
+		return version31, nil
+	case v == "":
+		return "", errors.New("not an OpenAPI 3 document (missing openapi field)")
+	}
+	return "", errors.Errorf(`unsupported OpenAPI version %s`, v)
+}
+
+// convertComponents returns a shallow copy of the document, in which
+// the schemas under components/schemas have been converted
+func (ctx *loadctx) convertComponents() map[string]interface{} {
+	jsctx := make(map[string]interface{}, len(ctx.root))
+	for key, val := range ctx.root {
+		jsctx[key] = val
+	}
+
+	components, ok := ctx.root["components"].(map[string]interface{})
+	if !ok {
+		return jsctx
+	}
+	schemas, ok := components["schemas"].(map[string]interface{})
+	if !ok {
+		return jsctx
+	}
+
+	converted := make(map[string]interface{}, len(schemas))
+	for name, s := range schemas {
+		converted[name] = convertSchema(s, ctx.version)
+	}
+	c := make(map[string]interface{}, len(components))
+	for key, val := range components {
+		c[key] = val
+	}
+	c["schemas"] = converted
+	jsctx["components"] = c
+	return jsctx
+}
+
+// resolve follows the reference in x, if any, and returns the object
+// it points to. Only references within the document are supported
+func (ctx *loadctx) resolve(x interface{}) (map[string]interface{}, error) {
+	for i := 0; ; i++ {
+		m, ok := x.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("expected an object")
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return m, nil
+		}
+		if i > 32 {
+			return nil, errors.Errorf(`too many levels of references (%s)`, ref)
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			return nil, errors.Errorf(`unsupported reference %s`, ref)
+		}
+
+		x = ctx.root
+		for _, seg := range strings.Split(ref[2:], "/") {
+			seg = strings.Replace(strings.Replace(seg, "~1", "/", -1), "~0", "~", -1)
+			parent, ok := x.(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf(`reference %s not found`, ref)
+			}
+			if x, ok = parent[seg]; !ok {
+				return nil, errors.Errorf(`reference %s not found`, ref)
+			}
+		}
+	}
+}
+
+func (ctx *loadctx) buildOperation(method, path string, item, op map[string]interface{}) (*Operation, error) {
+	o := &Operation{
+		Method:    method,
+		Path:      path,
+		Responses: make(map[string]*Body),
+	}
+	o.ID, _ = op["operationId"].(string)
+	if o.ID == "" {
+		o.ID = method + " " + path
+	}
+
+	// Parameters of the operation override those of the path item
+	var params []*Parameter
+	for _, src := range []interface{}{item["parameters"], op["parameters"]} {
+		l, _ := src.([]interface{})
+		for _, x := range l {
+			p, err := ctx.buildParameter(o.ID, x)
+			if err != nil {
+				return nil, err
+			}
+			replaced := false
+			for i, prev := range params {
+				if prev.In == p.In && prev.Name == p.Name {
+					params[i] = p
+					replaced = true
+				}
+			}
+			if !replaced {
+				params = append(params, p)
+			}
+		}
+	}
+	o.Parameters = params
+
+	if x, ok := op["requestBody"]; ok {
+		b, err := ctx.buildBody(o.ID+" request body", x, validator.DirectionRequest)
+		if err != nil {
+			return nil, errors.Wrap(err, `failed to build request body`)
+		}
+		o.RequestBody = b
+	}
+
+	responses, _ := op["responses"].(map[string]interface{})
+	for _, status := range sortedKeys(responses) {
+		b, err := ctx.buildBody(o.ID+" response "+status, responses[status], validator.DirectionResponse)
+		if err != nil {
+			return nil, errors.Wrapf(err, `failed to build response %s`, status)
+		}
+		if len(b.Content) > 0 {
+			o.Responses[status] = b
+		}
+	}
+	return o, nil
+}
+
+func (ctx *loadctx) buildParameter(opID string, x interface{}) (*Parameter, error) {
+	m, err := ctx.resolve(x)
+	if err != nil {
+		return nil, errors.Wrap(err, `failed to resolve parameter`)
+	}
+
+	p := &Parameter{}
+	p.Name, _ = m["name"].(string)
+	p.In, _ = m["in"].(string)
+	p.Required, _ = m["required"].(bool)
+	if p.Name == "" || p.In == "" {
+		return nil, errors.New("parameter must have a name and a location")
+	}
+
+	s, ok := m["schema"]
+	if !ok {
+		// Parameters may describe their value using a media type instead
+		content, _ := m["content"].(map[string]interface{})
+		for _, mt := range sortedKeys(content) {
+			if media, ok := content[mt].(map[string]interface{}); ok {
+				s, ok = media["schema"]
+				break
+			}
+		}
+	}
+	if s == nil {
+		return p, nil
+	}
+
+	v, err := ctx.build(opID+" parameter "+p.Name, s, validator.DirectionRequest)
+	if err != nil {
+		return nil, errors.Wrapf(err, `failed to build parameter %s`, p.Name)
+	}
+	p.Validator = v
+	return p, nil
+}
+
+func (ctx *loadctx) buildBody(name string, x interface{}, dir validator.Direction) (*Body, error) {
+	m, err := ctx.resolve(x)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Body{Content: make(map[string]*validator.JSVal)}
+	b.Required, _ = m["required"].(bool)
+
+	content, _ := m["content"].(map[string]interface{})
+	for _, mt := range sortedKeys(content) {
+		media, ok := content[mt].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		s, ok := media["schema"]
+		if !ok {
+			continue
+		}
+		v, err := ctx.build(name+" ("+mt+")", s, dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, `failed to build schema for %s`, mt)
+		}
+		b.Content[mt] = v
+	}
+	return b, nil
+}
+
+// build converts the schema object s, and builds a validator from it
+// that validates values sent in the given direction
+func (ctx *loadctx) build(name string, s interface{}, dir validator.Direction) (*validator.JSVal, error) {
+	buf, err := json.Marshal(convertSchema(s, ctx.version))
+	if err != nil {
+		return nil, errors.Wrap(err, `failed to encode schema`)
+	}
+
+	parsed, err := schema.Parse(bytes.NewReader(buf), schema.WithSchemaID(draft04.SchemaID))
+	if err != nil {
+		return nil, errors.Wrap(err, `failed to parse schema`)
+	}
+
+	v, err := ctx.b.BuildWithCtx(parsed, ctx.jsctx)
+	if err != nil {
+		return nil, err
+	}
+	return v.SetName(name).SetDirection(dir), nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	l := make([]string, 0, len(m))
+	for k := range m {
+		l = append(l, k)
+	}
+	sort.Strings(l)
+	return l
+}
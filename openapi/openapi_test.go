@@ -0,0 +1,272 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const petstore30 = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: 1.0.0
+paths:
+  /pets:
+    post:
+      operationId: createPet
+      requestBody:
+        $ref: '#/components/requestBodies/Pet'
+      responses:
+        201:
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+        default:
+          $ref: '#/components/responses/Error'
+  /pets/{id}:
+    parameters:
+      - $ref: '#/components/parameters/PetID'
+    get:
+      operationId: getPet
+      parameters:
+        - name: fields
+          in: query
+          schema:
+            type: string
+            maxLength: 10
+      responses:
+        200:
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+    delete:
+      responses:
+        204:
+          description: deleted
+components:
+  parameters:
+    PetID:
+      name: id
+      in: path
+      required: true
+      schema:
+        type: integer
+        minimum: 1
+  requestBodies:
+    Pet:
+      required: true
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Pet'
+  responses:
+    Error:
+      description: error
+      content:
+        application/problem+json:
+          schema:
+            type: object
+            properties:
+              title:
+                type: string
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        name:
+          type: string
+        tag:
+          type: string
+          nullable: true
+        age:
+          type: integer
+          minimum: 0
+          exclusiveMinimum: true
+`
+
+func TestParse30(t *testing.T) {
+	doc, err := Parse([]byte(petstore30))
+	if !assert.NoError(t, err, "Parse should succeed") {
+		return
+	}
+	if !assert.Equal(t, "3.0", doc.Version, "version matches") {
+		return
+	}
+	if !assert.Equal(t, []string{"DELETE /pets/{id}", "createPet", "getPet"}, doc.OperationIDs(), "operations match") {
+		return
+	}
+
+	create := doc.Operation("createPet")
+	if !assert.True(t, create.RequestBody.Required, "request body is required") {
+		return
+	}
+	req := create.RequestBody.Validator("application/json; charset=utf-8")
+	if !assert.NotNil(t, req, "request body validator exists") {
+		return
+	}
+
+	valid := []interface{}{
+		map[string]interface{}{"name": "Tama"},
+		map[string]interface{}{"name": "Tama", "tag": nil},
+		map[string]interface{}{"name": "Tama", "age": float64(1)},
+	}
+	for _, x := range valid {
+		if !assert.NoError(t, req.Validate(x), "%#v should be valid", x) {
+			return
+		}
+	}
+
+	invalid := []interface{}{
+		map[string]interface{}{},
+		map[string]interface{}{"name": "Tama", "tag": float64(1)},
+		map[string]interface{}{"name": "Tama", "age": float64(0)},
+		map[string]interface{}{"id": float64(1), "name": "Tama"},
+	}
+	for _, x := range invalid {
+		if !assert.Error(t, req.Validate(x), "%#v should be invalid", x) {
+			return
+		}
+	}
+
+	created := create.Response(201).Validator("application/json")
+	if !assert.NotNil(t, created, "201 response validator exists") {
+		return
+	}
+	if !assert.NoError(t, created.Validate(map[string]interface{}{"id": float64(1), "name": "Tama"}), "readOnly id is accepted in responses") {
+		return
+	}
+	if !assert.Error(t, created.Validate(map[string]interface{}{"name": "Tama"}), "readOnly id is required in responses") {
+		return
+	}
+	errResp := create.Response(500)
+	if !assert.NotNil(t, errResp, "default response is used for 500") {
+		return
+	}
+	if !assert.NotNil(t, errResp.Validator("application/problem+json"), "error response validator exists") {
+		return
+	}
+
+	get := doc.Operation("getPet")
+	if !assert.Len(t, get.Parameters, 2, "path and operation parameters are merged") {
+		return
+	}
+	id := get.Parameter("path", "id")
+	if !assert.NotNil(t, id, "path parameter exists") {
+		return
+	}
+	if !assert.True(t, id.Required, "path parameter is required") {
+		return
+	}
+	if !assert.Error(t, id.Validator.Validate(float64(0)), "id must be positive") {
+		return
+	}
+	if !assert.Error(t, get.Parameter("query", "fields").Validator.Validate("abcdefghijk"), "fields is too long") {
+		return
+	}
+
+	if !assert.Nil(t, doc.Operation("DELETE /pets/{id}").Response(204), "responses without content are omitted") {
+		return
+	}
+
+	// All validators share the constraints built for components
+	resp := get.Response(200).Validator("application/json")
+	if !assert.True(t, req.ConstraintMap == doc.Components() && resp.ConstraintMap == doc.Components(), "validators share the ConstraintMap") {
+		return
+	}
+	if !assert.Len(t, doc.Components().GetReferenceNames(), 1, "Pet is built once") {
+		return
+	}
+}
+
+const petstore31 = `{
+  "openapi": "3.1.0",
+  "info": { "title": "Petstore", "version": "1.0.0" },
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "requestBody": {
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "object",
+                "properties": {
+                  "name": { "type": ["string", "null"] },
+                  "kind": { "const": "pet" },
+                  "age": { "type": "integer", "exclusiveMinimum": 0 },
+                  "extra": false
+                },
+                "additionalProperties": true
+              }
+            }
+          }
+        },
+        "responses": {}
+      }
+    }
+  }
+}`
+
+func TestParse31(t *testing.T) {
+	doc, err := Parse([]byte(petstore31))
+	if !assert.NoError(t, err, "Parse should succeed") {
+		return
+	}
+	if !assert.Equal(t, "3.1", doc.Version, "version matches") {
+		return
+	}
+
+	v := doc.Operation("createPet").RequestBody.Validator("application/json")
+	if !assert.NotNil(t, v, "request body validator exists") {
+		return
+	}
+
+	data := []struct {
+		value interface{}
+		valid bool
+	}{
+		{map[string]interface{}{"name": nil, "kind": "pet", "age": float64(1)}, true},
+		{map[string]interface{}{"kind": "animal"}, false},
+		{map[string]interface{}{"age": float64(0)}, false},
+		{map[string]interface{}{"extra": "x"}, false},
+	}
+	for _, d := range data {
+		err := v.Validate(d.value)
+		if d.valid {
+			if !assert.NoError(t, err, "%#v should be valid", d.value) {
+				return
+			}
+		} else {
+			if !assert.Error(t, err, "%#v should be invalid", d.value) {
+				return
+			}
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	data := map[string]string{
+		"swagger":   `{"swagger": "2.0"}`,
+		"version":   `{"openapi": "4.0.0"}`,
+		"duplicate": `{"openapi": "3.0.0", "paths": {"/a": {"get": {"operationId": "x"}}, "/b": {"get": {"operationId": "x"}}}}`,
+		"reference": `{"openapi": "3.0.0", "paths": {"/a": {"get": {"parameters": [{"$ref": "#/components/parameters/missing"}]}}}}`,
+	}
+	for name, src := range data {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(strings.NewReader(src))
+			if !assert.Error(t, err, "Load should fail") {
+				return
+			}
+		})
+	}
+}
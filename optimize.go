@@ -0,0 +1,343 @@
+package validator
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/lestrrat/go-pdebug"
+)
+
+// Optimize simplifies the constraint tree of the validator (including
+// the constraints registered in the ConstraintMap) without changing
+// which values are accepted:
+//
+//   - nested AllConstraints and AnyConstraints are flattened into
+//     their parents
+//   - EmptyConstraints are removed from AllConstraints, and
+//     AnyConstraints that contain one are replaced by EmptyConstraint
+//   - StringConstraints under the same AllConstraint are merged, as
+//     long as they do not both specify a pattern, format or enum
+//   - duplicate children (including references to the same name) are
+//     removed from AllConstraints and AnyConstraints
+//   - combinations with a single child are replaced by the child
+//   - the children of AllConstraints and AnyConstraints are reordered
+//     so that cheap checks run first
+//
+// Because of the reordering, the error reported for a value that
+// violates several constraints may differ from the one reported by
+// the original tree. Constraints are modified in place, so Optimize
+// should be called once the tree is complete. Optimize returns the
+// same validator, so that it can be chained.
+func (v *JSVal) Optimize() *JSVal {
+	if pdebug.Enabled {
+		g := pdebug.Marker("JSVal.Optimize")
+		defer g.End()
+	}
+
+	o := optimizer{done: make(map[Constraint]Constraint)}
+	if cm := v.ConstraintMap; cm != nil {
+		for _, name := range cm.GetReferenceNames() {
+			c, err := cm.GetReference(name)
+			if err != nil {
+				continue
+			}
+			if oc := o.optimize(c); oc != c {
+				cm.SetReference(name, oc)
+			}
+		}
+	}
+	v.root = o.optimize(v.root)
+	return v
+}
+
+// Optimize simplifies the constraint tree rooted at c, as described in
+// JSVal.Optimize, and returns the new root
+func Optimize(c Constraint) Constraint {
+	o := optimizer{done: make(map[Constraint]Constraint)}
+	return o.optimize(c)
+}
+
+type optimizer struct {
+	// done maps constraints that have been optimized to their
+	// replacement. Constraints being optimized map to themselves,
+	// which stops recursive schemas from being visited forever
+	done map[Constraint]Constraint
+}
+
+func (o *optimizer) optimize(c Constraint) Constraint {
+	if c == nil || !reflect.TypeOf(c).Comparable() {
+		return c
+	}
+	if oc, ok := o.done[c]; ok {
+		return oc
+	}
+	o.done[c] = c
+
+	oc := o.rewrite(c)
+	o.done[c] = oc
+	return oc
+}
+
+func (o *optimizer) rewrite(c Constraint) Constraint {
+	switch c := c.(type) {
+	case *ReferenceConstraint:
+		resolved, err := c.Resolved()
+		if err != nil {
+			return c
+		}
+		if oc := o.optimize(resolved); oc != resolved {
+			c.lock.Lock()
+			c.resolved = oc
+			c.lock.Unlock()
+		}
+	case *NotConstraint:
+		c.child = o.optimize(c.child)
+	case *DiscriminatorConstraint:
+		for _, value := range c.GetMappingValues() {
+			c.Map(value, o.optimize(c.GetMapping(value)))
+		}
+	case *ArrayConstraint:
+		c.items = o.optimize(c.items)
+		for i, item := range c.positionalItems {
+			c.positionalItems[i] = o.optimize(item)
+		}
+		c.additionalItems = o.optimize(c.additionalItems)
+	case *ObjectConstraint:
+		o.rewriteObject(c)
+	case *AllConstraint:
+		return o.rewriteAll(c)
+	case *AnyConstraint:
+		return o.rewriteAny(c)
+	case *OneOfConstraint:
+		// oneOf(a, a) never passes, so duplicates must be kept
+		for i, child := range c.constraints {
+			c.constraints[i] = o.optimize(child)
+		}
+		return reduceCombined(c)
+	}
+	return c
+}
+
+func (o *optimizer) rewriteObject(c *ObjectConstraint) {
+	c.proplock.Lock()
+	props := make(map[string]Constraint, len(c.properties))
+	for name, pc := range c.properties {
+		props[name] = pc
+	}
+	patterns := make(map[*regexp.Regexp]Constraint, len(c.patternProperties))
+	for rx, pc := range c.patternProperties {
+		patterns[rx] = pc
+	}
+	c.proplock.Unlock()
+
+	// Children are optimized without holding the lock, as recursive
+	// schemas may lead back to this very constraint
+	for name, pc := range props {
+		props[name] = o.optimize(pc)
+	}
+	for rx, pc := range patterns {
+		patterns[rx] = o.optimize(pc)
+	}
+
+	c.proplock.Lock()
+	for name, pc := range props {
+		c.properties[name] = pc
+	}
+	for rx, pc := range patterns {
+		c.patternProperties[rx] = pc
+	}
+	c.resetPropList()
+	c.proplock.Unlock()
+
+	for _, name := range c.GetSchemaDependencyNames() {
+		dc := o.optimize(c.GetSchemaDependency(name))
+		c.deplock.Lock()
+		c.schemadeps[name] = dc
+		c.deplock.Unlock()
+	}
+	c.additionalProperties = o.optimize(c.additionalProperties)
+}
+
+func (o *optimizer) rewriteAll(c *AllConstraint) Constraint {
+	var l []Constraint
+	for _, child := range c.constraints {
+		child = o.optimize(child)
+		if ac, ok := child.(*AllConstraint); ok {
+			l = append(l, ac.constraints...)
+			continue
+		}
+		if child == EmptyConstraint {
+			continue
+		}
+		l = append(l, child)
+	}
+
+	l = sortByCost(mergeStrings(dedupe(l)))
+	switch len(l) {
+	case 0:
+		return EmptyConstraint
+	case 1:
+		return l[0]
+	}
+	return &AllConstraint{comboconstraint: comboconstraint{constraints: l}}
+}
+
+func (o *optimizer) rewriteAny(c *AnyConstraint) Constraint {
+	var l []Constraint
+	for _, child := range c.constraints {
+		child = o.optimize(child)
+		if ac, ok := child.(*AnyConstraint); ok {
+			l = append(l, ac.constraints...)
+			continue
+		}
+		if child == EmptyConstraint {
+			// Any value satisfies this branch
+			return EmptyConstraint
+		}
+		l = append(l, child)
+	}
+
+	l = sortByCost(dedupe(l))
+	if len(l) == 1 {
+		return l[0]
+	}
+	return &AnyConstraint{comboconstraint: comboconstraint{constraints: l}}
+}
+
+// refKey identifies the target of a reference
+type refKey struct {
+	resolver  RefResolver
+	reference string
+}
+
+// dedupe removes constraints that appear more than once in l, and
+// references to the same target
+func dedupe(l []Constraint) []Constraint {
+	seen := make(map[interface{}]struct{}, len(l))
+	out := l[:0:0]
+	for _, c := range l {
+		var key interface{} = c
+		if rc, ok := c.(*ReferenceConstraint); ok && (rc.resolver == nil || reflect.TypeOf(rc.resolver).Comparable()) {
+			key = refKey{resolver: rc.resolver, reference: rc.reference}
+		}
+		if reflect.TypeOf(c).Comparable() {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// mergeStrings merges the StringConstraints in l, which are children
+// of the same AllConstraint, into a single StringConstraint. The
+// constraints themselves are not modified, as they may be shared.
+func mergeStrings(l []Constraint) []Constraint {
+	var merged *StringConstraint
+	out := l[:0:0]
+	for _, c := range l {
+		sc, ok := c.(*StringConstraint)
+		if !ok {
+			out = append(out, c)
+			continue
+		}
+		if merged == nil {
+			merged = sc
+			out = append(out, sc)
+			continue
+		}
+
+		m, ok := mergeString(merged, sc)
+		if !ok {
+			out = append(out, sc)
+			continue
+		}
+		for i, x := range out {
+			if x == Constraint(merged) {
+				out[i] = m
+			}
+		}
+		merged = m
+	}
+	return out
+}
+
+// mergeString returns a StringConstraint that accepts exactly the
+// strings that both a and b accept, or false if they can not be
+// expressed as one
+func mergeString(a, b *StringConstraint) (*StringConstraint, bool) {
+	if a.regexp != nil && b.regexp != nil {
+		return nil, false
+	}
+	if a.format != "" && b.format != "" && a.format != b.format {
+		return nil, false
+	}
+	if a.enums != nil && b.enums != nil {
+		return nil, false
+	}
+	if a.HasDefault() && b.HasDefault() {
+		return nil, false
+	}
+
+	m := String()
+	m.defaultValue = a.defaultValue
+	if b.HasDefault() {
+		m.defaultValue = b.defaultValue
+	}
+	m.minLength = a.minLength
+	if b.minLength > m.minLength {
+		m.minLength = b.minLength
+	}
+	m.maxLength = a.maxLength
+	if b.maxLength > -1 && (m.maxLength < 0 || b.maxLength < m.maxLength) {
+		m.maxLength = b.maxLength
+	}
+	m.regexp = a.regexp
+	if b.regexp != nil {
+		m.regexp = b.regexp
+	}
+	m.format = a.format
+	if b.format != "" {
+		m.format = b.format
+	}
+	m.enums = a.enums
+	if b.enums != nil {
+		m.enums = b.enums
+	}
+	return m, true
+}
+
+// cost estimates how expensive validating against c is, so that cheap
+// checks can be run first
+func cost(c Constraint) int {
+	switch c := c.(type) {
+	case *BooleanConstraint, *EnumConstraint:
+		return 1
+	case *IntegerConstraint, *NumberConstraint:
+		return 2
+	case *StringConstraint:
+		if c.regexp != nil || c.format != "" {
+			return 4
+		}
+		return 3
+	case *ArrayConstraint:
+		return 5
+	case *ObjectConstraint:
+		return 6
+	case *NotConstraint:
+		return cost(c.child)
+	case *ReferenceConstraint, *AllConstraint, *AnyConstraint, *OneOfConstraint, *DiscriminatorConstraint:
+		return 7
+	}
+	return 0
+}
+
+func sortByCost(l []Constraint) []Constraint {
+	sort.SliceStable(l, func(i, j int) bool {
+		return cost(l[i]) < cost(l[j])
+	})
+	return l
+}
@@ -0,0 +1,165 @@
+package validator_test
+
+import (
+	"testing"
+
+	"github.com/go-json-schema/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimize(t *testing.T) {
+	t.Run("flatten nested allOf", func(t *testing.T) {
+		c := validator.Optimize(validator.All().
+			Add(validator.All().Add(validator.Integer().Minimum(0)).Add(validator.EmptyConstraint)).
+			Add(validator.All().Add(validator.All().Add(validator.Integer().Maximum(10)))),
+		)
+
+		all, ok := c.(*validator.AllConstraint)
+		if !assert.True(t, ok, "result is an AllConstraint (got %T)", c) {
+			return
+		}
+		if !assert.Len(t, all.Constraints(), 2, "nested constraints are flattened, and empty ones removed") {
+			return
+		}
+	})
+
+	t.Run("anyOf with an empty branch", func(t *testing.T) {
+		c := validator.Optimize(validator.Any().Add(validator.String()).Add(validator.EmptyConstraint))
+		if !assert.Equal(t, validator.EmptyConstraint, c, "anyOf accepting anything is replaced by EmptyConstraint") {
+			return
+		}
+	})
+
+	t.Run("merge strings", func(t *testing.T) {
+		c := validator.Optimize(validator.All().
+			Add(validator.String().MinLength(2)).
+			Add(validator.String().MaxLength(5).RegexpString(`^[a-z]+$`)).
+			Add(validator.String().MinLength(3)),
+		)
+
+		sc, ok := c.(*validator.StringConstraint)
+		if !assert.True(t, ok, "result is a StringConstraint (got %T)", c) {
+			return
+		}
+		if !assert.Equal(t, []int64{3, 5}, []int64{sc.GetMinLength(), sc.GetMaxLength()}, "lengths are merged") {
+			return
+		}
+		if !assert.NotNil(t, sc.GetRegexp(), "pattern is kept") {
+			return
+		}
+	})
+
+	t.Run("strings with different patterns are kept apart", func(t *testing.T) {
+		c := validator.Optimize(validator.All().
+			Add(validator.String().RegexpString(`^a`)).
+			Add(validator.String().RegexpString(`b$`)),
+		)
+
+		all, ok := c.(*validator.AllConstraint)
+		if !assert.True(t, ok, "result is an AllConstraint (got %T)", c) {
+			return
+		}
+		if !assert.Len(t, all.Constraints(), 2, "both patterns are kept") {
+			return
+		}
+	})
+
+	t.Run("dedupe references", func(t *testing.T) {
+		cm := &validator.ConstraintMap{}
+		cm.SetReference("#/definitions/foo", validator.String())
+		c := validator.Optimize(validator.Any().
+			Add(validator.Reference(cm).RefersTo("#/definitions/foo")).
+			Add(validator.Reference(cm).RefersTo("#/definitions/foo")),
+		)
+
+		if _, ok := c.(*validator.ReferenceConstraint); !assert.True(t, ok, "duplicate references are removed (got %T)", c) {
+			return
+		}
+	})
+
+	t.Run("oneOf keeps duplicates", func(t *testing.T) {
+		s := validator.String()
+		c := validator.Optimize(validator.OneOf().Add(s).Add(s))
+
+		oneOf, ok := c.(*validator.OneOfConstraint)
+		if !assert.True(t, ok, "result is a OneOfConstraint (got %T)", c) {
+			return
+		}
+		if !assert.Len(t, oneOf.Constraints(), 2, "duplicates are kept") {
+			return
+		}
+	})
+
+	t.Run("cheap checks first", func(t *testing.T) {
+		c := validator.Optimize(validator.All().
+			Add(validator.Object()).
+			Add(validator.String().RegexpString(`^a`)).
+			Add(validator.Boolean()),
+		)
+
+		all, ok := c.(*validator.AllConstraint)
+		if !assert.True(t, ok, "result is an AllConstraint (got %T)", c) {
+			return
+		}
+		l := all.Constraints()
+		if !assert.IsType(t, &validator.BooleanConstraint{}, l[0], "boolean comes first") {
+			return
+		}
+		if !assert.IsType(t, &validator.ObjectConstraint{}, l[2], "object comes last") {
+			return
+		}
+	})
+}
+
+func TestOptimizeValidator(t *testing.T) {
+	cm := &validator.ConstraintMap{}
+	node := validator.Object().
+		AddProp("name", validator.All().
+			Add(validator.String().MinLength(1)).
+			Add(validator.All().Add(validator.String().MaxLength(3)))).
+		AddProp("next", validator.Any().
+			Add(validator.Reference(cm).RefersTo("#/definitions/node")).
+			Add(validator.NullConstraint))
+	cm.SetReference("#/definitions/node", node)
+
+	v := validator.New().SetConstraintMap(cm).SetRoot(validator.All().
+		Add(validator.Reference(cm).RefersTo("#/definitions/node")).
+		Add(validator.EmptyConstraint),
+	)
+	// Validate once, so that the optimized properties replace the ones
+	// that have already been looked up
+	if !assert.NoError(t, v.Validate(map[string]interface{}{"name": "foo"}), "validation passes") {
+		return
+	}
+	v.Optimize()
+
+	if _, ok := v.Root().(*validator.ReferenceConstraint); !assert.True(t, ok, "root is reduced to the reference (got %T)", v.Root()) {
+		return
+	}
+	if _, ok := node.GetProp("name").(*validator.StringConstraint); !assert.True(t, ok, "name is reduced to a string (got %T)", node.GetProp("name")) {
+		return
+	}
+
+	data := []struct {
+		value interface{}
+		valid bool
+	}{
+		{map[string]interface{}{"name": "foo"}, true},
+		{map[string]interface{}{"name": "foo", "next": map[string]interface{}{"name": "bar", "next": nil}}, true},
+		{map[string]interface{}{"name": ""}, false},
+		{map[string]interface{}{"name": "toolong"}, false},
+		{map[string]interface{}{"name": "foo", "next": map[string]interface{}{"name": "toolong"}}, false},
+	}
+	for _, d := range data {
+		err := v.Validate(d.value)
+		if d.valid {
+			if !assert.NoError(t, err, "%#v should be valid", d.value) {
+				return
+			}
+		} else {
+			if !assert.Error(t, err, "%#v should be invalid", d.value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package patch
+
+// Merge applies the JSON Merge Patch p to doc, as described in RFC
+// 7386, and returns the result. Objects in p are merged into the
+// corresponding objects of doc, null values remove the property, and
+// any other value replaces the target. The result does not share any
+// map or slice with doc or p
+func Merge(doc, p interface{}) interface{} {
+	pm, ok := p.(map[string]interface{})
+	if !ok {
+		return deepCopy(p)
+	}
+
+	// A patch object replaces anything that is not an object
+	dm, _ := doc.(map[string]interface{})
+
+	out := make(map[string]interface{}, len(dm)+len(pm))
+	for name, value := range dm {
+		out[name] = deepCopy(value)
+	}
+	for name, value := range pm {
+		if value == nil {
+			delete(out, name)
+			continue
+		}
+		out[name] = Merge(out[name], value)
+	}
+	return out
+}
+
+// deepCopy returns a copy of the decoded JSON value x that does not
+// share any map or slice with it
+func deepCopy(x interface{}) interface{} {
+	switch x := x.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for name, value := range x {
+			m[name] = deepCopy(value)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(x))
+		for i, value := range x {
+			l[i] = deepCopy(value)
+		}
+		return l
+	default:
+		return x
+	}
+}
@@ -0,0 +1,70 @@
+// Package patch applies JSON Merge Patch (RFC 7386) and JSON Patch
+// (RFC 6902) documents to decoded JSON values, and validates the
+// result.
+//
+// PATCH endpoints usually accept partial documents that are described
+// by the same schema as the full resource. Rather than validating the
+// patch itself, the patch is applied to the current document and the
+// outcome is validated against the full schema:
+//
+//	patched, err := patch.ValidateMerge(v, current, body)
+//
+// To check a merge patch on its own, before the current document is
+// available, use validator.WithPartial instead.
+package patch
+
+import (
+	"context"
+
+	"github.com/go-json-schema/validator"
+	"github.com/lestrrat/go-pdebug"
+	"github.com/pkg/errors"
+)
+
+// ValidateMerge applies the merge patch p to doc, and validates the
+// result against v. The patched document is returned even if it is
+// invalid. doc is not modified
+func ValidateMerge(v *validator.JSVal, doc, p interface{}) (interface{}, error) {
+	return ValidateMergeContext(context.Background(), v, doc, p)
+}
+
+// ValidateMergeContext is the same as ValidateMerge, but honors the
+// given context
+func ValidateMergeContext(ctx context.Context, v *validator.JSVal, doc, p interface{}) (patched interface{}, err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("patch.ValidateMerge").BindError(&err)
+		defer g.End()
+	}
+
+	patched = Merge(doc, p)
+	if err := v.ValidateContext(ctx, patched); err != nil {
+		return patched, errors.Wrap(err, `patched document is invalid`)
+	}
+	return patched, nil
+}
+
+// ValidatePatch applies the JSON Patch operations in ops to doc, and
+// validates the result against v. If the operations can be applied,
+// the patched document is returned even if it is invalid. doc is not
+// modified
+func ValidatePatch(v *validator.JSVal, doc interface{}, ops []Operation) (interface{}, error) {
+	return ValidatePatchContext(context.Background(), v, doc, ops)
+}
+
+// ValidatePatchContext is the same as ValidatePatch, but honors the
+// given context
+func ValidatePatchContext(ctx context.Context, v *validator.JSVal, doc interface{}, ops []Operation) (patched interface{}, err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("patch.ValidatePatch").BindError(&err)
+		defer g.End()
+	}
+
+	patched, err = Apply(doc, ops)
+	if err != nil {
+		return nil, errors.Wrap(err, `failed to apply patch`)
+	}
+	if err := v.ValidateContext(ctx, patched); err != nil {
+		return patched, errors.Wrap(err, `patched document is invalid`)
+	}
+	return patched, nil
+}
@@ -0,0 +1,156 @@
+package patch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-json-schema/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func decode(t *testing.T, src string) interface{} {
+	var v interface{}
+	if !assert.NoError(t, json.Unmarshal([]byte(src), &v), "decoding %s should succeed", src) {
+		t.FailNow()
+	}
+	return v
+}
+
+func TestMerge(t *testing.T) {
+	// Test cases from RFC 7386, Appendix A
+	data := []struct {
+		doc, patch, expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, d := range data {
+		doc := decode(t, d.doc)
+		result := Merge(doc, decode(t, d.patch))
+		if !assert.Equal(t, decode(t, d.expected), result, "merging %s into %s", d.patch, d.doc) {
+			return
+		}
+		if !assert.Equal(t, decode(t, d.doc), doc, "document is not modified") {
+			return
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	data := []struct {
+		name, doc, patch, expected string
+	}{
+		{"add property", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"append", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc"]}]`, `{"foo":["bar",["abc"]]}`},
+		{"remove property", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"move", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"copy", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":1},"c":{"b":1}}`},
+		{"test", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"escaped", `{"a/b":{"c~d":1}}`, `[{"op":"replace","path":"/a~1b/c~0d","value":2}]`, `{"a/b":{"c~d":2}}`},
+		{"root", `{"a":1}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			ops, err := ParseOperations([]byte(d.patch))
+			if !assert.NoError(t, err, "ParseOperations should succeed") {
+				return
+			}
+			doc := decode(t, d.doc)
+			result, err := Apply(doc, ops)
+			if !assert.NoError(t, err, "Apply should succeed") {
+				return
+			}
+			if !assert.Equal(t, decode(t, d.expected), result, "result matches") {
+				return
+			}
+			if !assert.Equal(t, decode(t, d.doc), doc, "document is not modified") {
+				return
+			}
+		})
+	}
+}
+
+func TestApplyInvalid(t *testing.T) {
+	data := []struct {
+		name, doc, patch string
+	}{
+		{"missing parent", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`},
+		{"index out of range", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/2","value":"qux"}]`},
+		{"leading zero", `{"foo":["bar","baz"]}`, `[{"op":"remove","path":"/foo/01"}]`},
+		{"remove missing", `{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`},
+		{"replace missing", `{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`},
+		{"move into child", `{"a":{"b":{}}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`},
+		{"test fails", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`},
+		{"unknown op", `{}`, `[{"op":"merge","path":"/a"}]`},
+		{"invalid pointer", `{}`, `[{"op":"add","path":"a","value":1}]`},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			ops, err := ParseOperations([]byte(d.patch))
+			if !assert.NoError(t, err, "ParseOperations should succeed") {
+				return
+			}
+			_, err = Apply(decode(t, d.doc), ops)
+			if !assert.Error(t, err, "Apply should fail") {
+				return
+			}
+		})
+	}
+
+	_, err := ParseOperations([]byte(`[{"op":"add","path":"/a"}]`))
+	if !assert.Error(t, err, "add without a value is rejected") {
+		return
+	}
+}
+
+func TestValidate(t *testing.T) {
+	v := validator.New().SetRoot(validator.Object().
+		AddProp("name", validator.String().MinLength(1)).
+		AddProp("tags", validator.Array().Items(validator.String())).
+		Required("name"))
+	current := decode(t, `{"name":"Tama","tags":["cat"]}`)
+
+	patched, err := ValidateMerge(v, current, decode(t, `{"tags":["cat","white"]}`))
+	if !assert.NoError(t, err, "valid merge patch is accepted") {
+		return
+	}
+	if !assert.Equal(t, decode(t, `{"name":"Tama","tags":["cat","white"]}`), patched, "patched document matches") {
+		return
+	}
+	_, err = ValidateMerge(v, current, decode(t, `{"name":null}`))
+	if !assert.Error(t, err, "merge patch removing a required property is rejected") {
+		return
+	}
+
+	ops, err := ParseOperations([]byte(`[{"op":"add","path":"/tags/-","value":"white"}]`))
+	if !assert.NoError(t, err, "ParseOperations should succeed") {
+		return
+	}
+	if _, err := ValidatePatch(v, current, ops); !assert.NoError(t, err, "valid patch is accepted") {
+		return
+	}
+	ops, err = ParseOperations([]byte(`[{"op":"add","path":"/tags/-","value":1}]`))
+	if !assert.NoError(t, err, "ParseOperations should succeed") {
+		return
+	}
+	if _, err := ValidatePatch(v, current, ops); !assert.Error(t, err, "patch adding an invalid item is rejected") {
+		return
+	}
+}
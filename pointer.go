@@ -0,0 +1,246 @@
+package validator
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/lestrrat/go-pdebug"
+	"github.com/pkg/errors"
+)
+
+// ValidateAt validates x, the value found at the given JSON pointer in
+// a larger document, against the part of the schema that applies to
+// that location. This allows a single field to be checked without
+// validating the whole document. Pointers reported in validation
+// errors are relative to the root of the document.
+//
+// The pointer is resolved through object properties (including
+// patternProperties and additionalProperties), array items and
+// references. See ConstraintAt for how combinations are handled. As
+// the rest of the document is not known, $data references must point
+// within x.
+func (v *JSVal) ValidateAt(pointer string, x interface{}) error {
+	return v.ValidateAtContext(context.Background(), pointer, x)
+}
+
+// ValidateAtContext is the same as ValidateAt, but honors the given
+// context
+func (v *JSVal) ValidateAtContext(ctx context.Context, pointer string, x interface{}) (err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("JSVal.ValidateAt %s", pointer).BindError(&err)
+		defer g.End()
+	}
+
+	c, err := v.ConstraintAt(pointer)
+	if err != nil {
+		return v.wrapError(err)
+	}
+
+	// Push a frame for each token, so that errors point to the value
+	// within the whole document
+	tokens, _ := splitPointer(pointer)
+	ctx, f := startRun(v.withDirection(ctx))
+	for _, token := range tokens {
+		f = &frame{
+			Context: ctx,
+			run:     f.run,
+			parent:  f,
+			seg:     token,
+			depth:   f.depth + 1,
+		}
+		ctx = f
+	}
+	return v.wrapError(validateContext(ctx, c, x))
+}
+
+// ConstraintAt returns the constraint that the value at the given JSON
+// pointer in a document must satisfy.
+//
+// Values inside an allOf must satisfy the constraints of every branch,
+// so an AllConstraint is returned if several branches describe the
+// location. For anyOf, oneOf and discriminators, the branch that
+// applies depends on the rest of the document, so an AnyConstraint of
+// the candidate branches is returned. A "not" says nothing about the
+// parts of a value, and is skipped
+func (v *JSVal) ConstraintAt(pointer string) (Constraint, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := constraintAt(v.root, tokens, map[Constraint]struct{}{})
+	if err != nil {
+		return nil, errors.Wrapf(err, `failed to resolve '%s'`, pointer)
+	}
+	return c, nil
+}
+
+// constraintAt resolves tokens starting from c. seen holds the
+// references followed since the last token was consumed, so that
+// references that never lead to a schema are detected
+func constraintAt(c Constraint, tokens []string, seen map[Constraint]struct{}) (Constraint, error) {
+	if len(tokens) == 0 {
+		return c, nil
+	}
+
+	switch c := c.(type) {
+	case *ReferenceConstraint:
+		if _, ok := seen[c]; ok {
+			return nil, errors.Errorf("circular reference to '%s'", c.GetRefersTo())
+		}
+		seen[c] = struct{}{}
+		resolved, err := c.Resolved()
+		if err != nil {
+			return nil, errors.Wrapf(err, `failed to resolve reference '%s'`, c.GetRefersTo())
+		}
+		return constraintAt(resolved, tokens, seen)
+	case *AllConstraint:
+		l, err := combineAt(c.Constraints(), tokens, seen, false)
+		if err != nil {
+			return nil, err
+		}
+		all := All()
+		for _, rc := range l {
+			all.Add(rc)
+		}
+		return reduceAt(all, len(l)), nil
+	case *AnyConstraint, *OneOfConstraint, *DiscriminatorConstraint:
+		var branches []Constraint
+		switch c := c.(type) {
+		case *AnyConstraint:
+			branches = c.Constraints()
+		case *OneOfConstraint:
+			branches = c.Constraints()
+		case *DiscriminatorConstraint:
+			for _, value := range c.GetMappingValues() {
+				branches = append(branches, c.GetMapping(value))
+			}
+		}
+		l, err := combineAt(branches, tokens, seen, true)
+		if err != nil {
+			return nil, err
+		}
+		candidates := Any()
+		for _, rc := range l {
+			candidates.Add(rc)
+		}
+		return reduceAt(candidates, len(l)), nil
+	case *NotConstraint, NotConstraint, emptyConstraint:
+		return EmptyConstraint, nil
+	case *ObjectConstraint:
+		child, err := c.propConstraint(tokens[0])
+		if err != nil {
+			return nil, err
+		}
+		return constraintAt(child, tokens[1:], map[Constraint]struct{}{})
+	case *ArrayConstraint:
+		i, err := strconv.Atoi(tokens[0])
+		if err != nil || i < 0 {
+			return nil, errors.Errorf("invalid array index '%s'", tokens[0])
+		}
+		child, err := c.itemConstraint(i)
+		if err != nil {
+			return nil, err
+		}
+		if child == nil {
+			child = EmptyConstraint
+		}
+		return constraintAt(child, tokens[1:], map[Constraint]struct{}{})
+	default:
+		return nil, errors.Errorf("can not look up '%s' in a value that is neither an object nor an array", tokens[0])
+	}
+}
+
+// combineAt resolves tokens in each of the constraints in l. If
+// alternatives is true, only one of them applies to the value:
+// branches that can not be resolved are skipped, and nil is returned
+// if one of them accepts anything. Otherwise all of them must be
+// resolved
+func combineAt(l []Constraint, tokens []string, seen map[Constraint]struct{}, alternatives bool) ([]Constraint, error) {
+	var first error
+	var resolved []Constraint
+	for _, c := range l {
+		// Branches may refer to the same schema without being circular
+		branchSeen := make(map[Constraint]struct{}, len(seen))
+		for ref := range seen {
+			branchSeen[ref] = struct{}{}
+		}
+
+		rc, err := constraintAt(c, tokens, branchSeen)
+		if err != nil {
+			if !alternatives {
+				return nil, err
+			}
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		if rc == EmptyConstraint {
+			if alternatives {
+				return nil, nil
+			}
+			continue
+		}
+		resolved = append(resolved, rc)
+	}
+
+	if len(resolved) == 0 && first != nil {
+		return nil, first
+	}
+	return resolved, nil
+}
+
+// reduceAt simplifies the combination c of n constraints
+func reduceAt(c interface {
+	Constraint
+	Reduce() Constraint
+}, n int) Constraint {
+	if n == 0 {
+		return EmptyConstraint
+	}
+	return c.Reduce()
+}
+
+// propConstraint returns the constraint that the property pname must
+// satisfy. As in validation, a property must satisfy its definition
+// as well as every pattern it matches, which are combined in the order
+// of the patterns
+func (o *ObjectConstraint) propConstraint(pname string) (Constraint, error) {
+	var matched []Constraint
+	if c := o.GetProp(pname); c != nil {
+		matched = append(matched, c)
+	}
+
+	patterns := o.GetPatternProperties()
+	keys := make([]string, 0, len(patterns))
+	byKey := make(map[string]Constraint, len(patterns))
+	for rx, c := range patterns {
+		if rx.MatchString(pname) {
+			keys = append(keys, rx.String())
+			byKey[rx.String()] = c
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		matched = append(matched, byKey[key])
+	}
+
+	switch len(matched) {
+	case 0:
+	case 1:
+		return matched[0], nil
+	default:
+		all := All()
+		for _, c := range matched {
+			all.Add(c)
+		}
+		return all, nil
+	}
+
+	if o.additionalProperties == nil {
+		return nil, errors.Errorf("property '%s' is not allowed", pname)
+	}
+	return o.additionalProperties, nil
+}
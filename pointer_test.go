@@ -0,0 +1,131 @@
+package validator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-json-schema/schema"
+	"github.com/go-json-schema/schema/draft04"
+	"github.com/go-json-schema/validator"
+	"github.com/go-json-schema/validator/builder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAt(t *testing.T) {
+	const src = `{
+  "type": "object",
+  "definitions": {
+    "address": {
+      "type": "object",
+      "properties": {
+        "zip": { "type": "string", "pattern": "^[0-9]{5}$" }
+      }
+    }
+  },
+  "properties": {
+    "address": { "$ref": "#/definitions/address" },
+    "tags": { "type": "array", "items": { "type": "string", "maxLength": 5 } },
+    "contact": {
+      "oneOf": [
+        { "type": "object", "properties": { "email": { "type": "string", "format": "email" } }, "additionalProperties": false },
+        { "type": "object", "properties": { "phone": { "type": "string" } }, "additionalProperties": false }
+      ]
+    }
+  },
+  "patternProperties": {
+    "^x-": { "type": "integer" }
+  },
+  "additionalProperties": false
+}`
+
+	s, err := schema.Parse(strings.NewReader(src), schema.WithSchemaID(draft04.SchemaID))
+	if !assert.NoError(t, err, "reading schema should succeed") {
+		return
+	}
+
+	v, err := builder.New().Build(s)
+	if !assert.NoError(t, err, "Builder.Build should succeed") {
+		return
+	}
+
+	data := []struct {
+		pointer string
+		value   interface{}
+		valid   bool
+	}{
+		{"/address/zip", "12345", true},
+		{"/address/zip", "1234", false},
+		{"/address", map[string]interface{}{"zip": "12345"}, true},
+		{"/tags/3", "short", true},
+		{"/tags/3", "too long", false},
+		{"/contact/email", "tama@example.com", true},
+		{"/contact/email", "not an email", false},
+		{"/x-count", float64(1), true},
+		{"/x-count", "one", false},
+		{"", map[string]interface{}{"tags": []interface{}{"a"}}, true},
+	}
+	for _, d := range data {
+		err := v.ValidateAt(d.pointer, d.value)
+		if d.valid {
+			if !assert.NoError(t, err, "%#v should be valid at '%s'", d.value, d.pointer) {
+				return
+			}
+		} else {
+			if !assert.Error(t, err, "%#v should be invalid at '%s'", d.value, d.pointer) {
+				return
+			}
+		}
+	}
+
+	err = v.ValidateAt("/address/zip", "1234")
+	verr, ok := validator.AsValidationError(err)
+	if !assert.True(t, ok, "error is a ValidationError") {
+		return
+	}
+	if !assert.Equal(t, "/address/zip", verr.Pointer, "pointer is relative to the document") {
+		return
+	}
+
+	for _, pointer := range []string{"/unknown", "/address/zip/0", "/tags/first", "address"} {
+		if !assert.Error(t, v.ValidateAt(pointer, "x"), "'%s' can not be resolved", pointer) {
+			return
+		}
+	}
+}
+
+func TestValidateAtPatternProperties(t *testing.T) {
+	v := validator.New().SetRoot(validator.Object().
+		AddProp("x-count", validator.Integer().Minimum(0)).
+		PatternPropertiesString("^x-", validator.Integer().Maximum(10)).
+		PatternPropertiesString("count$", validator.Integer().MultipleOf(2)))
+
+	// A property must satisfy its definition as well as every pattern
+	// it matches, whether the document is validated as a whole or not
+	for _, value := range []float64{4, -2, 12, 3} {
+		whole := v.Validate(map[string]interface{}{"x-count": value})
+		at := v.ValidateAt("/x-count", value)
+		if !assert.Equal(t, whole == nil, at == nil, "ValidateAt agrees with Validate for %v", value) {
+			return
+		}
+	}
+	if !assert.Error(t, v.ValidateAt("/x-count", float64(12)), "patterns apply to defined properties") {
+		return
+	}
+}
+
+func TestConstraintAt(t *testing.T) {
+	zip := validator.String()
+	v := validator.New().SetRoot(validator.All().
+		Add(validator.Object().
+			AddProp("zip", zip)).
+		Add(validator.Object().
+			AdditionalProperties(validator.EmptyConstraint)))
+
+	c, err := v.ConstraintAt("/zip")
+	if !assert.NoError(t, err, "ConstraintAt should succeed") {
+		return
+	}
+	if !assert.Equal(t, zip, c, "branches accepting anything are dropped") {
+		return
+	}
+}
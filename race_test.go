@@ -0,0 +1,8 @@
+//go:build race
+// +build race
+
+package validator_test
+
+func init() {
+	raceEnabled = true
+}
@@ -1,13 +1,15 @@
 package validator
 
 import (
+	"context"
 	"errors"
+	"sort"
 	"sync"
 
 	"github.com/lestrrat/go-pdebug"
 )
 
-// RefResolver is a mandatory object that you must pass to a 
+// RefResolver is a mandatory object that you must pass to a
 // ReferenceConstraint upon its creation. This is responsible
 // for resolving the reference to an actual constraint.
 type RefResolver interface {
@@ -54,6 +56,21 @@ func (cm *ConstraintMap) GetReference(name string) (Constraint, error) {
 	return c, nil
 }
 
+// GetReferenceNames returns the sorted list of names that have been
+// registered using SetReference
+func (cm *ConstraintMap) GetReferenceNames() []string {
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+
+	refs := cm.refmap()
+	l := make([]string, 0, len(refs))
+	for name := range refs {
+		l = append(l, name)
+	}
+	sort.Strings(l)
+	return l
+}
+
 // ReferenceConstraint is a constraint where its actual definition
 // is stored elsewhere.
 type ReferenceConstraint struct {
@@ -107,6 +124,12 @@ func (r *ReferenceConstraint) RefersTo(s string) *ReferenceConstraint {
 	return r
 }
 
+// GetRefersTo returns the reference string that this constraint
+// points to
+func (r *ReferenceConstraint) GetRefersTo() string {
+	return r.reference
+}
+
 // Default is a no op for this type
 func (r *ReferenceConstraint) Default(_ interface{}) {
 }
@@ -133,7 +156,12 @@ func (r *ReferenceConstraint) HasDefault() bool {
 
 // Validate validates the value against the constraint pointed to
 // by the reference.
-func (r *ReferenceConstraint) Validate(v interface{}) (err error) {
+func (r *ReferenceConstraint) Validate(v interface{}) error {
+	return r.ValidateContext(context.Background(), v)
+}
+
+// ValidateContext is the same as Validate, but honors the given context
+func (r *ReferenceConstraint) ValidateContext(ctx context.Context, v interface{}) (err error) {
 	if pdebug.Enabled {
 		g := pdebug.IPrintf("START ReferenceConstraint.Validate")
 		defer func() {
@@ -149,5 +177,5 @@ func (r *ReferenceConstraint) Validate(v interface{}) (err error) {
 	if err != nil {
 		return err
 	}
-	return c.Validate(v)
-}
\ No newline at end of file
+	return validateContext(ctx, c, v)
+}
@@ -0,0 +1,306 @@
+package sample
+
+import (
+	"regexp"
+
+	"github.com/go-json-schema/validator"
+)
+
+// generateAll generates a value for an allOf. Branches of the same type
+// are merged into a single constraint, so that the value is built to
+// satisfy all of them at once, instead of hoping that a value generated
+// from one branch happens to satisfy the others. Combinations that can
+// not be merged fall back to generateBranch
+func (g *Generator) generateAll(c *validator.AllConstraint, depth int) (interface{}, error) {
+	branches, err := g.flattenAll(c.Constraints(), depth)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, ok := mergeBranches(branches)
+	if !ok {
+		return g.generateBranch(c, c.Constraints(), depth)
+	}
+	return g.satisfy(c, func() (interface{}, error) { return g.generate(merged, depth) })
+}
+
+// flattenAll returns the branches in l, with references resolved and
+// nested allOfs inlined. EmptyConstraints are left out, as they accept
+// any value
+func (g *Generator) flattenAll(l []validator.Constraint, depth int) ([]validator.Constraint, error) {
+	var flat []validator.Constraint
+	for _, c := range l {
+		switch c := c.(type) {
+		case *validator.ReferenceConstraint:
+			if depth >= g.maxDepth {
+				return nil, errTooDeep
+			}
+			resolved, err := c.Resolved()
+			if err != nil {
+				return nil, err
+			}
+			sub, err := g.flattenAll([]validator.Constraint{resolved}, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			flat = append(flat, sub...)
+		case *validator.AllConstraint:
+			sub, err := g.flattenAll(c.Constraints(), depth)
+			if err != nil {
+				return nil, err
+			}
+			flat = append(flat, sub...)
+		default:
+			if c != validator.EmptyConstraint {
+				flat = append(flat, c)
+			}
+		}
+	}
+	return flat, nil
+}
+
+// mergeBranches merges constraints of the same type into one, whose
+// values are likely to satisfy all of them. It returns false if the
+// constraints can not be merged
+func mergeBranches(l []validator.Constraint) (validator.Constraint, bool) {
+	switch len(l) {
+	case 0:
+		return validator.EmptyConstraint, true
+	case 1:
+		return l[0], true
+	}
+
+	switch l[0].(type) {
+	case *validator.ObjectConstraint:
+		objects := make([]*validator.ObjectConstraint, len(l))
+		for i, c := range l {
+			o, ok := c.(*validator.ObjectConstraint)
+			if !ok {
+				return nil, false
+			}
+			objects[i] = o
+		}
+		return mergeObjects(objects), true
+	case *validator.StringConstraint:
+		strs := make([]*validator.StringConstraint, len(l))
+		for i, c := range l {
+			s, ok := c.(*validator.StringConstraint)
+			if !ok {
+				return nil, false
+			}
+			strs[i] = s
+		}
+		return mergeStrings(strs), true
+	case *validator.NumberConstraint, *validator.IntegerConstraint:
+		integer := false
+		nums := make([]*validator.NumberConstraint, len(l))
+		for i, c := range l {
+			switch c := c.(type) {
+			case *validator.NumberConstraint:
+				nums[i] = c
+			case *validator.IntegerConstraint:
+				nums[i] = &c.NumberConstraint
+				integer = true
+			default:
+				return nil, false
+			}
+		}
+		return mergeNumbers(nums, integer), true
+	case *validator.ArrayConstraint:
+		arrays := make([]*validator.ArrayConstraint, len(l))
+		for i, c := range l {
+			a, ok := c.(*validator.ArrayConstraint)
+			if !ok || len(a.GetPositionalItems()) > 0 {
+				return nil, false
+			}
+			arrays[i] = a
+		}
+		return mergeArrays(arrays), true
+	}
+	return nil, false
+}
+
+// mergeObjects merges the properties, required lists, dependencies and
+// limits of l. Properties and patterns that are defined in several
+// branches must satisfy all of their definitions
+func mergeObjects(l []*validator.ObjectConstraint) validator.Constraint {
+	merged := validator.Object()
+
+	var names []string
+	props := make(map[string]*validator.AllConstraint)
+	var rxs []*regexp.Regexp
+	patterns := make(map[string]*validator.AllConstraint)
+	additional := validator.All()
+	disallow := false
+	min, max := int64(-1), int64(-1)
+	for _, o := range l {
+		for _, name := range o.GetPropNames() {
+			if _, ok := props[name]; !ok {
+				props[name] = validator.All()
+				names = append(names, name)
+			}
+			props[name].Add(o.GetProp(name))
+		}
+
+		merged.Required(o.GetRequired()...)
+
+		pp := o.GetPatternProperties()
+		for _, rx := range sortedPatterns(pp) {
+			if _, ok := patterns[rx.String()]; !ok {
+				patterns[rx.String()] = validator.All()
+				rxs = append(rxs, rx)
+			}
+			patterns[rx.String()].Add(pp[rx])
+		}
+
+		switch ap := o.GetAdditionalProperties(); ap {
+		case nil:
+			disallow = true
+		case validator.EmptyConstraint:
+		default:
+			additional.Add(ap)
+		}
+
+		if n := o.GetMinProperties(); n > min {
+			min = n
+		}
+		if n := o.GetMaxProperties(); n > -1 && (max < 0 || n < max) {
+			max = n
+		}
+	}
+
+	for _, name := range names {
+		merged.AddProp(name, props[name].Reduce())
+	}
+	for _, rx := range rxs {
+		merged.PatternProperties(rx, patterns[rx.String()].Reduce())
+	}
+	switch {
+	case disallow:
+		merged.AdditionalProperties(nil)
+	case len(additional.Constraints()) > 0:
+		merged.AdditionalProperties(additional.Reduce())
+	default:
+		merged.AdditionalProperties(validator.EmptyConstraint)
+	}
+	merged.MinProperties(min)
+	merged.MaxProperties(max)
+
+	// Dependencies can only be looked up by name, so only those of the
+	// properties that the branches mention are carried over
+	for _, o := range l {
+		for _, name := range append(o.GetPropNames(), o.GetRequired()...) {
+			if deps := o.GetPropDependencies(name); len(deps) > 0 {
+				merged.PropDependency(name, deps...)
+			}
+		}
+	}
+	return merged
+}
+
+// mergeStrings keeps the tightest length limits of l, along with the
+// first pattern, format and enum found. Values that do not satisfy the
+// other patterns or formats are rejected when the allOf is validated
+func mergeStrings(l []*validator.StringConstraint) validator.Constraint {
+	merged := validator.String()
+	min, max := int64(0), int64(-1)
+	for _, s := range l {
+		if n := s.GetMinLength(); n > min {
+			min = n
+		}
+		if n := s.GetMaxLength(); n > -1 && (max < 0 || n < max) {
+			max = n
+		}
+		if rx := s.GetRegexp(); rx != nil && merged.GetRegexp() == nil {
+			merged.Regexp(rx)
+		}
+		if f := s.GetFormat(); f != "" && merged.GetFormat() == "" {
+			merged.Format(f)
+		}
+		if enum := s.GetEnum(); len(enum) > 0 && len(merged.GetEnum()) == 0 {
+			merged.Enum(enum...)
+		}
+	}
+	merged.MinLength(min)
+	merged.MaxLength(max)
+	return merged
+}
+
+// mergeNumbers keeps the tightest limits of l, along with the first
+// multipleOf and enum found. The result is an integer if any of l is
+func mergeNumbers(l []*validator.NumberConstraint, integer bool) validator.Constraint {
+	var merged validator.NumericConstraint = validator.Number()
+	if integer {
+		merged = validator.Integer()
+	}
+
+	var lower, upper *validator.NumberConstraint
+	var multipleOf, enum *validator.NumberConstraint
+	for _, n := range l {
+		if n.HasMinimum() && (lower == nil || n.GetMinimum() > lower.GetMinimum() ||
+			(n.GetMinimum() == lower.GetMinimum() && n.IsExclusiveMinimum())) {
+			lower = n
+		}
+		if n.HasMaximum() && (upper == nil || n.GetMaximum() < upper.GetMaximum() ||
+			(n.GetMaximum() == upper.GetMaximum() && n.IsExclusiveMaximum())) {
+			upper = n
+		}
+		if n.HasMultipleOf() && multipleOf == nil {
+			multipleOf = n
+		}
+		if len(n.GetEnum()) > 0 && enum == nil {
+			enum = n
+		}
+	}
+
+	switch {
+	case lower == nil:
+	case lower.IsExclusiveMinimum():
+		merged.ExclusiveMinimum(lower.GetMinimum())
+	default:
+		merged.Minimum(lower.GetMinimum())
+	}
+	switch {
+	case upper == nil:
+	case upper.IsExclusiveMaximum():
+		merged.ExclusiveMaximum(upper.GetMaximum())
+	default:
+		merged.Maximum(upper.GetMaximum())
+	}
+	if multipleOf != nil {
+		merged.MultipleOf(multipleOf.GetMultipleOf())
+	}
+	if enum != nil {
+		merged.Enum(enum.GetEnum()...)
+	}
+	return merged
+}
+
+// mergeArrays keeps the tightest limits of l, and requires items to
+// satisfy the items constraint of every branch
+func mergeArrays(l []*validator.ArrayConstraint) validator.Constraint {
+	merged := validator.Array()
+	items := validator.All()
+	min, max := -1, -1
+	unique := false
+	for _, a := range l {
+		if ic := a.GetItems(); ic != nil {
+			items.Add(ic)
+		}
+		if n := a.GetMinItems(); n > min {
+			min = n
+		}
+		if n := a.GetMaxItems(); n > -1 && (max < 0 || n < max) {
+			max = n
+		}
+		unique = unique || a.GetUniqueItems()
+	}
+
+	if len(items.Constraints()) > 0 {
+		merged.Items(items.Reduce())
+	}
+	merged.MinItems(min)
+	merged.MaxItems(max)
+	merged.UniqueItems(unique)
+	return merged
+}
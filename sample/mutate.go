@@ -0,0 +1,540 @@
+package sample
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-json-schema/validator"
+	"github.com/lestrrat/go-pdebug"
+	"github.com/pkg/errors"
+)
+
+// Keywords that Mutate knows how to violate. The exclusive variants
+// of minimum and maximum are covered by their inclusive counterparts.
+const (
+	KeywordType                 = "type"
+	KeywordEnum                 = "enum"
+	KeywordMinLength            = "minLength"
+	KeywordMaxLength            = "maxLength"
+	KeywordPattern              = "pattern"
+	KeywordFormat               = "format"
+	KeywordMinimum              = "minimum"
+	KeywordMaximum              = "maximum"
+	KeywordMultipleOf           = "multipleOf"
+	KeywordMinItems             = "minItems"
+	KeywordMaxItems             = "maxItems"
+	KeywordUniqueItems          = "uniqueItems"
+	KeywordAdditionalItems      = "additionalItems"
+	KeywordRequired             = "required"
+	KeywordAdditionalProperties = "additionalProperties"
+	KeywordMinProperties        = "minProperties"
+	KeywordMaxProperties        = "maxProperties"
+)
+
+// Mutation is an invalid instance produced by Mutate
+type Mutation struct {
+	// Pointer is the JSON pointer to the value that was changed
+	Pointer string
+	// Keyword is the keyword that the changed value violates
+	Keyword string
+	// Value is the complete, invalid, instance
+	Value interface{}
+}
+
+// site is a value within an instance, along with the constraint that
+// it was validated against
+type site struct {
+	path  []string
+	c     validator.Constraint
+	value interface{}
+}
+
+// Mutate generates a valid instance for v, and then changes one of
+// its values so that it violates keyword, while leaving the rest of
+// the instance untouched. An error is returned if none of the
+// generated instances contain a value that is subject to keyword.
+func (g *Generator) Mutate(v *validator.JSVal, keyword string) (m *Mutation, err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("sample.Mutate (%s)", keyword).BindError(&err)
+		defer g.End()
+	}
+
+	if v == nil {
+		return nil, errors.New("validator must not be nil")
+	}
+
+	for i := 0; i < g.attempts; i++ {
+		value, err := g.generate(v.Root(), 0)
+		if err != nil {
+			return nil, errors.Wrap(err, `failed to generate value`)
+		}
+
+		var sites []site
+		if err := collectSites(v.Root(), value, nil, keyword, &sites); err != nil {
+			return nil, err
+		}
+		if len(sites) == 0 {
+			continue
+		}
+
+		s := sites[g.rand.Intn(len(sites))]
+		changed, ok := g.violate(s, keyword)
+		if !ok {
+			continue
+		}
+
+		mutated := replace(value, s.path, changed)
+		if v.Validate(mutated) == nil {
+			// Some other part of the schema accepts the change
+			continue
+		}
+		return &Mutation{
+			Pointer: pointer(s.path),
+			Keyword: keyword,
+			Value:   mutated,
+		}, nil
+	}
+	return nil, errors.Errorf(`failed to generate a value that violates %s`, keyword)
+}
+
+func pointer(path []string) string {
+	var buf bytes.Buffer
+	for _, seg := range path {
+		buf.WriteByte('/')
+		buf.WriteString(strings.Replace(strings.Replace(seg, "~", "~0", -1), "/", "~1", -1))
+	}
+	return buf.String()
+}
+
+// collectSites walks value along with c, and appends all values that
+// are subject to keyword to sites
+func collectSites(c validator.Constraint, value interface{}, path []string, keyword string, sites *[]site) error {
+	for i := 0; ; i++ {
+		rc, ok := c.(*validator.ReferenceConstraint)
+		if !ok {
+			break
+		}
+		if i > 100 {
+			return errors.Errorf(`too many levels of references (%s)`, rc.GetRefersTo())
+		}
+		resolved, err := rc.Resolved()
+		if err != nil {
+			return errors.Wrapf(err, `failed to resolve reference %s`, rc.GetRefersTo())
+		}
+		c = resolved
+	}
+
+	if applies(c, keyword) {
+		*sites = append(*sites, site{path: path, c: c, value: value})
+	}
+
+	switch c := c.(type) {
+	case *validator.ObjectConstraint:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := collectSites(propConstraint(c, name), m[name], appendPath(path, name), keyword, sites); err != nil {
+				return err
+			}
+		}
+	case *validator.ArrayConstraint:
+		l, ok := value.([]interface{})
+		if !ok {
+			return nil
+		}
+		for i, item := range l {
+			ic, ok := itemConstraint(c, i)
+			if !ok {
+				break
+			}
+			if err := collectSites(ic, item, appendPath(path, strconv.Itoa(i)), keyword, sites); err != nil {
+				return err
+			}
+		}
+	case *validator.AnyConstraint:
+		return collectBranches(c.Constraints(), value, path, keyword, sites)
+	case *validator.OneOfConstraint:
+		return collectBranches(c.Constraints(), value, path, keyword, sites)
+	case *validator.AllConstraint:
+		return collectBranches(c.Constraints(), value, path, keyword, sites)
+	case *validator.DiscriminatorConstraint:
+		l := make([]validator.Constraint, 0, len(c.GetMappingValues()))
+		for _, v := range c.GetMappingValues() {
+			l = append(l, c.GetMapping(v))
+		}
+		return collectBranches(l, value, path, keyword, sites)
+	}
+	return nil
+}
+
+// collectBranches collects sites from the branches of a combination
+// that accept value
+func collectBranches(branches []validator.Constraint, value interface{}, path []string, keyword string, sites *[]site) error {
+	for _, b := range branches {
+		if b.Validate(value) != nil {
+			continue
+		}
+		if err := collectSites(b, value, path, keyword, sites); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendPath(path []string, seg string) []string {
+	l := make([]string, len(path), len(path)+1)
+	copy(l, path)
+	return append(l, seg)
+}
+
+// applies returns true if c has a constraint for keyword
+func applies(c validator.Constraint, keyword string) bool {
+	switch c := c.(type) {
+	case *validator.StringConstraint:
+		switch keyword {
+		case KeywordType:
+			return true
+		case KeywordEnum:
+			return len(c.GetEnum()) > 0
+		case KeywordMinLength:
+			return c.GetMinLength() > 0
+		case KeywordMaxLength:
+			return c.GetMaxLength() >= 0
+		case KeywordPattern:
+			return c.GetRegexp() != nil
+		case KeywordFormat:
+			_, ok := invalidFormats[c.GetFormat()]
+			return ok
+		}
+	case *validator.IntegerConstraint:
+		return appliesNumber(&c.NumberConstraint, keyword)
+	case *validator.NumberConstraint:
+		return appliesNumber(c, keyword)
+	case *validator.BooleanConstraint:
+		return keyword == KeywordType
+	case *validator.EnumConstraint:
+		return keyword == KeywordEnum && len(c.GetEnum()) > 0
+	case *validator.ArrayConstraint:
+		switch keyword {
+		case KeywordType:
+			return true
+		case KeywordMinItems:
+			return c.GetMinItems() > 0
+		case KeywordMaxItems:
+			return c.GetMaxItems() >= 0
+		case KeywordUniqueItems:
+			return c.GetUniqueItems()
+		case KeywordAdditionalItems:
+			return c.GetItems() == nil && len(c.GetPositionalItems()) > 0 && c.GetAdditionalItems() == nil
+		}
+	case *validator.ObjectConstraint:
+		switch keyword {
+		case KeywordType:
+			return true
+		case KeywordRequired:
+			return len(c.GetRequired()) > 0
+		case KeywordAdditionalProperties:
+			return c.GetAdditionalProperties() == nil
+		case KeywordMinProperties:
+			return c.GetMinProperties() > 0
+		case KeywordMaxProperties:
+			return c.GetMaxProperties() >= 0
+		}
+	}
+	return keyword == KeywordType && c == validator.NullConstraint
+}
+
+func appliesNumber(c *validator.NumberConstraint, keyword string) bool {
+	switch keyword {
+	case KeywordType:
+		return true
+	case KeywordEnum:
+		return len(c.GetEnum()) > 0
+	case KeywordMinimum:
+		return c.HasMinimum()
+	case KeywordMaximum:
+		return c.HasMaximum()
+	case KeywordMultipleOf:
+		return c.HasMultipleOf()
+	}
+	return false
+}
+
+// invalidFormats lists a value that is not valid for each of the
+// formats known to validator.StringConstraint
+var invalidFormats = map[string]string{
+	"datetime": "2006-13-45T25:61:00Z",
+	"email":    "not an email address",
+	"hostname": "-invalid-.example.com",
+	"ipv4":     "256.256.256.256",
+	"ipv6":     "1:2:3",
+	"uri":      "%zz",
+}
+
+// violate returns a variant of s.value that violates keyword, or false
+// if s.value can not be changed to do so
+func (g *Generator) violate(s site, keyword string) (interface{}, bool) {
+	switch c := s.c.(type) {
+	case *validator.StringConstraint:
+		return g.violateString(c, s.value.(string), keyword)
+	case *validator.IntegerConstraint:
+		return g.violateNumber(&c.NumberConstraint, s.value, keyword, true)
+	case *validator.NumberConstraint:
+		return g.violateNumber(c, s.value, keyword, false)
+	case *validator.BooleanConstraint:
+		return "true", true
+	case *validator.EnumConstraint:
+		return g.notIn(c.GetEnum(), s.value)
+	case *validator.ArrayConstraint:
+		return g.violateArray(c, s.value.([]interface{}), keyword)
+	case *validator.ObjectConstraint:
+		return g.violateObject(c, s.value.(map[string]interface{}), keyword)
+	}
+	if s.c == validator.NullConstraint {
+		return "null", true
+	}
+	return nil, false
+}
+
+// notIn returns a value of the same type as v, that is not in enum
+func (g *Generator) notIn(enum []interface{}, v interface{}) (interface{}, bool) {
+	for i := 0; i < g.attempts; i++ {
+		var x interface{}
+		switch v := v.(type) {
+		case string:
+			x = v + g.randomString(1+i/10)
+		case float64:
+			x = v + float64(1+i)
+		case bool:
+			x = !v
+		default:
+			x = g.randomString(1 + i/10)
+		}
+		if !contains(enum, x) {
+			return x, true
+		}
+	}
+	return nil, false
+}
+
+func (g *Generator) violateString(c *validator.StringConstraint, s string, keyword string) (interface{}, bool) {
+	switch keyword {
+	case KeywordType:
+		return float64(len(s)), true
+	case KeywordEnum:
+		return g.notIn(c.GetEnum(), s)
+	case KeywordMinLength:
+		return s[:c.GetMinLength()-1], true
+	case KeywordMaxLength:
+		for int64(len(s)) <= c.GetMaxLength() {
+			s += g.randomString(1)
+		}
+		return s, true
+	case KeywordPattern:
+		rx := c.GetRegexp()
+		n := len(s)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < g.attempts; i++ {
+			x := g.randomString(n)
+			if !rx.MatchString(x) {
+				return x, true
+			}
+		}
+	case KeywordFormat:
+		return invalidFormats[c.GetFormat()], true
+	}
+	return nil, false
+}
+
+func (g *Generator) violateNumber(c *validator.NumberConstraint, v interface{}, keyword string, integer bool) (interface{}, bool) {
+	f, ok := v.(float64)
+	if !ok {
+		return nil, false
+	}
+
+	// Stepping by multipleOf keeps the value a valid multiple, so that
+	// only the limit is violated
+	step := 1.0
+	if c.HasMultipleOf() {
+		step = c.GetMultipleOf()
+	}
+
+	switch keyword {
+	case KeywordType:
+		return strconv.FormatFloat(f, 'f', -1, 64), true
+	case KeywordEnum:
+		return g.notIn(c.GetEnum(), f)
+	case KeywordMinimum:
+		if c.IsExclusiveMinimum() {
+			return c.GetMinimum(), true
+		}
+		return math.Floor(c.GetMinimum()/step)*step - step, true
+	case KeywordMaximum:
+		if c.IsExclusiveMaximum() {
+			return c.GetMaximum(), true
+		}
+		return math.Ceil(c.GetMaximum()/step)*step + step, true
+	case KeywordMultipleOf:
+		x := f + c.GetMultipleOf()/2
+		if integer && x != math.Floor(x) {
+			// Integers can only be off by whole numbers
+			x = f + 1
+		}
+		return x, true
+	}
+	return nil, false
+}
+
+func (g *Generator) violateArray(c *validator.ArrayConstraint, l []interface{}, keyword string) (interface{}, bool) {
+	switch keyword {
+	case KeywordType:
+		return map[string]interface{}{}, true
+	case KeywordMinItems:
+		return append([]interface{}(nil), l[:c.GetMinItems()-1]...), true
+	case KeywordMaxItems:
+		l = append([]interface{}(nil), l...)
+		for len(l) <= c.GetMaxItems() {
+			ic, ok := itemConstraint(c, len(l))
+			if !ok {
+				return nil, false
+			}
+			v, err := g.generateItem(ic, l, c.GetUniqueItems(), 0)
+			if err != nil {
+				return nil, false
+			}
+			l = append(l, v)
+		}
+		return l, true
+	case KeywordUniqueItems:
+		if len(l) == 0 {
+			ic, _ := itemConstraint(c, 0)
+			v, err := g.generate(ic, 0)
+			if err != nil {
+				return nil, false
+			}
+			return []interface{}{v, v}, true
+		}
+		l = append([]interface{}(nil), l...)
+		return append(l, l[g.rand.Intn(len(l))]), true
+	case KeywordAdditionalItems:
+		l = append([]interface{}(nil), l...)
+		for len(l) <= len(c.GetPositionalItems()) {
+			l = append(l, g.anyValue())
+		}
+		return l, true
+	}
+	return nil, false
+}
+
+func (g *Generator) violateObject(c *validator.ObjectConstraint, m map[string]interface{}, keyword string) (interface{}, bool) {
+	switch keyword {
+	case KeywordType:
+		return []interface{}{}, true
+	case KeywordRequired:
+		required := c.GetRequired()
+		m = copyMap(m)
+		delete(m, required[g.rand.Intn(len(required))])
+		return m, true
+	case KeywordAdditionalProperties:
+		m = copyMap(m)
+		for i := 0; i < g.attempts; i++ {
+			name := fmt.Sprintf("unexpected%d", i)
+			if _, ok := m[name]; ok {
+				continue
+			}
+			if propConstraint(c, name) == nil {
+				m[name] = g.anyValue()
+				return m, true
+			}
+		}
+	case KeywordMinProperties:
+		// Remove optional properties first, so that required ones
+		// are only missing when there is no other way
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		sort.SliceStable(names, func(i, j int) bool {
+			return !c.IsPropRequired(names[i]) && c.IsPropRequired(names[j])
+		})
+		m = copyMap(m)
+		for _, name := range names {
+			if int64(len(m)) < c.GetMinProperties() {
+				break
+			}
+			delete(m, name)
+		}
+		return m, true
+	case KeywordMaxProperties:
+		m = copyMap(m)
+		for i := 0; int64(len(m)) <= c.GetMaxProperties(); i++ {
+			if i >= g.attempts {
+				return nil, false
+			}
+			name, err := g.extraPropName(c)
+			if err != nil {
+				name = fmt.Sprintf("extra%d", i)
+			}
+			if _, ok := m[name]; ok {
+				continue
+			}
+			pc := propConstraint(c, name)
+			if pc == nil {
+				pc = validator.EmptyConstraint
+			}
+			v, err := g.generate(pc, 0)
+			if err != nil {
+				return nil, false
+			}
+			m[name] = v
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// replace returns a copy of value, where the value at path has been
+// replaced by x. Only the containers along path are copied.
+func replace(value interface{}, path []string, x interface{}) interface{} {
+	if len(path) == 0 {
+		return x
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := copyMap(v)
+		m[path[0]] = replace(v[path[0]], path[1:], x)
+		return m
+	case []interface{}:
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i >= len(v) {
+			return value
+		}
+		l := append([]interface{}(nil), v...)
+		l[i] = replace(v[i], path[1:], x)
+		return l
+	}
+	return value
+}
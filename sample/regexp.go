@@ -0,0 +1,103 @@
+package sample
+
+import (
+	"bytes"
+	"math/rand"
+	"regexp"
+	"regexp/syntax"
+
+	"github.com/pkg/errors"
+)
+
+// maxRepeat is the number of extra repetitions generated for
+// unbounded quantifiers such as `*` and `+`
+const maxRepeat = 3
+
+const (
+	letters   = "abcdefghijklmnopqrstuvwxyz"
+	alphanums = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// matchString generates a string that rx matches. Since regular
+// expressions in JSON Schema are not implicitly anchored, the string
+// may be used as part of a larger string, as long as the expression
+// is not anchored either.
+func matchString(r *rand.Rand, rx *regexp.Regexp) (string, error) {
+	re, err := syntax.Parse(rx.String(), syntax.Perl)
+	if err != nil {
+		return "", errors.Wrapf(err, `failed to parse regular expression %s`, rx)
+	}
+
+	var buf bytes.Buffer
+	writeMatch(r, &buf, re.Simplify())
+	return buf.String(), nil
+}
+
+func writeMatch(r *rand.Rand, buf *bytes.Buffer, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, c := range re.Rune {
+			buf.WriteRune(c)
+		}
+	case syntax.OpCharClass:
+		buf.WriteRune(classRune(r, re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		buf.WriteByte(alphanums[r.Intn(len(alphanums))])
+	case syntax.OpCapture:
+		writeMatch(r, buf, re.Sub[0])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, maxRepeat
+		case syntax.OpPlus:
+			min, max = 1, 1+maxRepeat
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 {
+			max = min + maxRepeat
+		}
+		n := min + r.Intn(max-min+1)
+		for i := 0; i < n; i++ {
+			writeMatch(r, buf, re.Sub[0])
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writeMatch(r, buf, sub)
+		}
+	case syntax.OpAlternate:
+		writeMatch(r, buf, re.Sub[r.Intn(len(re.Sub))])
+	}
+	// Everything else (anchors, word boundaries and empty matches)
+	// does not consume any input
+}
+
+// classRune picks a rune from a character class, which is given as a
+// list of inclusive ranges. Printable ASCII characters are preferred,
+// so that negated classes do not produce control characters.
+func classRune(r *rand.Rand, ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < 0x21 {
+			lo = 0x21
+		}
+		if hi > 0x7e {
+			hi = 0x7e
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	if len(ranges) == 0 {
+		return 'a'
+	}
+
+	i := 2 * r.Intn(len(ranges)/2)
+	lo, hi := ranges[i], ranges[i+1]
+	return lo + rune(r.Int63n(int64(hi-lo)+1))
+}
@@ -0,0 +1,586 @@
+// Package sample generates random instances from a validator: values
+// that satisfy all of its constraints, and values that violate exactly
+// the constraint you are interested in. Generators are seeded, so that
+// the same seed always produces the same sequence of values, which
+// makes them suitable for fuzzing handlers and for producing example
+// payloads.
+package sample
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-json-schema/validator"
+	"github.com/lestrrat/go-pdebug"
+	"github.com/pkg/errors"
+)
+
+// Generator generates random instances. A Generator is not safe to
+// be used from multiple goroutines at the same time.
+type Generator struct {
+	rand     *rand.Rand
+	attempts int
+	maxDepth int
+	maxItems int
+}
+
+// errTooDeep is returned when a value can only be generated by
+// following more references than allowed by MaxDepth
+var errTooDeep = errors.New("maximum depth exceeded")
+
+// New creates a new Generator, whose values are derived from seed
+func New(seed int64) *Generator {
+	return &Generator{
+		rand:     rand.New(rand.NewSource(seed)),
+		attempts: 100,
+		maxDepth: 5,
+		maxItems: 5,
+	}
+}
+
+// MaxDepth sets the number of references that are followed before
+// the generator stops producing optional values. Recursive schemas
+// that require a value beyond this depth can not be generated.
+func (g *Generator) MaxDepth(n int) *Generator {
+	g.maxDepth = n
+	return g
+}
+
+// MaxItems sets the number of items in arrays, properties in objects
+// and characters in strings, that are generated on top of what the
+// constraints require
+func (g *Generator) MaxItems(n int) *Generator {
+	g.maxItems = n
+	return g
+}
+
+// Attempts sets the number of times a value is regenerated when it
+// does not satisfy its constraint, for example because of a
+// combination of keywords that the generator can not take into
+// account at the same time
+func (g *Generator) Attempts(n int) *Generator {
+	g.attempts = n
+	return g
+}
+
+// Generate returns a random value that is valid against v. Objects
+// are generated as map[string]interface{}, arrays as []interface{},
+// and numbers as float64, just as if the value had been decoded by
+// encoding/json.
+func (g *Generator) Generate(v *validator.JSVal) (value interface{}, err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("sample.Generate").BindError(&err)
+		defer g.End()
+	}
+
+	if v == nil {
+		return nil, errors.New("validator must not be nil")
+	}
+
+	value, err = g.generate(v.Root(), 0)
+	if err != nil {
+		return nil, errors.Wrap(err, `failed to generate value`)
+	}
+	return value, nil
+}
+
+// generate returns a value that satisfies c. Values that are built
+// piece by piece are checked against c, and regenerated if they do
+// not satisfy it.
+func (g *Generator) generate(c validator.Constraint, depth int) (interface{}, error) {
+	switch c := c.(type) {
+	case nil:
+		return nil, errors.New("no value is allowed")
+	case *validator.ReferenceConstraint:
+		if depth >= g.maxDepth {
+			return nil, errTooDeep
+		}
+		resolved, err := c.Resolved()
+		if err != nil {
+			return nil, errors.Wrapf(err, `failed to resolve reference %s`, c.GetRefersTo())
+		}
+		return g.generate(resolved, depth+1)
+	case *validator.EnumConstraint:
+		return g.pick(c, c.GetEnum())
+	case *validator.BooleanConstraint:
+		return g.rand.Intn(2) == 1, nil
+	case *validator.StringConstraint:
+		return g.satisfy(c, func() (interface{}, error) { return g.generateString(c) })
+	case *validator.IntegerConstraint:
+		return g.satisfy(c, func() (interface{}, error) { return g.generateNumber(&c.NumberConstraint, true) })
+	case *validator.NumberConstraint:
+		return g.satisfy(c, func() (interface{}, error) { return g.generateNumber(c, false) })
+	case *validator.ArrayConstraint:
+		return g.satisfy(c, func() (interface{}, error) { return g.generateArray(c, depth) })
+	case *validator.ObjectConstraint:
+		return g.satisfy(c, func() (interface{}, error) { return g.generateObject(c, depth) })
+	case *validator.AnyConstraint:
+		return g.generateBranch(c, c.Constraints(), depth)
+	case *validator.OneOfConstraint:
+		return g.generateBranch(c, c.Constraints(), depth)
+	case *validator.AllConstraint:
+		return g.generateAll(c, depth)
+	case *validator.DiscriminatorConstraint:
+		return g.generateDiscriminated(c, depth)
+	case *validator.NotConstraint:
+		return g.satisfy(c, func() (interface{}, error) { return g.anyValue(), nil })
+	}
+
+	switch c {
+	case validator.EmptyConstraint:
+		return g.anyValue(), nil
+	case validator.NullConstraint:
+		return nil, nil
+	}
+	return nil, errors.Errorf(`unsupported constraint %T`, c)
+}
+
+// satisfy calls fn until it returns a value that c accepts
+func (g *Generator) satisfy(c validator.Constraint, fn func() (interface{}, error)) (interface{}, error) {
+	var lastErr error
+	for i := 0; i < g.attempts; i++ {
+		v, err := fn()
+		if err != nil {
+			return nil, err
+		}
+		if lastErr = c.Validate(v); lastErr == nil {
+			return v, nil
+		}
+	}
+	return nil, errors.Wrapf(lastErr, `failed to generate a valid value after %d attempts`, g.attempts)
+}
+
+func (g *Generator) pick(c validator.Constraint, enum []interface{}) (interface{}, error) {
+	if len(enum) == 0 {
+		return nil, errors.New("enum has no values")
+	}
+	return g.satisfy(c, func() (interface{}, error) {
+		return enum[g.rand.Intn(len(enum))], nil
+	})
+}
+
+// generateBranch generates values from the branches of a combination,
+// in random order, until one of them satisfies the whole combination.
+// It is used for anyOf and oneOf, and for allOf when its branches can
+// not be merged
+func (g *Generator) generateBranch(c validator.Constraint, branches []validator.Constraint, depth int) (interface{}, error) {
+	if len(branches) == 0 {
+		return g.satisfy(c, func() (interface{}, error) { return g.anyValue(), nil })
+	}
+
+	var lastErr error
+	for i := 0; i < g.attempts; i++ {
+		for _, n := range g.rand.Perm(len(branches)) {
+			v, err := g.generate(branches[n], depth)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if lastErr = c.Validate(v); lastErr == nil {
+				return v, nil
+			}
+		}
+		if lastErr == errTooDeep {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// generateDiscriminated generates an object from one of the constraints
+// mapped by c, and sets its discriminator property accordingly
+func (g *Generator) generateDiscriminated(c *validator.DiscriminatorConstraint, depth int) (interface{}, error) {
+	values := c.GetMappingValues()
+	if len(values) == 0 {
+		return nil, errors.New("discriminator has no mapping")
+	}
+
+	var lastErr error
+	for i := 0; i < g.attempts; i++ {
+		value := values[g.rand.Intn(len(values))]
+		v, err := g.generate(c.GetMapping(value), depth)
+		if err != nil {
+			lastErr = err
+			if err == errTooDeep {
+				break
+			}
+			continue
+		}
+		if m, ok := v.(map[string]interface{}); ok {
+			m[c.GetPropertyName()] = value
+		}
+		if lastErr = c.Validate(v); lastErr == nil {
+			return v, nil
+		}
+	}
+	return nil, lastErr
+}
+
+// anyValue generates a value of a random type
+func (g *Generator) anyValue() interface{} {
+	switch g.rand.Intn(6) {
+	case 0:
+		return nil
+	case 1:
+		return g.rand.Intn(2) == 1
+	case 2:
+		return float64(g.rand.Intn(200) - 100)
+	case 3:
+		return g.randomString(1 + g.rand.Intn(g.maxItems+1))
+	case 4:
+		return []interface{}{}
+	}
+	return map[string]interface{}{}
+}
+
+func (g *Generator) randomString(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letters[g.rand.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+func (g *Generator) generateString(c *validator.StringConstraint) (interface{}, error) {
+	if enum := c.GetEnum(); len(enum) > 0 {
+		return enum[g.rand.Intn(len(enum))], nil
+	}
+
+	if f := c.GetFormat(); f != "" {
+		if s, ok := g.formatString(f); ok {
+			return s, nil
+		}
+	}
+
+	min := int(c.GetMinLength())
+	if min < 0 {
+		min = 0
+	}
+	max := int(c.GetMaxLength())
+	if max < 0 {
+		max = min + g.maxItems
+	}
+
+	rx := c.GetRegexp()
+	if rx == nil {
+		if max < min {
+			return nil, errors.Errorf(`minLength %d is greater than maxLength %d`, min, max)
+		}
+		return g.randomString(min + g.rand.Intn(max-min+1)), nil
+	}
+
+	s, err := matchString(g.rand, rx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pad short strings on whichever side keeps them matching
+	if n := utf8.RuneCountInString(s); n < min {
+		pad := g.randomString(min - n)
+		if rx.MatchString(s + pad) {
+			s = s + pad
+		} else {
+			s = pad + s
+		}
+	}
+	return s, nil
+}
+
+// formatString generates a string in one of the formats known to
+// validator.StringConstraint
+func (g *Generator) formatString(f string) (string, bool) {
+	switch f {
+	case "datetime":
+		t := time.Unix(g.rand.Int63n(4102444800), 0).UTC()
+		return t.Format(time.RFC3339), true
+	case "email":
+		return g.randomString(1+g.rand.Intn(8)) + "@example.com", true
+	case "hostname":
+		return g.randomString(1+g.rand.Intn(8)) + ".example.com", true
+	case "ipv4":
+		return fmt.Sprintf("%d.%d.%d.%d", 1+g.rand.Intn(254), g.rand.Intn(256), g.rand.Intn(256), 1+g.rand.Intn(254)), true
+	case "ipv6":
+		// validator.StringConstraint only accepts decimal digits and
+		// colons in IPv6 addresses
+		return fmt.Sprintf("%d:%d::%d", 1+g.rand.Intn(9999), g.rand.Intn(9999), 1+g.rand.Intn(9999)), true
+	case "uri":
+		return "https://example.com/" + g.randomString(1+g.rand.Intn(8)), true
+	}
+	return "", false
+}
+
+func (g *Generator) generateNumber(c *validator.NumberConstraint, integer bool) (interface{}, error) {
+	if enum := c.GetEnum(); len(enum) > 0 {
+		return enum[g.rand.Intn(len(enum))], nil
+	}
+
+	const span = 100
+	lo, hi := -span/2.0, span/2.0
+	switch {
+	case c.HasMinimum() && c.HasMaximum():
+		lo, hi = c.GetMinimum(), c.GetMaximum()
+	case c.HasMinimum():
+		lo = c.GetMinimum()
+		hi = lo + span
+	case c.HasMaximum():
+		hi = c.GetMaximum()
+		lo = hi - span
+	}
+	if lo > hi {
+		return nil, errors.Errorf(`minimum %v is greater than maximum %v`, lo, hi)
+	}
+
+	step := 0.0
+	switch {
+	case c.HasMultipleOf():
+		step = c.GetMultipleOf()
+	case integer:
+		step = 1
+	}
+
+	if step > 0 {
+		from, to := math.Ceil(lo/step), math.Floor(hi/step)
+		if c.IsExclusiveMinimum() && from*step <= lo {
+			from++
+		}
+		if c.IsExclusiveMaximum() && to*step >= hi {
+			to--
+		}
+		if from > to {
+			return nil, errors.Errorf(`no multiple of %v between %v and %v`, step, lo, hi)
+		}
+		// Ranges that are too wide to be counted in an int64 are
+		// sampled as floats. Multiples that large are integers anyway
+		n := to - from
+		var k float64
+		if n < 1<<53 {
+			k = float64(g.rand.Int63n(int64(n) + 1))
+		} else {
+			k = math.Min(math.Floor(g.rand.Float64()*(n+1)), n)
+		}
+		return (from + k) * step, nil
+	}
+
+	// Interpolate rather than scaling hi-lo, which may overflow
+	r := g.rand.Float64()
+	f := lo*(1-r) + hi*r
+	if hi-lo > 1 && math.Abs(f) < 1<<53/100 {
+		// Two decimals are enough for example values
+		f = math.Round(f*100) / 100
+	}
+	return f, nil
+}
+
+func (g *Generator) generateArray(c *validator.ArrayConstraint, depth int) (interface{}, error) {
+	min := c.GetMinItems()
+	if min < 0 {
+		min = 0
+	}
+	max := c.GetMaxItems()
+	if max < 0 {
+		max = min + g.maxItems
+	}
+	if max < min {
+		return nil, errors.Errorf(`minItems %d is greater than maxItems %d`, min, max)
+	}
+
+	n := min + g.rand.Intn(max-min+1)
+	l := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		ic, ok := itemConstraint(c, i)
+		if !ok {
+			break
+		}
+
+		v, err := g.generateItem(ic, l, c.GetUniqueItems(), depth)
+		if err != nil {
+			// Items beyond minItems are optional
+			if i >= min {
+				break
+			}
+			return nil, errors.Wrapf(err, `failed to generate item %d`, i)
+		}
+		l = append(l, v)
+	}
+	return l, nil
+}
+
+// itemConstraint returns the constraint for the i-th item of an
+// array, or false if the array can not have that many items
+func itemConstraint(c *validator.ArrayConstraint, i int) (validator.Constraint, bool) {
+	if items := c.GetItems(); items != nil {
+		return items, true
+	}
+
+	positional := c.GetPositionalItems()
+	if i < len(positional) {
+		return positional[i], true
+	}
+	if len(positional) > 0 {
+		additional := c.GetAdditionalItems()
+		return additional, additional != nil
+	}
+	return validator.EmptyConstraint, true
+}
+
+// generateItem generates a value for ic, which must be different from
+// all items in l if unique is true
+func (g *Generator) generateItem(ic validator.Constraint, l []interface{}, unique bool, depth int) (interface{}, error) {
+	for i := 0; i < g.attempts; i++ {
+		v, err := g.generate(ic, depth)
+		if err != nil {
+			return nil, err
+		}
+		if !unique || !contains(l, v) {
+			return v, nil
+		}
+	}
+	return nil, errors.New("failed to generate a unique item")
+}
+
+func contains(l []interface{}, v interface{}) bool {
+	for _, x := range l {
+		if reflect.DeepEqual(x, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) generateObject(c *validator.ObjectConstraint, depth int) (interface{}, error) {
+	m := make(map[string]interface{})
+
+	required := c.GetRequired()
+	for _, name := range required {
+		if err := g.generateProp(c, m, name, depth); err != nil {
+			return nil, err
+		}
+	}
+
+	var optional []string
+	for _, name := range c.GetPropNames() {
+		if !c.IsPropRequired(name) {
+			optional = append(optional, name)
+		}
+	}
+
+	min := int(c.GetMinProperties())
+	max := int(c.GetMaxProperties())
+	if max < 0 {
+		max = len(required) + len(optional) + g.maxItems
+	}
+
+	// Optional properties are added with a probability of one half,
+	// or whenever they are needed to reach minProperties
+	for _, n := range g.rand.Perm(len(optional)) {
+		if len(m) >= max {
+			break
+		}
+		if len(m) >= min && g.rand.Intn(2) == 0 {
+			continue
+		}
+		// Properties that lead too deep are simply left out
+		if err := g.generateProp(c, m, optional[n], depth); err != nil && len(m) < min {
+			return nil, err
+		}
+	}
+
+	for i := 0; len(m) < min; i++ {
+		if i >= g.attempts {
+			return nil, errors.Errorf(`failed to generate %d properties`, min)
+		}
+		name, err := g.extraPropName(c)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := m[name]; ok {
+			continue
+		}
+		if err := g.generateProp(c, m, name, depth); err != nil {
+			return nil, err
+		}
+	}
+
+	// Properties that are present may require others
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, dep := range c.GetPropDependencies(name) {
+			if _, ok := m[dep]; ok {
+				continue
+			}
+			if err := g.generateProp(c, m, dep, depth); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// generateProp generates a value for the property name, and stores
+// it in m
+func (g *Generator) generateProp(c *validator.ObjectConstraint, m map[string]interface{}, name string, depth int) error {
+	v, err := g.generate(propConstraint(c, name), depth)
+	if err != nil {
+		return errors.Wrapf(err, `failed to generate property %s`, name)
+	}
+	m[name] = v
+	return nil
+}
+
+// propConstraint returns the constraint that the property name is
+// validated against, or nil if the property is not allowed. Defined
+// properties are validated against the patterns that they match, too
+func propConstraint(c *validator.ObjectConstraint, name string) validator.Constraint {
+	all := validator.All()
+	if pc := c.GetProp(name); pc != nil {
+		all.Add(pc)
+	}
+	patterns := c.GetPatternProperties()
+	for _, rx := range sortedPatterns(patterns) {
+		if rx.MatchString(name) {
+			all.Add(patterns[rx])
+		}
+	}
+	if len(all.Constraints()) == 0 {
+		return c.GetAdditionalProperties()
+	}
+	return all.Reduce()
+}
+
+// extraPropName generates the name of a property that is not listed
+// in the properties of c
+func (g *Generator) extraPropName(c *validator.ObjectConstraint) (string, error) {
+	patterns := c.GetPatternProperties()
+	if c.GetAdditionalProperties() != nil && (len(patterns) == 0 || g.rand.Intn(2) == 0) {
+		return g.randomString(1 + g.rand.Intn(8)), nil
+	}
+	if len(patterns) == 0 {
+		return "", errors.New("additional properties are not allowed")
+	}
+
+	l := sortedPatterns(patterns)
+	return matchString(g.rand, l[g.rand.Intn(len(l))])
+}
+
+// sortedPatterns returns the patterns in m sorted by their source, as
+// map iteration order would make the output differ between runs
+func sortedPatterns(m map[*regexp.Regexp]validator.Constraint) []*regexp.Regexp {
+	l := make([]*regexp.Regexp, 0, len(m))
+	for rx := range m {
+		l = append(l, rx)
+	}
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].String() < l[j].String()
+	})
+	return l
+}
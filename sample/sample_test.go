@@ -0,0 +1,277 @@
+package sample
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-json-schema/schema"
+	"github.com/go-json-schema/schema/draft04"
+	"github.com/go-json-schema/validator"
+	"github.com/go-json-schema/validator/builder"
+	"github.com/stretchr/testify/assert"
+)
+
+const personSchema = `{
+  "type": "object",
+  "properties": {
+    "id": { "type": "integer", "minimum": 1, "maximum": 1000 },
+    "name": { "type": "string", "pattern": "^[A-Z][a-z]+$", "maxLength": 20 },
+    "email": { "type": "string", "format": "email" },
+    "score": { "type": "number", "minimum": 0, "exclusiveMaximum": true, "maximum": 10, "multipleOf": 0.5 },
+    "status": { "enum": ["active", "inactive"] },
+    "tags": { "type": "array", "items": { "type": "string", "minLength": 2, "maxLength": 5 }, "minItems": 1, "maxItems": 4, "uniqueItems": true },
+    "address": { "$ref": "#/definitions/address" },
+    "children": { "type": "array", "items": { "$ref": "#" } }
+  },
+  "required": ["id", "name", "status"],
+  "additionalProperties": false,
+  "definitions": {
+    "address": {
+      "type": "object",
+      "properties": {
+        "zip": { "type": "string", "pattern": "^[0-9]{5}$" },
+        "country": { "type": "string", "minLength": 2, "maxLength": 2 }
+      },
+      "required": ["zip"],
+      "minProperties": 1,
+      "maxProperties": 2
+    }
+  }
+}`
+
+func build(t *testing.T, src string) *validator.JSVal {
+	s, err := schema.Parse(strings.NewReader(src), schema.WithSchemaID(draft04.SchemaID))
+	if !assert.NoError(t, err, "schema.Parse should succeed") {
+		return nil
+	}
+
+	v, err := builder.New().Build(s)
+	if !assert.NoError(t, err, "Builder.Build should succeed") {
+		return nil
+	}
+	return v
+}
+
+func TestGenerate(t *testing.T) {
+	v := build(t, personSchema)
+	if v == nil {
+		return
+	}
+
+	g := New(1)
+	for i := 0; i < 100; i++ {
+		value, err := g.Generate(v)
+		if !assert.NoError(t, err, "Generate should succeed") {
+			return
+		}
+		if !assert.NoError(t, v.Validate(value), "generated value should be valid: %#v", value) {
+			return
+		}
+	}
+}
+
+func TestGenerateReproducible(t *testing.T) {
+	v := build(t, personSchema)
+	if v == nil {
+		return
+	}
+
+	for seed := int64(0); seed < 10; seed++ {
+		a, err := New(seed).Generate(v)
+		if !assert.NoError(t, err, "Generate should succeed") {
+			return
+		}
+		b, err := New(seed).Generate(v)
+		if !assert.NoError(t, err, "Generate should succeed") {
+			return
+		}
+		if !assert.Equal(t, a, b, "same seed generates the same value") {
+			return
+		}
+	}
+}
+
+func TestGenerateWideRange(t *testing.T) {
+	constraints := []validator.Constraint{
+		validator.Integer().Minimum(0).Maximum(1e20),
+		validator.Integer().Minimum(-1e19).Maximum(1e19),
+		validator.Number().Minimum(0).Maximum(1e20).MultipleOf(0.5),
+		validator.Number().Minimum(-1e308).Maximum(1e308),
+	}
+
+	g := New(1)
+	for _, c := range constraints {
+		v := validator.New().SetRoot(c)
+		for i := 0; i < 100; i++ {
+			value, err := g.Generate(v)
+			if !assert.NoError(t, err, "Generate should succeed") {
+				return
+			}
+			if !assert.NoError(t, v.Validate(value), "generated value should be valid: %#v", value) {
+				return
+			}
+		}
+	}
+}
+
+func TestGenerateMaxDepth(t *testing.T) {
+	v := build(t, `{
+  "type": "object",
+  "properties": { "next": { "$ref": "#" } },
+  "required": ["next"]
+}`)
+	if v == nil {
+		return
+	}
+
+	_, err := New(1).MaxDepth(3).Generate(v)
+	if !assert.Error(t, err, "infinitely recursive values can not be generated") {
+		return
+	}
+}
+
+func TestGenerateAllOf(t *testing.T) {
+	v := build(t, `{
+  "allOf": [
+    { "$ref": "#/definitions/base" },
+    {
+      "type": "object",
+      "properties": {
+        "id": { "type": "integer", "maximum": 10 },
+        "extra": { "type": "string", "minLength": 3, "maxLength": 3 }
+      },
+      "required": ["extra"]
+    }
+  ],
+  "definitions": {
+    "base": {
+      "type": "object",
+      "properties": {
+        "id": { "type": "integer", "minimum": 5 },
+        "name": { "type": "string" }
+      },
+      "required": ["id", "name"]
+    }
+  }
+}`)
+	if v == nil {
+		return
+	}
+
+	// A value generated from a single branch is never valid, so a single
+	// attempt is only enough if the branches are merged
+	g := New(1).Attempts(1)
+	for i := 0; i < 100; i++ {
+		value, err := g.Generate(v)
+		if !assert.NoError(t, err, "Generate should succeed") {
+			return
+		}
+		if !assert.NoError(t, v.Validate(value), "generated value should be valid: %#v", value) {
+			return
+		}
+	}
+}
+
+func TestMutate(t *testing.T) {
+	v := build(t, personSchema)
+	if v == nil {
+		return
+	}
+
+	keywords := []string{
+		KeywordType,
+		KeywordEnum,
+		KeywordMinLength,
+		KeywordMaxLength,
+		KeywordPattern,
+		KeywordFormat,
+		KeywordMinimum,
+		KeywordMaximum,
+		KeywordMultipleOf,
+		KeywordMinItems,
+		KeywordUniqueItems,
+		KeywordRequired,
+		KeywordAdditionalProperties,
+		KeywordMinProperties,
+		KeywordMaxProperties,
+	}
+
+	g := New(1)
+	for _, keyword := range keywords {
+		keyword := keyword
+		t.Run(keyword, func(t *testing.T) {
+			m, err := g.Mutate(v, keyword)
+			if !assert.NoError(t, err, "Mutate should succeed") {
+				return
+			}
+			t.Logf("%s: %#v", m.Pointer, m.Value)
+
+			if !assert.Equal(t, keyword, m.Keyword, "keyword matches") {
+				return
+			}
+			if !assert.Error(t, v.Validate(m.Value), "mutated value should be invalid") {
+				return
+			}
+		})
+	}
+}
+
+func TestMutateArray(t *testing.T) {
+	v := validator.New().SetRoot(
+		validator.Object().
+			AddProp("point", validator.Array().
+				PositionalItems([]validator.Constraint{validator.Number(), validator.Number()}).
+				AdditionalItems(nil)).
+			AddProp("tags", validator.Array().
+				Items(validator.String()).
+				MaxItems(3)),
+	)
+
+	g := New(1)
+	for _, keyword := range []string{KeywordAdditionalItems, KeywordMaxItems} {
+		m, err := g.Mutate(v, keyword)
+		if !assert.NoError(t, err, "Mutate should succeed") {
+			return
+		}
+		t.Logf("%s: %#v", m.Pointer, m.Value)
+
+		if !assert.Error(t, v.Validate(m.Value), "mutated value should be invalid") {
+			return
+		}
+	}
+}
+
+func TestGenerateDiscriminator(t *testing.T) {
+	v := validator.New().SetRoot(validator.Discriminator("kind").
+		Map("cat", validator.Object().
+			AddProp("kind", validator.String()).
+			AddProp("lives", validator.Integer().Minimum(1).Maximum(9)).
+			Required("kind", "lives")).
+		Map("dog", validator.Object().
+			AddProp("kind", validator.String()).
+			Required("kind")),
+	)
+
+	g := New(3)
+	for i := 0; i < 10; i++ {
+		x, err := g.Generate(v)
+		if !assert.NoError(t, err, "Generate should succeed") {
+			return
+		}
+		if !assert.NoError(t, v.Validate(x), "generated value should be valid") {
+			return
+		}
+	}
+}
+
+func TestMutateUnknownKeyword(t *testing.T) {
+	v := build(t, `{ "type": "string" }`)
+	if v == nil {
+		return
+	}
+
+	_, err := New(1).Attempts(5).Mutate(v, KeywordMinimum)
+	if !assert.Error(t, err, "keywords not in the schema can not be violated") {
+		return
+	}
+}
@@ -0,0 +1,281 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/lestrrat/go-pdebug"
+	"github.com/pkg/errors"
+)
+
+// ValidateJSON validates the raw JSON document in data. It is the
+// same as calling ValidateReader with a reader over data.
+func (v *JSVal) ValidateJSON(data []byte) error {
+	return v.ValidateReader(bytes.NewReader(data))
+}
+
+// ValidateReader validates the JSON document read from r.
+func (v *JSVal) ValidateReader(r io.Reader) error {
+	return v.ValidateReaderContext(context.Background(), r)
+}
+
+// ValidateReaderContext validates the JSON document read from r,
+// without unmarshaling the whole document first.
+//
+// If the root constraint is an ArrayConstraint, the top-level array is
+// read from the token stream one element at a time, and each element is
+// validated and discarded before the next one is read. This allows
+// arbitrarily large arrays of records to be validated with memory
+// proportional to the size of a single record. Note that if the
+// constraint requires unique items, a key for each element must still
+// be kept around. As the array as a whole is never in memory, $data
+// references must point within the element. Any other document is
+// decoded as a whole before being validated.
+//
+// Numbers are decoded as json.Number so that they can be compared
+// against the constraints without losing precision.
+//
+// When validation fails, the returned error carries a ValidationError
+// that reports both the JSON pointer to the offending value and the
+// byte offset at which that value starts.
+func (v *JSVal) ValidateReaderContext(ctx context.Context, r io.Reader) (err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("JSVal.ValidateReaderContext").BindError(&err)
+		defer g.End()
+	}
+
+	ctx = v.withDirection(ctx)
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	if ac, ok := streamableArray(v.root); ok {
+		err = validateArrayStream(ctx, dec, ac)
+	} else {
+		err = validateValueStream(ctx, dec, v.root)
+	}
+	if err != nil {
+		return v.wrapError(err)
+	}
+
+	if dec.More() {
+		return v.wrapError(errors.Errorf("unexpected data after top-level value (offset %d)", dec.InputOffset()))
+	}
+	return nil
+}
+
+// streamableArray returns the ArrayConstraint that c boils down to,
+// if any
+func streamableArray(c Constraint) (*ArrayConstraint, bool) {
+	for {
+		switch v := c.(type) {
+		case *ArrayConstraint:
+			return v, true
+		case *ReferenceConstraint:
+			resolved, err := v.Resolved()
+			if err != nil {
+				return nil, false
+			}
+			c = resolved
+		default:
+			return nil, false
+		}
+	}
+}
+
+// decodeValue reads the next JSON value from dec, and returns it along
+// with its raw bytes and the byte offset at which it starts
+func decodeValue(dec *json.Decoder) (interface{}, json.RawMessage, int64, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, nil, -1, errors.Wrap(err, `failed to decode JSON`)
+	}
+	offset := dec.InputOffset() - int64(len(raw))
+
+	rdec := json.NewDecoder(bytes.NewReader(raw))
+	rdec.UseNumber()
+	var x interface{}
+	if err := rdec.Decode(&x); err != nil {
+		return nil, nil, offset, errors.Wrap(err, `failed to decode JSON`)
+	}
+	return x, raw, offset, nil
+}
+
+// withOffset records in the ValidationError carried by err the offset
+// of the value that failed. raw is the value that starts at offset,
+// and skip is the number of leading tokens of the error's pointer that
+// lead to raw itself
+func withOffset(err error, raw json.RawMessage, offset int64, skip int) error {
+	if verr, ok := AsValidationError(err); ok {
+		verr.Offset = offset
+		if tokens, perr := splitPointer(verr.Pointer); perr == nil && len(tokens) >= skip {
+			if rel, ok := locateValue(raw, tokens[skip:]); ok {
+				verr.Offset = offset + rel
+			}
+		}
+		return err
+	}
+
+	if isAborted(err) {
+		return err
+	}
+	return &ValidationError{Offset: offset, Err: err}
+}
+
+// locateValue returns the offset within raw at which the value that
+// tokens refer to starts. It is only called once validation failed, so
+// that offsets are not tracked for every value that passes
+func locateValue(raw json.RawMessage, tokens []string) (int64, bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	pos := skipSeparators(raw, 0)
+	for _, token := range tokens {
+		tok, err := dec.Token()
+		if err != nil {
+			return 0, false
+		}
+		d, ok := tok.(json.Delim)
+		if !ok {
+			return 0, false
+		}
+
+		found := false
+		for i := 0; !found && dec.More(); i++ {
+			var name string
+			if d == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return 0, false
+				}
+				name, _ = key.(string)
+			} else {
+				name = strconv.Itoa(i)
+			}
+
+			if name == token {
+				pos = skipSeparators(raw, dec.InputOffset())
+				found = true
+				break
+			}
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return 0, false
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return pos, true
+}
+
+// skipSeparators returns the offset of the first byte at or after pos
+// that is not whitespace, a comma or a colon
+func skipSeparators(raw json.RawMessage, pos int64) int64 {
+	for ; pos < int64(len(raw)); pos++ {
+		switch raw[pos] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+func validateValueStream(ctx context.Context, dec *json.Decoder, c Constraint) error {
+	x, raw, offset, err := decodeValue(dec)
+	if err != nil {
+		return err
+	}
+
+	if err := validateContext(ctx, c, x); err != nil {
+		return withOffset(err, raw, offset, 0)
+	}
+	return nil
+}
+
+func validateArrayStream(ctx context.Context, dec *json.Decoder, c *ArrayConstraint) error {
+	ctx, f := startRun(ctx)
+	if t := f.run.trace; t != nil {
+		// The array is the root of the trace, and its elements are
+		// recorded as its children
+		node := t.enter(c, f.pointer())
+		err := streamArray(ctx, f, dec, c)
+		t.leave(node, err)
+		return err
+	}
+	return streamArray(ctx, f, dec, c)
+}
+
+func streamArray(ctx context.Context, f *frame, dec *json.Decoder, c *ArrayConstraint) error {
+	if err := f.visit(); err != nil {
+		return err
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return errors.Wrap(err, `failed to read JSON token`)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		var typ string
+		switch tok.(type) {
+		case json.Delim:
+			typ = "object"
+		case nil:
+			typ = "null"
+		default:
+			typ = fmt.Sprintf("%T", tok)
+		}
+		return &ValidationError{Offset: 0, Err: errors.New("value must be a slice (was: " + typ + ")")}
+	}
+	start := dec.InputOffset() - 1
+
+	var uitems map[string]struct{}
+	if c.uniqueItems {
+		uitems = make(map[string]struct{})
+	}
+
+	l := 0
+	for ; dec.More(); l++ {
+		x, raw, offset, err := decodeValue(dec)
+		if err != nil {
+			return err
+		}
+
+		if mi := c.maxItems; mi > -1 && l >= mi {
+			return &ValidationError{Offset: start, Err: errors.New("more items than maxItems")}
+		}
+
+		if uitems != nil {
+			kv := fmt.Sprintf("%s", x)
+			if _, ok := uitems[kv]; ok {
+				return &ValidationError{Pointer: "/" + strconv.Itoa(l), Offset: offset, Err: errors.New("duplicate element found")}
+			}
+			uitems[kv] = struct{}{}
+		}
+
+		celem, err := c.itemConstraint(l)
+		if err != nil {
+			return &ValidationError{Pointer: "/" + strconv.Itoa(l), Offset: offset, Err: err}
+		}
+		if celem == nil {
+			continue
+		}
+
+		if err := validateChild(ctx, strconv.Itoa(l), celem, x); err != nil {
+			return withOffset(err, raw, offset, 1)
+		}
+	}
+
+	// consume the closing ']'
+	if _, err := dec.Token(); err != nil {
+		return errors.Wrap(err, `failed to read JSON token`)
+	}
+
+	if mi := c.minItems; mi > -1 && l < mi {
+		return &ValidationError{Offset: start, Err: errors.New("fewer items than minItems")}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package validator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-json-schema/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateJSON(t *testing.T) {
+	record := validator.Object().
+		AddProp(`id`, validator.Integer().Minimum(0)).
+		AddProp(`name`, validator.String().MaxLength(5)).
+		Required(`id`)
+
+	t.Run("array of records", func(t *testing.T) {
+		v := validator.New().SetRoot(validator.Array().Items(record))
+
+		const good = `[{"id": 1, "name": "foo"}, {"id": 2}]`
+		if !assert.NoError(t, v.ValidateJSON([]byte(good)), "validation passes") {
+			return
+		}
+
+		const bad = `[{"id": 1, "name": "foo"},
+ {"id": 2, "name": "foobar"}]`
+		err := v.ValidateJSON([]byte(bad))
+		if !assert.Error(t, err, "validation fails") {
+			return
+		}
+
+		verr, ok := validator.AsValidationError(err)
+		if !assert.True(t, ok, "error should carry a ValidationError") {
+			return
+		}
+		if !assert.Equal(t, "/1/name", verr.Pointer, "pointer matches") {
+			return
+		}
+		if !assert.Equal(t, int64(strings.Index(bad, `"foobar"`)), verr.Offset, "offset matches") {
+			return
+		}
+	})
+	t.Run("item count", func(t *testing.T) {
+		v := validator.New().SetRoot(validator.Array().Items(record).MinItems(1).MaxItems(2))
+
+		for _, src := range []string{`[]`, `[{"id": 1}, {"id": 2}, {"id": 3}]`, `{"id": 1}`} {
+			if !assert.Error(t, v.ValidateJSON([]byte(src)), "validation fails for %s", src) {
+				return
+			}
+		}
+	})
+	t.Run("non-array root", func(t *testing.T) {
+		v := validator.New().SetRoot(record)
+		if !assert.NoError(t, v.ValidateReader(strings.NewReader(`{"id": 10}`)), "validation passes") {
+			return
+		}
+		if !assert.Error(t, v.ValidateReader(strings.NewReader(`{"id": 10} {"id": 11}`)), "trailing data is rejected") {
+			return
+		}
+	})
+	t.Run("offset of nested values", func(t *testing.T) {
+		v := validator.New().SetRoot(validator.Object().
+			AddProp("p", validator.Object().
+				AddProp("tags", validator.Array().Items(validator.String()))))
+
+		const src = `{"a": [1, {"p": 2}], "p": {"tags" : ["x", 3]}}`
+		err := v.ValidateJSON([]byte(src))
+		if !assert.Error(t, err, "validation fails") {
+			return
+		}
+		verr, ok := validator.AsValidationError(err)
+		if !assert.True(t, ok, "error should carry a ValidationError") {
+			return
+		}
+		if !assert.Equal(t, "/p/tags/1", verr.Pointer, "pointer matches") {
+			return
+		}
+		if !assert.Equal(t, int64(strings.Index(src, `3]`)), verr.Offset, "offset matches") {
+			return
+		}
+	})
+	t.Run("exact numbers", func(t *testing.T) {
+		v := validator.New().SetRoot(validator.Integer().Maximum(9007199254740993))
+
+		// 9007199254740993 cannot be represented exactly as a float64
+		if !assert.Error(t, v.ValidateJSON([]byte(`9007199254740993`)), "validation fails") {
+			return
+		}
+		if !assert.NoError(t, v.ValidateJSON([]byte(`9007199254740992`)), "validation passes") {
+			return
+		}
+		if !assert.Error(t, v.ValidateJSON([]byte(`1.5`)), "validation fails") {
+			return
+		}
+		if !assert.Error(t, validator.New().SetRoot(validator.String()).ValidateJSON([]byte(`1`)), "numbers are not strings") {
+			return
+		}
+	})
+}
@@ -1,12 +1,14 @@
 package validator
 
 import (
+	"encoding/json"
 	"net"
 	"net/mail"
 	"net/url"
 	"reflect"
 	"regexp"
 	"time"
+	"unicode/utf8"
 
 	"github.com/lestrrat/go-pdebug"
 	"github.com/pkg/errors"
@@ -37,6 +39,11 @@ func (sc *StringConstraint) Validate(v interface{}) (err error) {
 			}
 		}()
 	}
+	// json.Number is a string type, but it represents a number
+	if _, ok := v.(json.Number); ok {
+		return errors.New("value is not a string (Kind: number)")
+	}
+
 	rv := reflect.ValueOf(v)
 	switch rv.Kind() {
 	case reflect.Ptr, reflect.Interface:
@@ -50,7 +57,7 @@ func (sc *StringConstraint) Validate(v interface{}) (err error) {
 	}
 
 	str := rv.String()
-	ls := int64(len(str))
+	ls := int64(utf8.RuneCountInString(str))
 	if sc.maxLength > -1 {
 		if pdebug.Enabled {
 			pdebug.Printf("Checking MaxLength (%d)", sc.maxLength)
@@ -188,13 +195,15 @@ func (sc *StringConstraint) Enum(l ...interface{}) *StringConstraint {
 	return sc
 }
 
-// MaxLength specifies the maximum length the passed value can have
+// MaxLength specifies the maximum length the passed value can have,
+// counted in Unicode code points
 func (sc *StringConstraint) MaxLength(l int64) *StringConstraint {
 	sc.maxLength = l
 	return sc
 }
 
-// MinLength specifies the minimum length the passed value must have
+// MinLength specifies the minimum length the passed value must have,
+// counted in Unicode code points
 func (sc *StringConstraint) MinLength(l int64) *StringConstraint {
 	sc.minLength = l
 	return sc
@@ -219,6 +228,37 @@ func (sc *StringConstraint) Format(f string) *StringConstraint {
 	return sc
 }
 
+// GetEnum returns the enumeration of the possible values, or nil if
+// unspecified
+func (sc *StringConstraint) GetEnum() []interface{} {
+	if sc.enums == nil {
+		return nil
+	}
+	return sc.enums.GetEnum()
+}
+
+// GetMaxLength returns the maximum length, or -1 if unspecified
+func (sc *StringConstraint) GetMaxLength() int64 {
+	return sc.maxLength
+}
+
+// GetMinLength returns the minimum length
+func (sc *StringConstraint) GetMinLength() int64 {
+	return sc.minLength
+}
+
+// GetRegexp returns the regular expression that the value must
+// conform to, or nil if unspecified
+func (sc *StringConstraint) GetRegexp() *regexp.Regexp {
+	return sc.regexp
+}
+
+// GetFormat returns the format that the value must conform to, or
+// an empty string if unspecified
+func (sc *StringConstraint) GetFormat() string {
+	return sc.format
+}
+
 // String creates a new StringConstraint. It unfortunately overlaps
 // the `Stringer` interface :/
 func String() *StringConstraint {
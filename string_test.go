@@ -63,4 +63,33 @@ func TestString(t *testing.T) {
 	if !assert.NoError(t, c.Validate(s), "validate should succeed") {
 		return
 	}
+
+	// Lengths are counted in code points, not bytes
+	c = validator.String().MinLength(2).MaxLength(2)
+	if !assert.NoError(t, c.Validate("日本"), "two code points are valid") {
+		return
+	}
+	if !assert.Error(t, c.Validate("日"), "one code point is invalid") {
+		return
+	}
+}
+
+func TestStringGetters(t *testing.T) {
+	c := validator.String().MinLength(1).MaxLength(5).RegexpString(`^[a-z]+$`).Format("hostname").Enum("foo", "bar")
+
+	if !assert.Equal(t, int64(1), c.GetMinLength(), "minLength matches") {
+		return
+	}
+	if !assert.Equal(t, int64(5), c.GetMaxLength(), "maxLength matches") {
+		return
+	}
+	if !assert.Equal(t, `^[a-z]+$`, c.GetRegexp().String(), "pattern matches") {
+		return
+	}
+	if !assert.Equal(t, "hostname", c.GetFormat(), "format matches") {
+		return
+	}
+	if !assert.Equal(t, []interface{}{"foo", "bar"}, c.GetEnum(), "enum matches") {
+		return
+	}
 }
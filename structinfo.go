@@ -10,6 +10,9 @@ import (
 type PropInfo struct {
 	// Name of the Field that maps to this property
 	FieldName string
+	// Index is the index sequence of the field, suitable for
+	// `reflect.Value.FieldByIndex()`
+	Index []int
 	// IsMaybe is true if this property implements the Maybe interface
 	IsMaybe bool
 }
@@ -21,7 +24,7 @@ type StructInfo struct {
 
 type StructInfoRegistry struct {
 	lock     sync.RWMutex
-	registry map[reflect.Type]StructInfo
+	registry map[reflect.Type]*StructInfo
 }
 
 func (si *StructInfo) FieldName(pname string) (string, bool) {
@@ -35,6 +38,19 @@ func (si *StructInfo) FieldName(pname string) (string, bool) {
 	return pinfo.FieldName, true
 }
 
+// Field returns the value of the field that maps to the given property
+// name, or the zero reflect.Value if there is no such field
+func (si *StructInfo) Field(rv reflect.Value, pname string) reflect.Value {
+	si.lock.RLock()
+	pinfo, ok := si.props[pname]
+	si.lock.RUnlock()
+
+	if !ok {
+		return zeroval
+	}
+	return rv.FieldByIndex(pinfo.Index)
+}
+
 // Gets the list of property names for this particuar instance of a
 // struct. Uninitialized types are not considered, so we remove
 // them depending on the state of this instance of the struct
@@ -45,10 +61,7 @@ func (si *StructInfo) PropNames(rv reflect.Value) []string {
 	pnames := make([]string, 0, len(si.props))
 	for pname, pinfo := range si.props {
 		if pinfo.IsMaybe {
-			fv := rv.FieldByName(pinfo.FieldName)
-			mv := fv.MethodByName("Valid")
-			out := mv.Call(nil)
-			if !out[0].Bool() {
+			if mv, ok := maybeValue(rv.FieldByIndex(pinfo.Index)); ok && !mv.Valid() {
 				continue
 			}
 		}
@@ -58,7 +71,18 @@ func (si *StructInfo) PropNames(rv reflect.Value) []string {
 	return pnames
 }
 
+// Lookup returns a copy of the StructInfo registered for t
 func (r *StructInfoRegistry) Lookup(t reflect.Type) (StructInfo, bool) {
+	si, ok := r.LookupPtr(t)
+	if !ok {
+		return StructInfo{}, false
+	}
+	return StructInfo{props: si.props}, true
+}
+
+// LookupPtr is the same as Lookup, but returns the registered
+// StructInfo itself rather than a copy
+func (r *StructInfoRegistry) LookupPtr(t reflect.Type) (*StructInfo, bool) {
 	switch t.Kind() {
 	case reflect.Ptr, reflect.Interface:
 		t = t.Elem()
@@ -71,7 +95,16 @@ func (r *StructInfoRegistry) Lookup(t reflect.Type) (StructInfo, bool) {
 	return si, ok
 }
 
+// Register extracts the properties of t, unless it has already been
+// registered, and returns a copy of its StructInfo
 func (r *StructInfoRegistry) Register(t reflect.Type) StructInfo {
+	si := r.RegisterPtr(t)
+	return StructInfo{props: si.props}
+}
+
+// RegisterPtr is the same as Register, but returns the registered
+// StructInfo itself rather than a copy
+func (r *StructInfoRegistry) RegisterPtr(t reflect.Type) *StructInfo {
 	if pdebug.Enabled {
 		g := pdebug.Marker("StructInfoRegistry.Register (%s)", t.Name())
 		defer g.End()
@@ -102,7 +135,7 @@ func (r *StructInfoRegistry) Register(t reflect.Type) StructInfo {
 		}
 	}
 
-	si = StructInfo{props: props}
+	si = &StructInfo{props: props}
 	r.lock.Lock()
 	r.registry[t] = si
 	r.lock.Unlock()
@@ -111,6 +144,32 @@ func (r *StructInfoRegistry) Register(t reflect.Type) StructInfo {
 
 var maybeif = reflect.TypeOf((*Maybe)(nil)).Elem()
 
+// maybeValuer is the read-only part of the Maybe interface. Types
+// implementing Maybe usually implement these with value receivers,
+// so this can be checked against non-addressable values
+type maybeValuer interface {
+	Valid() bool
+	Value() interface{}
+}
+
+// maybeValue returns the given value as a maybeValuer, if possible
+func maybeValue(rv reflect.Value) (maybeValuer, bool) {
+	if !rv.IsValid() || !rv.CanInterface() {
+		return nil, false
+	}
+
+	if mv, ok := rv.Interface().(maybeValuer); ok {
+		return mv, true
+	}
+
+	if rv.CanAddr() {
+		if mv, ok := rv.Addr().Interface().(maybeValuer); ok {
+			return mv, true
+		}
+	}
+	return nil, false
+}
+
 func extract(t reflect.Type) map[string]PropInfo {
 	props := make(map[string]PropInfo)
 	for i := 0; i < t.NumField(); i++ {
@@ -118,6 +177,7 @@ func extract(t reflect.Type) map[string]PropInfo {
 		if fv.Anonymous {
 			info := extract(fv.Type)
 			for k, v := range info {
+				v.Index = append([]int{i}, v.Index...)
 				props[k] = v
 			}
 			continue
@@ -144,6 +204,7 @@ func extract(t reflect.Type) map[string]PropInfo {
 		if tag == "" || tag[0] == ',' {
 			props[fv.Name] = PropInfo{
 				FieldName: fv.Name,
+				Index:     fv.Index,
 				IsMaybe:   isMaybe,
 			}
 			continue
@@ -158,6 +219,7 @@ func extract(t reflect.Type) map[string]PropInfo {
 		}
 		props[tag[:flen+1]] = PropInfo{
 			FieldName: fv.Name,
+			Index:     fv.Index,
 			IsMaybe:   isMaybe,
 		}
 	}
@@ -0,0 +1,451 @@
+package validator_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-json-schema/schema"
+	"github.com/go-json-schema/schema/draft04"
+	"github.com/go-json-schema/schema/draft07"
+	"github.com/go-json-schema/validator/builder"
+)
+
+// suiteDir is where the fixtures from the official JSON Schema test
+// suite (https://github.com/json-schema-org/JSON-Schema-Test-Suite)
+// are vendored
+const suiteDir = "testdata/JSON-Schema-Test-Suite/tests"
+
+// suiteDrafts lists the drafts that the suite is run against, along
+// with the schema ID that is used to parse their schemas. Drafts 2019-09
+// and 2020-12 are not listed, as the schema package can not parse them.
+var suiteDrafts = []struct {
+	dir      string
+	schemaID string
+}{
+	{"draft4", draft04.SchemaID},
+	{"draft7", draft07.SchemaID},
+}
+
+// Reasons shared by many entries of suiteSkip
+const (
+	skipTypeInference = "the builder infers the type from the keywords, and rejects values of other types"
+	skipDraft07       = "the builder does not implement draft-07 keywords yet"
+	skipRemoteRef     = "remote references are not fetched"
+)
+
+// suiteSkip lists the known gaps. Each key is the full name of a test
+// case (draft/file/group/test) that is expected to fail. Skipped cases
+// are still run and counted towards the pass rate, but their failures
+// are not reported as errors. A skipped case that passes, or an entry
+// that does not match any case, is reported as an error, so that the
+// list does not go stale.
+var suiteSkip = map[string]string{
+
+	"draft4/additionalItems.json/additionalItems as false without items/ignores non-arrays":                                       skipTypeInference,
+	"draft4/additionalProperties.json/additionalProperties being false does not allow other properties/ignores arrays":            skipTypeInference,
+	"draft4/additionalProperties.json/additionalProperties being false does not allow other properties/ignores other non-objects": skipTypeInference,
+	"draft4/additionalProperties.json/additionalProperties being false does not allow other properties/ignores strings":           skipTypeInference,
+	"draft4/anyOf.json/anyOf/second anyOf valid":                                                                                            "keywords of different types are combined into a single type",
+	"draft4/definitions.json/invalid definition/invalid definition schema":                                                                  "references to the metaschema are not supported",
+	"draft4/definitions.json/valid definition/valid definition schema":                                                                      "references to the metaschema are not supported",
+	"draft4/dependencies.json/dependencies/ignores arrays":                                                                                  skipTypeInference,
+	"draft4/dependencies.json/dependencies/ignores other non-objects":                                                                       skipTypeInference,
+	"draft4/dependencies.json/dependencies/ignores strings":                                                                                 skipTypeInference,
+	"draft4/enum.json/heterogeneous enum validation/one of the enum is valid":                                                               "enum values of different types are not supported",
+	"draft4/items.json/a schema given for items/JavaScript pseudo-array is valid":                                                           skipTypeInference,
+	"draft4/items.json/a schema given for items/ignores non-arrays":                                                                         skipTypeInference,
+	"draft4/items.json/an array of schemas for items/JavaScript pseudo-array is valid":                                                      skipTypeInference,
+	"draft4/maxItems.json/maxItems validation/ignores non-arrays":                                                                           skipTypeInference,
+	"draft4/maxLength.json/maxLength validation/ignores non-strings":                                                                        skipTypeInference,
+	"draft4/maxProperties.json/maxProperties validation/ignores arrays":                                                                     skipTypeInference,
+	"draft4/maxProperties.json/maxProperties validation/ignores other non-objects":                                                          skipTypeInference,
+	"draft4/maxProperties.json/maxProperties validation/ignores strings":                                                                    skipTypeInference,
+	"draft4/maximum.json/exclusiveMaximum validation/below the maximum is still valid":                                                      "maximum makes the value an integer",
+	"draft4/maximum.json/maximum validation/below the maximum is valid":                                                                     "maximum makes the value an integer",
+	"draft4/maximum.json/maximum validation/ignores non-numbers":                                                                            "maximum makes the value an integer",
+	"draft4/minItems.json/minItems validation/ignores non-arrays":                                                                           skipTypeInference,
+	"draft4/minLength.json/minLength validation/ignores non-strings":                                                                        skipTypeInference,
+	"draft4/minProperties.json/minProperties validation/ignores arrays":                                                                     skipTypeInference,
+	"draft4/minProperties.json/minProperties validation/ignores other non-objects":                                                          skipTypeInference,
+	"draft4/minProperties.json/minProperties validation/ignores strings":                                                                    skipTypeInference,
+	"draft4/minimum.json/minimum validation/ignores non-numbers":                                                                            skipTypeInference,
+	"draft4/multipleOf.json/by int/ignores non-numbers":                                                                                     skipTypeInference,
+	"draft4/multipleOf.json/by small number/0.0075 is multiple of 0.0001":                                                                   "multipleOf is subject to floating point errors",
+	"draft4/oneOf.json/oneOf/second oneOf valid":                                                                                            "keywords of different types are combined into a single type",
+	"draft4/pattern.json/pattern validation/ignores non-strings":                                                                            skipTypeInference,
+	"draft4/patternProperties.json/patternProperties validates properties matching a regex/ignores arrays":                                  skipTypeInference,
+	"draft4/patternProperties.json/patternProperties validates properties matching a regex/ignores other non-objects":                       skipTypeInference,
+	"draft4/patternProperties.json/patternProperties validates properties matching a regex/ignores strings":                                 skipTypeInference,
+	"draft4/properties.json/object properties validation/ignores arrays":                                                                    skipTypeInference,
+	"draft4/properties.json/object properties validation/ignores other non-objects":                                                         skipTypeInference,
+	"draft4/ref.json/Recursive references between schemas/invalid tree":                                                                     "references relative to id are not supported",
+	"draft4/ref.json/Recursive references between schemas/valid tree":                                                                       "references relative to id are not supported",
+	"draft4/ref.json/escaped pointer ref/percent invalid":                                                                                   "escaped JSON pointers are not supported",
+	"draft4/ref.json/escaped pointer ref/percent valid":                                                                                     "escaped JSON pointers are not supported",
+	"draft4/ref.json/escaped pointer ref/slash invalid":                                                                                     "escaped JSON pointers are not supported",
+	"draft4/ref.json/escaped pointer ref/slash valid":                                                                                       "escaped JSON pointers are not supported",
+	"draft4/ref.json/escaped pointer ref/tilda invalid":                                                                                     "escaped JSON pointers are not supported",
+	"draft4/ref.json/escaped pointer ref/tilda valid":                                                                                       "escaped JSON pointers are not supported",
+	"draft4/ref.json/remote ref, containing refs itself/remote ref invalid":                                                                 skipRemoteRef,
+	"draft4/ref.json/remote ref, containing refs itself/remote ref valid":                                                                   skipRemoteRef,
+	"draft4/ref.json/root pointer ref/match":                                                                                                "references to the root schema are not supported",
+	"draft4/ref.json/root pointer ref/recursive match":                                                                                      "references to the root schema are not supported",
+	"draft4/refRemote.json/base URI change - change folder in subschema/number is valid":                                                    skipRemoteRef,
+	"draft4/refRemote.json/base URI change - change folder in subschema/string is invalid":                                                  skipRemoteRef,
+	"draft4/refRemote.json/base URI change - change folder/number is valid":                                                                 skipRemoteRef,
+	"draft4/refRemote.json/base URI change - change folder/string is invalid":                                                               skipRemoteRef,
+	"draft4/refRemote.json/base URI change/base URI change ref invalid":                                                                     skipRemoteRef,
+	"draft4/refRemote.json/base URI change/base URI change ref valid":                                                                       skipRemoteRef,
+	"draft4/refRemote.json/fragment within remote ref/remote fragment invalid":                                                              skipRemoteRef,
+	"draft4/refRemote.json/fragment within remote ref/remote fragment valid":                                                                skipRemoteRef,
+	"draft4/refRemote.json/ref within remote ref/ref within ref invalid":                                                                    skipRemoteRef,
+	"draft4/refRemote.json/ref within remote ref/ref within ref valid":                                                                      skipRemoteRef,
+	"draft4/refRemote.json/remote ref/remote ref invalid":                                                                                   skipRemoteRef,
+	"draft4/refRemote.json/remote ref/remote ref valid":                                                                                     skipRemoteRef,
+	"draft4/refRemote.json/root ref in remote ref/null is valid":                                                                            skipRemoteRef,
+	"draft4/refRemote.json/root ref in remote ref/object is invalid":                                                                        skipRemoteRef,
+	"draft4/refRemote.json/root ref in remote ref/string is valid":                                                                          skipRemoteRef,
+	"draft4/required.json/required validation/ignores arrays":                                                                               skipTypeInference,
+	"draft4/required.json/required validation/ignores other non-objects":                                                                    skipTypeInference,
+	"draft4/required.json/required validation/ignores strings":                                                                              skipTypeInference,
+	"draft7/additionalItems.json/additionalItems as schema/additional items do not match schema":                                            skipDraft07,
+	"draft7/additionalItems.json/array of items with no additionalItems/additional items are not permitted":                                 skipDraft07,
+	"draft7/additionalProperties.json/additionalProperties allows a schema which should validate/an additional invalid property is invalid": skipDraft07,
+	"draft7/additionalProperties.json/additionalProperties being false does not allow other properties/an additional property is invalid":   skipDraft07,
+	"draft7/additionalProperties.json/additionalProperties can exist by itself/an additional invalid property is invalid":                   skipDraft07,
+	"draft7/allOf.json/allOf simple types/mismatch one":                                                                                     skipDraft07,
+	"draft7/allOf.json/allOf with base schema/mismatch base schema":                                                                         skipDraft07,
+	"draft7/allOf.json/allOf with base schema/mismatch both":                                                                                skipDraft07,
+	"draft7/allOf.json/allOf with base schema/mismatch first allOf":                                                                         skipDraft07,
+	"draft7/allOf.json/allOf with base schema/mismatch second allOf":                                                                        skipDraft07,
+	"draft7/allOf.json/allOf with boolean schemas, all false/any value is invalid":                                                          skipDraft07,
+	"draft7/allOf.json/allOf with boolean schemas, some false/any value is invalid":                                                         skipDraft07,
+	"draft7/allOf.json/allOf/mismatch first":                                                                                                skipDraft07,
+	"draft7/allOf.json/allOf/mismatch second":                                                                                               skipDraft07,
+	"draft7/allOf.json/allOf/wrong type":                                                                                                    skipDraft07,
+	"draft7/anyOf.json/anyOf complex types/neither anyOf valid (complex)":                                                                   skipDraft07,
+	"draft7/anyOf.json/anyOf with base schema/both anyOf invalid":                                                                           skipDraft07,
+	"draft7/anyOf.json/anyOf with base schema/mismatch base schema":                                                                         skipDraft07,
+	"draft7/anyOf.json/anyOf with boolean schemas, all false/any value is invalid":                                                          skipDraft07,
+	"draft7/anyOf.json/anyOf/neither anyOf valid":                                                                                           skipDraft07,
+	"draft7/boolean_schema.json/boolean schema 'false'/array is invalid":                                                                    skipDraft07,
+	"draft7/boolean_schema.json/boolean schema 'false'/boolean false is invalid":                                                            skipDraft07,
+	"draft7/boolean_schema.json/boolean schema 'false'/boolean true is invalid":                                                             skipDraft07,
+	"draft7/boolean_schema.json/boolean schema 'false'/empty array is invalid":                                                              skipDraft07,
+	"draft7/boolean_schema.json/boolean schema 'false'/empty object is invalid":                                                             skipDraft07,
+	"draft7/boolean_schema.json/boolean schema 'false'/null is invalid":                                                                     skipDraft07,
+	"draft7/boolean_schema.json/boolean schema 'false'/number is invalid":                                                                   skipDraft07,
+	"draft7/boolean_schema.json/boolean schema 'false'/object is invalid":                                                                   skipDraft07,
+	"draft7/boolean_schema.json/boolean schema 'false'/string is invalid":                                                                   skipDraft07,
+	"draft7/const.json/const validation/another type is invalid":                                                                            skipDraft07,
+	"draft7/const.json/const validation/another value is invalid":                                                                           skipDraft07,
+	"draft7/const.json/const with array/another array item is invalid":                                                                      skipDraft07,
+	"draft7/const.json/const with array/array with additional items is invalid":                                                             skipDraft07,
+	"draft7/const.json/const with null/not null is invalid":                                                                                 skipDraft07,
+	"draft7/const.json/const with object/another object is invalid":                                                                         skipDraft07,
+	"draft7/const.json/const with object/another type is invalid":                                                                           skipDraft07,
+	"draft7/contains.json/contains keyword validation/array without items matching schema is invalid":                                       skipDraft07,
+	"draft7/contains.json/contains keyword validation/empty array is invalid":                                                               skipDraft07,
+	"draft7/contains.json/contains keyword with boolean schema false/any non-empty array is invalid":                                        skipDraft07,
+	"draft7/contains.json/contains keyword with boolean schema false/empty array is invalid":                                                skipDraft07,
+	"draft7/contains.json/contains keyword with boolean schema true/empty array is invalid":                                                 skipDraft07,
+	"draft7/contains.json/contains keyword with const keyword/array without item 5 is invalid":                                              skipDraft07,
+	"draft7/definitions.json/invalid definition/invalid definition schema":                                                                  skipDraft07,
+	"draft7/dependencies.json/dependencies with boolean subschemas/object with both properties is invalid":                                  skipDraft07,
+	"draft7/dependencies.json/dependencies with boolean subschemas/object with property having schema false is invalid":                     skipDraft07,
+	"draft7/dependencies.json/dependencies/missing dependency":                                                                              skipDraft07,
+	"draft7/dependencies.json/multiple dependencies subschema/wrong type":                                                                   skipDraft07,
+	"draft7/dependencies.json/multiple dependencies subschema/wrong type both":                                                              skipDraft07,
+	"draft7/dependencies.json/multiple dependencies subschema/wrong type other":                                                             skipDraft07,
+	"draft7/dependencies.json/multiple dependencies/missing both dependencies":                                                              skipDraft07,
+	"draft7/dependencies.json/multiple dependencies/missing dependency":                                                                     skipDraft07,
+	"draft7/dependencies.json/multiple dependencies/missing other dependency":                                                               skipDraft07,
+	"draft7/enum.json/enums in properties/missing all properties is invalid":                                                                skipDraft07,
+	"draft7/enum.json/enums in properties/missing required property is invalid":                                                             skipDraft07,
+	"draft7/enum.json/heterogeneous enum validation/objects are deep compared":                                                              skipDraft07,
+	"draft7/enum.json/heterogeneous enum validation/something else is invalid":                                                              skipDraft07,
+	"draft7/enum.json/simple enum validation/something else is invalid":                                                                     skipDraft07,
+	"draft7/exclusiveMaximum.json/exclusiveMaximum validation/above the exclusiveMaximum is invalid":                                        skipDraft07,
+	"draft7/exclusiveMaximum.json/exclusiveMaximum validation/boundary point is invalid":                                                    skipDraft07,
+	"draft7/exclusiveMinimum.json/exclusiveMinimum validation/below the exclusiveMinimum is invalid":                                        skipDraft07,
+	"draft7/exclusiveMinimum.json/exclusiveMinimum validation/boundary point is invalid":                                                    skipDraft07,
+	"draft7/if-then-else.json/if and else without then/invalid through else":                                                                skipDraft07,
+	"draft7/if-then-else.json/if and then without else/invalid through then":                                                                skipDraft07,
+	"draft7/if-then-else.json/validate against correct branch, then vs else/invalid through else":                                           skipDraft07,
+	"draft7/if-then-else.json/validate against correct branch, then vs else/invalid through then":                                           skipDraft07,
+	"draft7/items.json/a schema given for items/wrong type of items":                                                                        skipDraft07,
+	"draft7/items.json/an array of schemas for items/wrong types":                                                                           skipDraft07,
+	"draft7/items.json/items with boolean schema (false)/any non-empty array is invalid":                                                    skipDraft07,
+	"draft7/items.json/items with boolean schemas/array with two items is invalid":                                                          skipDraft07,
+	"draft7/maxItems.json/maxItems validation/too long is invalid":                                                                          skipDraft07,
+	"draft7/maxLength.json/maxLength validation/too long is invalid":                                                                        skipDraft07,
+	"draft7/maxProperties.json/maxProperties validation/too long is invalid":                                                                skipDraft07,
+	"draft7/maximum.json/maximum validation/above the maximum is invalid":                                                                   skipDraft07,
+	"draft7/minItems.json/minItems validation/too short is invalid":                                                                         skipDraft07,
+	"draft7/minLength.json/minLength validation/one supplementary Unicode code point is not long enough":                                    skipDraft07,
+	"draft7/minLength.json/minLength validation/too short is invalid":                                                                       skipDraft07,
+	"draft7/minProperties.json/minProperties validation/too short is invalid":                                                               skipDraft07,
+	"draft7/minimum.json/minimum validation/below the minimum is invalid":                                                                   skipDraft07,
+	"draft7/multipleOf.json/by int/int by int fail":                                                                                         skipDraft07,
+	"draft7/multipleOf.json/by number/35 is not multiple of 1.5":                                                                            skipDraft07,
+	"draft7/multipleOf.json/by small number/0.00751 is not multiple of 0.0001":                                                              skipDraft07,
+	"draft7/not.json/forbidden property/property present":                                                                                   skipDraft07,
+	"draft7/not.json/not more complex schema/mismatch":                                                                                      skipDraft07,
+	"draft7/not.json/not multiple types/mismatch":                                                                                           skipDraft07,
+	"draft7/not.json/not multiple types/other mismatch":                                                                                     skipDraft07,
+	"draft7/not.json/not with boolean schema true/any value is invalid":                                                                     skipDraft07,
+	"draft7/not.json/not/disallowed":                                                                                                        skipDraft07,
+	"draft7/oneOf.json/oneOf complex types/both oneOf valid (complex)":                                                                      skipDraft07,
+	"draft7/oneOf.json/oneOf complex types/neither oneOf valid (complex)":                                                                   skipDraft07,
+	"draft7/oneOf.json/oneOf with base schema/both oneOf valid":                                                                             skipDraft07,
+	"draft7/oneOf.json/oneOf with base schema/mismatch base schema":                                                                         skipDraft07,
+	"draft7/oneOf.json/oneOf with boolean schemas, all false/any value is invalid":                                                          skipDraft07,
+	"draft7/oneOf.json/oneOf with boolean schemas, all true/any value is invalid":                                                           skipDraft07,
+	"draft7/oneOf.json/oneOf with boolean schemas, more than one true/any value is invalid":                                                 skipDraft07,
+	"draft7/oneOf.json/oneOf/both oneOf valid":                                                                                              skipDraft07,
+	"draft7/oneOf.json/oneOf/neither oneOf valid":                                                                                           skipDraft07,
+	"draft7/pattern.json/pattern validation/a non-matching pattern is invalid":                                                              skipDraft07,
+	"draft7/patternProperties.json/multiple simultaneous patternProperties are validated/an invalid due to both is invalid":                 skipDraft07,
+	"draft7/patternProperties.json/multiple simultaneous patternProperties are validated/an invalid due to one is invalid":                  skipDraft07,
+	"draft7/patternProperties.json/multiple simultaneous patternProperties are validated/an invalid due to the other is invalid":            skipDraft07,
+	"draft7/patternProperties.json/patternProperties validates properties matching a regex/a single invalid match is invalid":               skipDraft07,
+	"draft7/patternProperties.json/patternProperties validates properties matching a regex/multiple invalid matches is invalid":             skipDraft07,
+	"draft7/patternProperties.json/patternProperties with boolean schemas/object with both properties is invalid":                           skipDraft07,
+	"draft7/patternProperties.json/patternProperties with boolean schemas/object with property matching schema false is invalid":            skipDraft07,
+	"draft7/patternProperties.json/regexes are not anchored by default and are case sensitive/recognized members are accounted for":         skipDraft07,
+	"draft7/patternProperties.json/regexes are not anchored by default and are case sensitive/regexes are case sensitive, 2":                skipDraft07,
+	"draft7/properties.json/object properties validation/both properties invalid is invalid":                                                skipDraft07,
+	"draft7/properties.json/object properties validation/one property invalid is invalid":                                                   skipDraft07,
+	"draft7/properties.json/properties with boolean schema/both properties present is invalid":                                              skipDraft07,
+	"draft7/properties.json/properties with boolean schema/only 'false' property present is invalid":                                        skipDraft07,
+	"draft7/properties.json/properties, patternProperties, additionalProperties interaction/additionalProperty invalidates others":          skipDraft07,
+	"draft7/properties.json/properties, patternProperties, additionalProperties interaction/patternProperty invalidates nonproperty":        skipDraft07,
+	"draft7/properties.json/properties, patternProperties, additionalProperties interaction/patternProperty invalidates property":           skipDraft07,
+	"draft7/properties.json/properties, patternProperties, additionalProperties interaction/property invalidates property":                  skipDraft07,
+	"draft7/propertyNames.json/propertyNames validation/some property names invalid":                                                        skipDraft07,
+	"draft7/propertyNames.json/propertyNames with boolean schema false/object with any properties is invalid":                               skipDraft07,
+	"draft7/ref.json/$ref to boolean schema false/any value is invalid":                                                                     skipDraft07,
+	"draft7/ref.json/Recursive references between schemas/invalid tree":                                                                     skipDraft07,
+	"draft7/ref.json/escaped pointer ref/percent invalid":                                                                                   skipDraft07,
+	"draft7/ref.json/escaped pointer ref/slash invalid":                                                                                     skipDraft07,
+	"draft7/ref.json/escaped pointer ref/tilda invalid":                                                                                     skipDraft07,
+	"draft7/ref.json/nested refs/nested ref invalid":                                                                                        skipDraft07,
+	"draft7/ref.json/property named $ref that is not a reference/property named $ref invalid":                                               skipDraft07,
+	"draft7/ref.json/ref overrides any sibling keywords/ref invalid":                                                                        skipDraft07,
+	"draft7/ref.json/relative pointer ref to array/mismatch array":                                                                          skipDraft07,
+	"draft7/ref.json/relative pointer ref to object/mismatch":                                                                               skipDraft07,
+	"draft7/ref.json/remote ref, containing refs itself/remote ref invalid":                                                                 skipDraft07,
+	"draft7/ref.json/root pointer ref/mismatch":                                                                                             skipDraft07,
+	"draft7/ref.json/root pointer ref/recursive mismatch":                                                                                   skipDraft07,
+	"draft7/refRemote.json/base URI change - change folder in subschema/string is invalid":                                                  skipRemoteRef,
+	"draft7/refRemote.json/base URI change - change folder/string is invalid":                                                               skipRemoteRef,
+	"draft7/refRemote.json/base URI change/base URI change ref invalid":                                                                     skipRemoteRef,
+	"draft7/refRemote.json/fragment within remote ref/remote fragment invalid":                                                              skipRemoteRef,
+	"draft7/refRemote.json/ref within remote ref/ref within ref invalid":                                                                    skipRemoteRef,
+	"draft7/refRemote.json/remote ref/remote ref invalid":                                                                                   skipRemoteRef,
+	"draft7/refRemote.json/root ref in remote ref/object is invalid":                                                                        skipRemoteRef,
+	"draft7/required.json/required validation/non-present required property is invalid":                                                     skipDraft07,
+	"draft7/type.json/array type matches arrays/a boolean is not an array":                                                                  skipDraft07,
+	"draft7/type.json/array type matches arrays/a float is not an array":                                                                    skipDraft07,
+	"draft7/type.json/array type matches arrays/a string is not an array":                                                                   skipDraft07,
+	"draft7/type.json/array type matches arrays/an integer is not an array":                                                                 skipDraft07,
+	"draft7/type.json/array type matches arrays/an object is not an array":                                                                  skipDraft07,
+	"draft7/type.json/array type matches arrays/null is not an array":                                                                       skipDraft07,
+	"draft7/type.json/boolean type matches booleans/a float is not a boolean":                                                               skipDraft07,
+	"draft7/type.json/boolean type matches booleans/a string is not a boolean":                                                              skipDraft07,
+	"draft7/type.json/boolean type matches booleans/an array is not a boolean":                                                              skipDraft07,
+	"draft7/type.json/boolean type matches booleans/an integer is not a boolean":                                                            skipDraft07,
+	"draft7/type.json/boolean type matches booleans/an object is not a boolean":                                                             skipDraft07,
+	"draft7/type.json/boolean type matches booleans/null is not a boolean":                                                                  skipDraft07,
+	"draft7/type.json/integer type matches integers/a boolean is not an integer":                                                            skipDraft07,
+	"draft7/type.json/integer type matches integers/a float is not an integer":                                                              skipDraft07,
+	"draft7/type.json/integer type matches integers/a string is not an integer":                                                             skipDraft07,
+	"draft7/type.json/integer type matches integers/a string is still not an integer, even if it looks like one":                            skipDraft07,
+	"draft7/type.json/integer type matches integers/an array is not an integer":                                                             skipDraft07,
+	"draft7/type.json/integer type matches integers/an object is not an integer":                                                            skipDraft07,
+	"draft7/type.json/integer type matches integers/null is not an integer":                                                                 skipDraft07,
+	"draft7/type.json/multiple types can be specified in an array/a boolean is invalid":                                                     skipDraft07,
+	"draft7/type.json/multiple types can be specified in an array/a float is invalid":                                                       skipDraft07,
+	"draft7/type.json/multiple types can be specified in an array/an array is invalid":                                                      skipDraft07,
+	"draft7/type.json/multiple types can be specified in an array/an object is invalid":                                                     skipDraft07,
+	"draft7/type.json/multiple types can be specified in an array/null is invalid":                                                          skipDraft07,
+	"draft7/type.json/null type matches only the null object/a boolean is not null":                                                         skipDraft07,
+	"draft7/type.json/null type matches only the null object/a float is not null":                                                           skipDraft07,
+	"draft7/type.json/null type matches only the null object/a string is not null":                                                          skipDraft07,
+	"draft7/type.json/null type matches only the null object/an array is not null":                                                          skipDraft07,
+	"draft7/type.json/null type matches only the null object/an integer is not null":                                                        skipDraft07,
+	"draft7/type.json/null type matches only the null object/an object is not null":                                                         skipDraft07,
+	"draft7/type.json/number type matches numbers/a boolean is not a number":                                                                skipDraft07,
+	"draft7/type.json/number type matches numbers/a string is not a number":                                                                 skipDraft07,
+	"draft7/type.json/number type matches numbers/a string is still not a number, even if it looks like one":                                skipDraft07,
+	"draft7/type.json/number type matches numbers/an array is not a number":                                                                 skipDraft07,
+	"draft7/type.json/number type matches numbers/an object is not a number":                                                                skipDraft07,
+	"draft7/type.json/number type matches numbers/null is not a number":                                                                     skipDraft07,
+	"draft7/type.json/object type matches objects/a boolean is not an object":                                                               skipDraft07,
+	"draft7/type.json/object type matches objects/a float is not an object":                                                                 skipDraft07,
+	"draft7/type.json/object type matches objects/a string is not an object":                                                                skipDraft07,
+	"draft7/type.json/object type matches objects/an array is not an object":                                                                skipDraft07,
+	"draft7/type.json/object type matches objects/an integer is not an object":                                                              skipDraft07,
+	"draft7/type.json/object type matches objects/null is not an object":                                                                    skipDraft07,
+	"draft7/type.json/string type matches strings/1 is not a string":                                                                        skipDraft07,
+	"draft7/type.json/string type matches strings/a boolean is not a string":                                                                skipDraft07,
+	"draft7/type.json/string type matches strings/a float is not a string":                                                                  skipDraft07,
+	"draft7/type.json/string type matches strings/an array is not a string":                                                                 skipDraft07,
+	"draft7/type.json/string type matches strings/an object is not a string":                                                                skipDraft07,
+	"draft7/type.json/string type matches strings/null is not a string":                                                                     skipDraft07,
+	"draft7/uniqueItems.json/uniqueItems validation/non-unique array of arrays is invalid":                                                  skipDraft07,
+	"draft7/uniqueItems.json/uniqueItems validation/non-unique array of integers is invalid":                                                skipDraft07,
+	"draft7/uniqueItems.json/uniqueItems validation/non-unique array of nested objects is invalid":                                          skipDraft07,
+	"draft7/uniqueItems.json/uniqueItems validation/non-unique array of objects is invalid":                                                 skipDraft07,
+	"draft7/uniqueItems.json/uniqueItems validation/non-unique heterogeneous types are invalid":                                             skipDraft07,
+	"draft7/uniqueItems.json/uniqueItems validation/numbers are unique if mathematically unequal":                                           skipDraft07,
+}
+
+type suiteCase struct {
+	Description string      `json:"description"`
+	Data        interface{} `json:"data"`
+	Valid       bool        `json:"valid"`
+}
+
+type suiteGroup struct {
+	Description string          `json:"description"`
+	Schema      json.RawMessage `json:"schema"`
+	Tests       []suiteCase     `json:"tests"`
+}
+
+type suiteResult struct {
+	total   int
+	passed  int
+	skipped int
+}
+
+// TestJSONSchemaTestSuite runs the official JSON Schema test suite.
+// Only the required tests are run: the tests under optional/ are not.
+// The pass rate for each draft is logged at the end of the test.
+func TestJSONSchemaTestSuite(t *testing.T) {
+	if _, err := os.Stat(suiteDir); err != nil {
+		t.Skipf("JSON Schema test suite not found in %s", suiteDir)
+		return
+	}
+
+	results := make(map[string]*suiteResult)
+	matched := make(map[string]struct{})
+	for _, draft := range suiteDrafts {
+		dir := filepath.Join(suiteDir, draft.dir)
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil || len(files) == 0 {
+			continue
+		}
+		sort.Strings(files)
+
+		res := &suiteResult{}
+		results[draft.dir] = res
+		t.Run(draft.dir, func(t *testing.T) {
+			for _, file := range files {
+				runSuiteFile(t, draft.dir+"/"+filepath.Base(file), file, draft.schemaID, res, matched)
+			}
+		})
+	}
+
+	for _, draft := range suiteDrafts {
+		res, ok := results[draft.dir]
+		if !ok || res.total == 0 {
+			continue
+		}
+		t.Logf("%s: %d/%d passed (%.1f%%), %d known failures skipped",
+			draft.dir, res.passed, res.total, float64(res.passed)*100/float64(res.total), res.skipped)
+	}
+
+	for name := range suiteSkip {
+		if _, ok := results[name[:strings.IndexByte(name, '/')]]; !ok {
+			continue
+		}
+		if _, ok := matched[name]; !ok {
+			t.Errorf("suiteSkip entry does not match any test case: %s", name)
+		}
+	}
+}
+
+func runSuiteFile(t *testing.T, name, file, schemaID string, res *suiteResult, matched map[string]struct{}) {
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Errorf("failed to read %s: %s", file, err)
+		return
+	}
+
+	var groups []suiteGroup
+	if err := json.Unmarshal(src, &groups); err != nil {
+		t.Errorf("failed to decode %s: %s", file, err)
+		return
+	}
+
+	for _, g := range groups {
+		gname := name + "/" + g.Description
+
+		var check func(interface{}) error
+		buildErr := func() error {
+			s, err := schema.Parse(bytes.NewReader(g.Schema), schema.WithSchemaID(schemaID))
+			if err != nil {
+				return err
+			}
+
+			// References are resolved against the raw schema, so that
+			// JSON pointers can point anywhere in the document
+			var jsctx interface{}
+			if err := json.Unmarshal(g.Schema, &jsctx); err != nil {
+				return err
+			}
+
+			v, err := builder.New().BuildWithCtx(s, jsctx)
+			if err != nil {
+				return err
+			}
+			check = v.Validate
+			return nil
+		}()
+
+		for _, tc := range g.Tests {
+			cname := gname + "/" + tc.Description
+			res.total++
+
+			_, skipped := suiteSkip[cname]
+			if skipped {
+				matched[cname] = struct{}{}
+			}
+
+			passed := buildErr == nil
+			var err error
+			if passed {
+				err = check(tc.Data)
+				passed = (err == nil) == tc.Valid
+			}
+			if passed {
+				res.passed++
+				if skipped {
+					t.Errorf("PASS: %s: listed in suiteSkip, remove it", cname)
+				}
+				continue
+			}
+
+			if skipped {
+				res.skipped++
+				continue
+			}
+
+			switch {
+			case buildErr != nil:
+				t.Errorf("FAIL: %s: failed to build validator: %s", cname, buildErr)
+			case tc.Valid:
+				t.Errorf("FAIL: %s: expected data to be valid: %s", cname, err)
+			default:
+				t.Errorf("FAIL: %s: expected data to be invalid", cname)
+			}
+		}
+	}
+}
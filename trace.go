@@ -0,0 +1,193 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/lestrrat/go-pdebug"
+)
+
+// Trace records every constraint evaluated during a single validation
+// run, the location in the input it was applied to, and its outcome.
+// It is meant for debugging schemas: when a value is rejected, the
+// trace shows which branches of anyOf/oneOf were tried, and why each
+// of them failed.
+//
+// A Trace is filled in by the validation run using the context created
+// by WithTrace (or by JSVal.Explain). It only holds the result of the
+// most recent run, and must not be shared by concurrent runs.
+type Trace struct {
+	// Root is the constraint the run started with
+	Root  *TraceNode
+	stack []*TraceNode
+}
+
+// TraceNode describes the evaluation of a single constraint
+type TraceNode struct {
+	// Constraint is the constraint that was evaluated
+	Constraint Constraint
+	// Pointer is the JSON pointer to the value the constraint was
+	// applied to
+	Pointer string
+	// Err is the error returned by the constraint, or nil if it passed
+	Err error
+	// Children are the constraints evaluated on behalf of this one,
+	// in the order in which they were evaluated
+	Children []*TraceNode
+}
+
+// WithTrace returns a new context that records the next validation run
+// using this context in t
+func WithTrace(ctx context.Context, t *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+func traceFromContext(ctx context.Context) *Trace {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	if t != nil {
+		t.Root = nil
+		t.stack = t.stack[:0]
+	}
+	return t
+}
+
+// Explain validates x, and returns the trace of the validation run
+// along with the result
+func (v *JSVal) Explain(x interface{}) (t *Trace, err error) {
+	if pdebug.Enabled {
+		g := pdebug.Marker("JSVal.Explain").BindError(&err)
+		defer g.End()
+	}
+
+	t = &Trace{}
+	err = v.ValidateContext(WithTrace(context.Background(), t), x)
+	return t, err
+}
+
+func (t *Trace) enter(c Constraint, pointer string) *TraceNode {
+	node := &TraceNode{Constraint: c, Pointer: pointer}
+	if l := len(t.stack); l > 0 {
+		parent := t.stack[l-1]
+		parent.Children = append(parent.Children, node)
+	} else {
+		t.Root = node
+	}
+	t.stack = append(t.stack, node)
+	return node
+}
+
+func (t *Trace) leave(node *TraceNode, err error) {
+	node.Err = err
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+// Passed returns true if the constraint accepted the value
+func (n *TraceNode) Passed() bool {
+	return n.Err == nil
+}
+
+// Name returns a short description of the constraint, such as
+// "string", "oneOf" or "$ref #/definitions/foo"
+func (n *TraceNode) Name() string {
+	switch c := n.Constraint.(type) {
+	case emptyConstraint:
+		return "any"
+	case nullConstraint:
+		return "null"
+	case *BooleanConstraint:
+		return "boolean"
+	case *StringConstraint:
+		return "string"
+	case *NumberConstraint:
+		return "number"
+	case *IntegerConstraint:
+		return "integer"
+	case *ArrayConstraint:
+		return "array"
+	case *ObjectConstraint:
+		return "object"
+	case *EnumConstraint:
+		return "enum"
+	case *AnyConstraint:
+		return "anyOf"
+	case *AllConstraint:
+		return "allOf"
+	case *OneOfConstraint:
+		return "oneOf"
+	case *NotConstraint:
+		return "not"
+	case *DiscriminatorConstraint:
+		return "discriminator " + c.propertyName
+	case *ReferenceConstraint:
+		return "$ref " + c.reference
+	case *DataConstraint:
+		return c.keyword + " $data " + c.pointer
+	}
+	return "custom"
+}
+
+// reason returns the message explaining why the node failed, or the
+// empty string if the failure was caused by one of its children, in
+// which case the child already carries the message
+func (n *TraceNode) reason() string {
+	if n.Err == nil {
+		return ""
+	}
+
+	verr, ok := AsValidationError(n.Err)
+	if !ok {
+		return n.Err.Error()
+	}
+	for _, child := range n.Children {
+		if cerr, ok := AsValidationError(child.Err); ok && cerr == verr {
+			return ""
+		}
+	}
+	return verr.Err.Error()
+}
+
+// String renders the trace as an indented tree, one constraint per line
+func (t *Trace) String() string {
+	var buf bytes.Buffer
+	t.WriteTo(&buf)
+	return buf.String()
+}
+
+// WriteTo renders the trace as an indented tree to w. Each line holds
+// the outcome, the name of the constraint, the location of the value
+// it was applied to, and for failures that did not originate in a
+// child constraint, the reason
+func (t *Trace) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	if t.Root != nil {
+		writeTraceNode(&buf, t.Root, 0)
+	}
+	return buf.WriteTo(w)
+}
+
+func writeTraceNode(buf *bytes.Buffer, n *TraceNode, depth int) {
+	buf.WriteString(strings.Repeat("  ", depth))
+	if n.Passed() {
+		buf.WriteString("PASS ")
+	} else {
+		buf.WriteString("FAIL ")
+	}
+	buf.WriteString(n.Name())
+	buf.WriteString(" at ")
+	if n.Pointer == "" {
+		buf.WriteByte('/')
+	} else {
+		buf.WriteString(n.Pointer)
+	}
+	if reason := n.reason(); reason != "" {
+		buf.WriteString(": ")
+		buf.WriteString(reason)
+	}
+	buf.WriteByte('\n')
+
+	for _, child := range n.Children {
+		writeTraceNode(buf, child, depth+1)
+	}
+}
@@ -0,0 +1,118 @@
+package validator_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-json-schema/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPetValidator() *validator.JSVal {
+	cm := &validator.ConstraintMap{}
+	cm.SetReference("#/definitions/cat", validator.Object().
+		AddProp("kind", validator.Enum("cat")).
+		AddProp("lives", validator.Integer().Maximum(9)).
+		Required("kind"))
+	cm.SetReference("#/definitions/dog", validator.Object().
+		AddProp("kind", validator.Enum("dog")).
+		Required("kind"))
+
+	return validator.New().SetConstraintMap(cm).SetRoot(validator.Object().
+		AddProp("pet", validator.OneOf().
+			Add(validator.Reference(cm).RefersTo("#/definitions/cat")).
+			Add(validator.Reference(cm).RefersTo("#/definitions/dog"))).
+		Required("pet"),
+	)
+}
+
+func TestExplain(t *testing.T) {
+	v := newPetValidator()
+
+	trace, err := v.Explain(map[string]interface{}{
+		"pet": map[string]interface{}{"kind": "cat", "lives": 12},
+	})
+	if !assert.Error(t, err, "validation should fail") {
+		return
+	}
+
+	const expected = `FAIL object at /
+  FAIL oneOf at /pet: none of the constraints passed; closest match is branch 0: numeric value is greater than the maximum (at '/pet/lives')
+    FAIL $ref #/definitions/cat at /pet
+      FAIL object at /pet
+        PASS enum at /pet/kind
+        FAIL integer at /pet/lives: numeric value is greater than the maximum
+    FAIL $ref #/definitions/dog at /pet
+      FAIL object at /pet
+        FAIL enum at /pet/kind: value is not in enumeration
+`
+	if !assert.Equal(t, expected, trace.String(), "rendered trace matches") {
+		return
+	}
+}
+
+func TestWithTrace(t *testing.T) {
+	v := newPetValidator()
+
+	var trace validator.Trace
+	ctx := validator.WithTrace(context.Background(), &trace)
+	if !assert.Error(t, v.ValidateContext(ctx, map[string]interface{}{}), "validation should fail") {
+		return
+	}
+	if !assert.False(t, trace.Root.Passed(), "root failed") {
+		return
+	}
+
+	err := v.ValidateContext(ctx, map[string]interface{}{
+		"pet": map[string]interface{}{"kind": "dog"},
+	})
+	if !assert.NoError(t, err, "validation should pass") {
+		return
+	}
+	if !assert.True(t, trace.Root.Passed(), "trace is reset for each run") {
+		return
+	}
+	oneOf := trace.Root.Children[0]
+	if !assert.Equal(t, "oneOf", oneOf.Name(), "first child is oneOf") {
+		return
+	}
+	if !assert.Len(t, oneOf.Children, 2, "both branches are recorded") {
+		return
+	}
+	if !assert.Equal(t, []bool{false, true}, []bool{oneOf.Children[0].Passed(), oneOf.Children[1].Passed()}, "only the dog branch passes") {
+		return
+	}
+}
+
+func TestWithTraceReader(t *testing.T) {
+	v := validator.New().SetRoot(validator.Array().
+		Items(validator.Integer().Maximum(9)).
+		MaxItems(2))
+
+	var trace validator.Trace
+	ctx := validator.WithTrace(context.Background(), &trace)
+	if !assert.Error(t, v.ValidateReaderContext(ctx, strings.NewReader(`[1, 12]`)), "validation should fail") {
+		return
+	}
+
+	const expected = `FAIL array at /
+  PASS integer at /0
+  FAIL integer at /1: numeric value is greater than the maximum
+`
+	if !assert.Equal(t, expected, trace.String(), "elements are recorded under the array") {
+		return
+	}
+
+	if !assert.Error(t, v.ValidateReaderContext(ctx, strings.NewReader(`[1, 2, 3]`)), "validation should fail") {
+		return
+	}
+
+	const tooMany = `FAIL array at /: more items than maxItems
+  PASS integer at /0
+  PASS integer at /1
+`
+	if !assert.Equal(t, tooMany, trace.String(), "failures of the array itself are recorded") {
+		return
+	}
+}
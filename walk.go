@@ -0,0 +1,136 @@
+package validator
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Visitor is implemented by types that inspect constraint trees using
+// Walk. Visit is called for each constraint encountered, along with
+// the path to the constraint, which is a JSON pointer into the schema
+// that the constraint would have been built from, such as
+// "/properties/name" or "/items/0". If the returned Visitor is not
+// nil, Walk visits each of the children of c with it.
+type Visitor interface {
+	Visit(path string, c Constraint) Visitor
+}
+
+// VisitorFunc adapts an ordinary function to a Visitor. Returning
+// false from the function skips the children of the constraint.
+type VisitorFunc func(path string, c Constraint) bool
+
+// Visit calls f(path, c)
+func (f VisitorFunc) Visit(path string, c Constraint) Visitor {
+	if f(path, c) {
+		return f
+	}
+	return nil
+}
+
+// Walk traverses the constraint tree rooted at c in depth-first order.
+// References are visited as ReferenceConstraints, followed by the
+// constraint that they resolve to, at the same path. The constraint
+// that a reference resolves to is only traversed the first time it
+// is encountered, so that recursive schemas can be walked as well.
+func Walk(c Constraint, v Visitor) error {
+	w := walker{seen: make(map[Constraint]struct{})}
+	return w.walk("", c, v)
+}
+
+type walker struct {
+	seen map[Constraint]struct{}
+}
+
+func (w *walker) walk(path string, c Constraint, v Visitor) error {
+	if c == nil {
+		return nil
+	}
+
+	if v = v.Visit(path, c); v == nil {
+		return nil
+	}
+
+	switch c := c.(type) {
+	case *ReferenceConstraint:
+		resolved, err := c.Resolved()
+		if err != nil {
+			return errors.Wrapf(err, `failed to resolve reference %s at %s`, c.reference, path)
+		}
+		if reflect.TypeOf(resolved).Comparable() {
+			if _, ok := w.seen[resolved]; ok {
+				return nil
+			}
+			w.seen[resolved] = struct{}{}
+		}
+		return w.walk(path, resolved, v)
+	case *ArrayConstraint:
+		if c.items != nil {
+			return w.walk(path+"/items", c.items, v)
+		}
+		for i, item := range c.positionalItems {
+			if err := w.walk(path+"/items/"+strconv.Itoa(i), item, v); err != nil {
+				return err
+			}
+		}
+		if len(c.positionalItems) > 0 {
+			return w.walk(path+"/additionalItems", c.additionalItems, v)
+		}
+	case *ObjectConstraint:
+		for _, name := range c.GetPropNames() {
+			if err := w.walk(path+"/properties/"+pointerEscaper.Replace(name), c.GetProp(name), v); err != nil {
+				return err
+			}
+		}
+
+		patterns := c.GetPatternProperties()
+		keys := make([]string, 0, len(patterns))
+		byKey := make(map[string]Constraint, len(patterns))
+		for rx, pc := range patterns {
+			keys = append(keys, rx.String())
+			byKey[rx.String()] = pc
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := w.walk(path+"/patternProperties/"+pointerEscaper.Replace(key), byKey[key], v); err != nil {
+				return err
+			}
+		}
+
+		if err := w.walk(path+"/additionalProperties", c.additionalProperties, v); err != nil {
+			return err
+		}
+
+		for _, name := range c.GetSchemaDependencyNames() {
+			if err := w.walk(path+"/dependencies/"+pointerEscaper.Replace(name), c.GetSchemaDependency(name), v); err != nil {
+				return err
+			}
+		}
+	case *AnyConstraint:
+		return w.walkList(path+"/anyOf", c.constraints, v)
+	case *AllConstraint:
+		return w.walkList(path+"/allOf", c.constraints, v)
+	case *OneOfConstraint:
+		return w.walkList(path+"/oneOf", c.constraints, v)
+	case *DiscriminatorConstraint:
+		for _, value := range c.GetMappingValues() {
+			if err := w.walk(path+"/discriminator/mapping/"+pointerEscaper.Replace(value), c.GetMapping(value), v); err != nil {
+				return err
+			}
+		}
+	case *NotConstraint:
+		return w.walk(path+"/not", c.child, v)
+	}
+	return nil
+}
+
+func (w *walker) walkList(path string, l []Constraint, v Visitor) error {
+	for i, c := range l {
+		if err := w.walk(path+"/"+strconv.Itoa(i), c, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
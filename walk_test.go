@@ -0,0 +1,129 @@
+package validator_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-json-schema/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWalkValidator() *validator.JSVal {
+	cm := &validator.ConstraintMap{}
+	node := validator.Object().
+		AddProp("value", validator.Integer()).
+		AddProp("children", validator.Array().Items(validator.Reference(cm).RefersTo("#/definitions/node")))
+	cm.SetReference("#/definitions/node", node)
+
+	return validator.New().SetConstraintMap(cm).SetRoot(validator.Object().
+		AddProp("root", validator.Reference(cm).RefersTo("#/definitions/node")).
+		AddProp("a/b", validator.Any().Add(validator.String()).Add(validator.NullConstraint)).
+		AddProp("point", validator.Array().PositionalItems([]validator.Constraint{validator.Number(), validator.Number()})).
+		PatternPropertiesString("^x-", validator.Not(validator.NullConstraint)).
+		AdditionalProperties(validator.EmptyConstraint).
+		SchemaDependency("root", validator.Object().Required("a/b")),
+	)
+}
+
+func describeConstraint(c validator.Constraint) string {
+	switch c {
+	case validator.EmptyConstraint:
+		return "empty"
+	case validator.NullConstraint:
+		return "null"
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", c), "*validator.")
+}
+
+func TestWalk(t *testing.T) {
+	var visited []string
+	err := validator.Walk(newWalkValidator().Root(), validator.VisitorFunc(func(path string, c validator.Constraint) bool {
+		visited = append(visited, path+" "+describeConstraint(c))
+		return true
+	}))
+	if !assert.NoError(t, err, "Walk should succeed") {
+		return
+	}
+
+	expected := []string{
+		" ObjectConstraint",
+		"/properties/a~1b AnyConstraint",
+		"/properties/a~1b/anyOf/0 StringConstraint",
+		"/properties/a~1b/anyOf/1 null",
+		"/properties/point ArrayConstraint",
+		"/properties/point/items/0 NumberConstraint",
+		"/properties/point/items/1 NumberConstraint",
+		"/properties/point/additionalItems empty",
+		"/properties/root ReferenceConstraint",
+		"/properties/root ObjectConstraint",
+		"/properties/root/properties/children ArrayConstraint",
+		"/properties/root/properties/children/items ReferenceConstraint",
+		"/properties/root/properties/value IntegerConstraint",
+		"/patternProperties/^x- NotConstraint",
+		"/patternProperties/^x-/not null",
+		"/additionalProperties empty",
+		"/dependencies/root ObjectConstraint",
+	}
+	if !assert.Equal(t, expected, visited, "visited constraints match") {
+		return
+	}
+}
+
+func TestWalkSkipChildren(t *testing.T) {
+	var visited []string
+	err := validator.Walk(newWalkValidator().Root(), validator.VisitorFunc(func(path string, c validator.Constraint) bool {
+		visited = append(visited, path)
+		_, isArray := c.(*validator.ArrayConstraint)
+		_, isRef := c.(*validator.ReferenceConstraint)
+		return !isArray && !isRef
+	}))
+	if !assert.NoError(t, err, "Walk should succeed") {
+		return
+	}
+
+	expected := []string{
+		"",
+		"/properties/a~1b",
+		"/properties/a~1b/anyOf/0",
+		"/properties/a~1b/anyOf/1",
+		"/properties/point",
+		"/properties/root",
+		"/patternProperties/^x-",
+		"/patternProperties/^x-/not",
+		"/additionalProperties",
+		"/dependencies/root",
+	}
+	if !assert.Equal(t, expected, visited, "visited paths match") {
+		return
+	}
+}
+
+func TestWalkUnresolvedReference(t *testing.T) {
+	c := validator.Object().AddProp("foo", validator.Reference(&validator.ConstraintMap{}).RefersTo("#/definitions/foo"))
+	err := validator.Walk(c, validator.VisitorFunc(func(string, validator.Constraint) bool { return true }))
+	if !assert.Error(t, err, "Walk should fail") {
+		return
+	}
+}
+
+func TestObjectDependencyNames(t *testing.T) {
+	c := validator.Object().
+		PropDependency("b", "a").
+		PropDependency("a", "c").
+		SchemaDependency("z", validator.Object())
+
+	if !assert.Equal(t, []string{"a", "b"}, c.GetPropDependencyNames(), "property dependency names match") {
+		return
+	}
+	if !assert.Equal(t, []string{"z"}, c.GetSchemaDependencyNames(), "schema dependency names match") {
+		return
+	}
+
+	cm := &validator.ConstraintMap{}
+	cm.SetReference("#/b", validator.String())
+	cm.SetReference("#/a", validator.String())
+	if !assert.Equal(t, []string{"#/a", "#/b"}, cm.GetReferenceNames(), "reference names match") {
+		return
+	}
+}